@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"metabee/internal/config"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// runCommand executa os subcomandos de linha de comando e retorna o código de saída
+func runCommand(configPath string, args []string) int {
+	if len(args) >= 2 && args[0] == "config" && args[1] == "check" {
+		return runConfigCheck(configPath, args[2:])
+	}
+	if len(args) >= 1 && args[0] == "migrate" {
+		return runMigrate(configPath, args[1:])
+	}
+	if len(args) >= 1 && args[0] == "user" {
+		return runUser(configPath, args[1:])
+	}
+
+	fmt.Fprintf(os.Stderr, "Comando desconhecido: %v\n\n", args)
+	fmt.Fprintln(os.Stderr, "Uso:")
+	fmt.Fprintln(os.Stderr, "  metabee [--config <arquivo>]                 inicia o servidor")
+	fmt.Fprintln(os.Stderr, "  metabee [--config <arquivo>] config check    valida e exibe o config efetivo")
+	fmt.Fprintln(os.Stderr, "  metabee [--config <arquivo>] migrate         aplica as migrações pendentes do banco")
+	fmt.Fprintln(os.Stderr, "  metabee [--config <arquivo>] migrate status  lista as migrações aplicadas e pendentes")
+	fmt.Fprintln(os.Stderr, "  metabee [--config <arquivo>] user promote <email> <papel>")
+	fmt.Fprintln(os.Stderr, "                                               altera o papel de um usuário (ex.: o primeiro admin)")
+	return 2
+}
+
+// runConfigCheck monta a configuração como na inicialização, imprime o resultado
+// com os segredos mascarados e lista todos os problemas encontrados.
+func runConfigCheck(configPath string, args []string) int {
+	flags := flag.NewFlagSet("config check", flag.ContinueOnError)
+	flags.StringVar(&configPath, "config", configPath, "caminho do metabee.conf")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	conf, fileConf, err := config.Read(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao ler config: %v\n", err)
+		return 1
+	}
+
+	if fileConf == "" {
+		fmt.Println("# Nenhum arquivo de configuração encontrado; padrões + variáveis de ambiente")
+	} else {
+		fmt.Printf("# Config efetivo: %s + variáveis de ambiente\n", fileConf)
+	}
+	if err := toml.NewEncoder(os.Stdout).Encode(config.Redacted(conf)); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao exibir config: %v\n", err)
+		return 1
+	}
+
+	if err := config.Validate(conf); err != nil {
+		fmt.Fprintf(os.Stderr, "\n❌ Config inválido:\n%v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(os.Stderr, "\n✅ Config válido")
+	return 0
+}
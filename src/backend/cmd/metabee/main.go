@@ -1,22 +1,46 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"metabee/internal/config"
-	"metabee/internal/database"
 	"metabee/internal/router"
-	"strconv"
+	"metabee/internal/service"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	config.Load()
-	database.ConnectMongoDB()
+	configPath := flag.String("config", "", "caminho do metabee.conf (padrão: METABEE_CONFIG, ./metabee.conf ou ao lado do executável)")
+	flag.Parse()
 
-	port := strconv.Itoa(config.Env.Service.Port)
+	if args := flag.Args(); len(args) > 0 {
+		os.Exit(runCommand(*configPath, args))
+	}
+
+	config.Load(*configPath)
+	config.WatchConfig()
+
+	if err := service.LoadSigningKeys(); err != nil {
+		log.Fatalf("❌ Erro ao carregar chaves de assinatura JWT: %v", err)
+	}
+
+	// Cancelado no primeiro SIGINT/SIGTERM; um segundo sinal encerra imediatamente
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	r := router.SetupMainRouter()
+	service.StartSigningKeyRotation(ctx)
+
+	storage, err := openStorage(ctx)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	r := router.SetupMainRouter(storage)
 	
 	if gin.Mode() == gin.DebugMode {
 		log.Println("📋 Rotas registradas:")
@@ -25,6 +49,7 @@ func main() {
 		}
 	}
 	
-	log.Printf("🚀 Servidor iniciado na porta %s", port)
-	r.Run(":" + port)
+	if err := serve(ctx, stop, r, storage); err != nil {
+		log.Fatalf("❌ Erro no servidor HTTP: %v", err)
+	}
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"metabee/internal/config"
+	"metabee/internal/database"
+	"metabee/internal/migration"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// runMigrate aplica as migrações pendentes ("migrate") ou lista a situação de cada uma ("migrate status")
+func runMigrate(configPath string, args []string) int {
+	showStatus := len(args) == 1 && args[0] == "status"
+	if len(args) > 0 && !showStatus {
+		fmt.Fprintf(os.Stderr, "Comando desconhecido: migrate %v\n", args)
+		return 2
+	}
+
+	config.Load(configPath)
+	if config.Current().Database.Embedded() {
+		fmt.Fprintln(os.Stderr, "❌ Migrações só se aplicam ao backend mongodb; o embedded cria o que precisa ao abrir o arquivo")
+		return 1
+	}
+	database.ConnectMongoDB()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer database.DisconnectMongoDB(context.Background())
+
+	if showStatus {
+		return printMigrationStatus(ctx)
+	}
+
+	applied, err := migration.Run(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("✅ Nenhuma migração pendente")
+	} else {
+		fmt.Printf("✅ %d migração(ões) aplicada(s)\n", len(applied))
+	}
+	return 0
+}
+
+func printMigrationStatus(ctx context.Context) int {
+	applied, err := migration.Applied(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao ler migrações aplicadas: %v\n", err)
+		return 1
+	}
+
+	known := make(map[int]bool)
+	pending := 0
+	for _, m := range migration.All() {
+		known[m.Version] = true
+		if record, done := applied[m.Version]; done {
+			fmt.Printf("  [x] %03d %s (aplicada em %s)\n", m.Version, m.Description, record.AppliedAt.Local().Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("  [ ] %03d %s\n", m.Version, m.Description)
+			pending++
+		}
+	}
+
+	// Registradas por um binário mais novo que este
+	var unknown []int
+	for version := range applied {
+		if !known[version] {
+			unknown = append(unknown, version)
+		}
+	}
+	sort.Ints(unknown)
+	for _, version := range unknown {
+		fmt.Printf("  [?] %03d %s (desconhecida por esta versão)\n", version, applied[version].Description)
+	}
+
+	fmt.Printf("\n%d pendente(s)\n", pending)
+	return 0
+}
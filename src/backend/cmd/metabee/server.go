@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/repository"
+	"metabee/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// serve atende as requisições até ctx ser cancelado e então encerra em ordem:
+// para de aceitar conexões e espera as requisições em andamento (inclusive
+// chamadas longas ao chat), espera os workers em segundo plano e fecha o
+// armazenamento (MongoDB ou arquivo local).
+// Tudo isso respeita [service].shutdownTimeoutSeconds.
+func serve(ctx context.Context, stopSignals context.CancelFunc, handler http.Handler, storage repository.Storage) error {
+	serviceConf := config.Current().Service
+
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(serviceConf.Port),
+		Handler:           handler,
+		ReadTimeout:       time.Duration(serviceConf.ReadTimeoutSeconds) * time.Second,
+		ReadHeaderTimeout: time.Duration(serviceConf.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(serviceConf.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(serviceConf.IdleTimeoutSeconds) * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		var err error
+		if serviceConf.TLSCertFile != "" {
+			log.Printf("🚀 Servidor iniciado na porta %d (HTTPS)", serviceConf.Port)
+			err = server.ListenAndServeTLS(serviceConf.TLSCertFile, serviceConf.TLSKeyFile)
+		} else {
+			log.Printf("🚀 Servidor iniciado na porta %d", serviceConf.Port)
+			err = server.ListenAndServe()
+		}
+		serverErr <- err
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// A partir daqui um novo sinal usa o comportamento padrão e mata o processo
+	stopSignals()
+
+	shutdownTimeout := time.Duration(serviceConf.ShutdownTimeoutSeconds) * time.Second
+	log.Printf("🛑 Encerrando: aguardando requisições em andamento (até %s)", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️  Requisições ainda em andamento foram interrompidas: %v", err)
+	}
+	if err := service.WaitBackgroundWorkers(shutdownCtx); err != nil {
+		log.Printf("⚠️  Workers em segundo plano não terminaram a tempo: %v", err)
+	}
+	if err := storage.Close(shutdownCtx); err != nil {
+		log.Printf("⚠️  Erro ao encerrar o armazenamento (%s): %v", storage.Name(), err)
+	}
+
+	log.Println("👋 Servidor encerrado")
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/database"
+	"metabee/internal/migration"
+	"metabee/internal/repository"
+	"metabee/internal/repository/embedded"
+)
+
+// openStorage abre o backend escolhido em [database].backend. No MongoDB também
+// aplica (ou apenas verifica) as migrações; o embedded não tem migrações.
+func openStorage(ctx context.Context) (repository.Storage, error) {
+	databaseConf := config.Current().Database
+
+	if databaseConf.Embedded() {
+		storage, err := embedded.Open(databaseConf.EmbeddedPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("✅ Usando o banco local %s", databaseConf.EmbeddedPath)
+		return storage, nil
+	}
+
+	database.ConnectMongoDB()
+
+	if databaseConf.MigrateOnStartup {
+		if _, err := migration.Run(ctx); err != nil {
+			return nil, fmt.Errorf("erro ao aplicar migrações: %w", err)
+		}
+	} else if pending, err := migration.Pending(ctx); err != nil {
+		log.Printf("⚠️  Erro ao verificar migrações: %v", err)
+	} else if len(pending) > 0 {
+		log.Printf("⚠️  %d migração(ões) pendente(s); execute \"metabee migrate\"", len(pending))
+	}
+
+	return repository.NewMongoStorage(), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"metabee/internal/config"
+	"metabee/internal/model/dao"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// runUser altera o papel de um usuário direto no banco ("user promote <email> <papel>").
+// É o caminho para criar o primeiro administrador, já que a rota de papéis exige um.
+func runUser(configPath string, args []string) int {
+	if len(args) != 3 || args[0] != "promote" {
+		fmt.Fprintf(os.Stderr, "Comando desconhecido: user %v\n", args)
+		fmt.Fprintln(os.Stderr, "Uso: metabee user promote <email> <admin|instructor|student>")
+		return 2
+	}
+	email, role := strings.ToLower(strings.TrimSpace(args[1])), args[2]
+	if !dao.IsValidRole(role) {
+		fmt.Fprintf(os.Stderr, "❌ Papel inválido: %s. Use admin, instructor ou student\n", role)
+		return 2
+	}
+
+	config.Load(configPath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	storage, err := openStorage(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	defer storage.Close(context.Background())
+	users := storage.Repositories().Users
+
+	user, err := users.FindUserByEmail(ctx, email)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if user.EffectiveRole() == role {
+		fmt.Printf("✅ %s já é %s\n", user.Email, role)
+		return 0
+	}
+	if err := users.UpdateUserProfile(ctx, user.ID, bson.M{"role": role}); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erro ao alterar o papel: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("✅ %s agora é %s (antes: %s)\n", user.Email, role, user.EffectiveRole())
+	return 0
+}
@@ -0,0 +1,43 @@
+// Package buildinfo expõe a versão do binário. Version e Commit são definidos no build:
+//
+//	go build -ldflags "-X metabee/internal/buildinfo.Version=1.4.0 -X metabee/internal/buildinfo.Commit=$(git rev-parse --short HEAD)" ./cmd/metabee
+//
+// Sem -ldflags, Commit vem das informações de VCS gravadas pelo go build.
+package buildinfo
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// StartedAt é o horário em que o processo iniciou, usado para calcular o uptime
+var StartedAt = time.Now()
+
+func init() {
+	if Commit != "" {
+		return
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			Commit = setting.Value
+			if len(Commit) > 12 {
+				Commit = Commit[:12]
+			}
+		}
+	}
+}
+
+// Uptime retorna há quanto tempo o processo está em execução
+func Uptime() time.Duration {
+	return time.Since(StartedAt)
+}
@@ -1,97 +1,254 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 
 	"github.com/BurntSushi/toml"
 )
 
+// Campos com a tag redact são mascarados em "metabee config check"; redact:"url"
+// mascara apenas a senha embutida na URL. Campos com reload:"true" são aplicados
+// por Reload sem reiniciar o servidor; os demais exigem reinício.
+
 type service struct {
 	Port          int    `toml:"port"`
-	GeminiApiKey  string `toml:"geminiApiKey"`
-	N8nWebhookURL string `toml:"n8nWebhookURL"`
+	Mode          string `toml:"mode"` // Modo do Gin: debug (padrão), release ou test
+	GeminiApiKey  string `toml:"geminiApiKey" redact:"true"`
+	N8nWebhookURL string `toml:"n8nWebhookURL" reload:"true"`
+	// Chamadas ao webhook de IA; deve ser menor que writeTimeoutSeconds (padrão: 90)
+	ChatTimeoutSeconds     int    `toml:"chatTimeoutSeconds" reload:"true"`
+	ReadTimeoutSeconds     int    `toml:"readTimeoutSeconds"`     // Leitura da requisição, incluindo uploads (padrão: 30)
+	WriteTimeoutSeconds    int    `toml:"writeTimeoutSeconds"`    // Tempo total para responder (padrão: 120)
+	IdleTimeoutSeconds     int    `toml:"idleTimeoutSeconds"`     // Conexões keep-alive ociosas (padrão: 60)
+	ShutdownTimeoutSeconds int    `toml:"shutdownTimeoutSeconds"` // Espera pelas requisições em andamento ao encerrar (padrão: 120)
+	TLSCertFile            string `toml:"tlsCertFile"`            // Com tlsKeyFile, serve HTTPS; vazio: HTTP
+	TLSKeyFile             string `toml:"tlsKeyFile"`
 }
 
 type database struct {
-	MongoURI string `toml:"mongoURI"`
-	DBName   string `toml:"dbName"`
+	Backend               string `toml:"backend"`      // mongodb (padrão) ou embedded (arquivo local, sem rede)
+	EmbeddedPath          string `toml:"embeddedPath"` // Arquivo do backend embedded (padrão: metabee.db)
+	MongoURI              string `toml:"mongoURI" redact:"url"`
+	DBName                string `toml:"dbName"`
+	ConnectTimeoutSeconds int    `toml:"connectTimeoutSeconds"`             // Conexão e ping inicial (padrão: 10)
+	QueryTimeoutSeconds   int    `toml:"queryTimeoutSeconds" reload:"true"` // Limite de cada operação no banco (padrão: 5)
+	MigrateOnStartup      bool   `toml:"migrateOnStartup"`                  // false: só avisa das migrações pendentes ("metabee migrate")
+}
+
+// Embedded indica se os dados ficam no arquivo local em vez do MongoDB
+func (d database) Embedded() bool {
+	return strings.EqualFold(d.Backend, "embedded")
+}
+
+type cors struct {
+	AllowedOrigins []string `toml:"allowedOrigins" reload:"true"`
+	AllowLoopback  bool     `toml:"allowLoopback" reload:"true"` // Aceita localhost/127.* em qualquer porta, file:// e requisições sem Origin (Electron)
+	MaxAgeHours    int      `toml:"maxAgeHours" reload:"true"`   // Cache do preflight (padrão: 12)
+}
+
+type uploads struct {
+	MaxAvatarMB int `toml:"maxAvatarMB" reload:"true"` // Padrão: 5
+	MaxImageMB  int `toml:"maxImageMB" reload:"true"`  // Imagens de cursos e notícias (padrão: 10)
 }
 
 type jwt struct {
-	JWTSECRET string `toml:"jwtsecret"`
+	JWTSECRET             string `toml:"jwtsecret" redact:"true"`
+	AccessTokenTTLMinutes int    `toml:"accessTokenTTLMinutes"` // Validade do access token (padrão: 15 minutos)
+	RefreshTokenTTLDays   int    `toml:"refreshTokenTTLDays"`   // Validade do refresh token (padrão: 30 dias)
+	// HS256 (padrão, usa jwtsecret), RS256 ou EdDSA. Os dois últimos usam as chaves de keysDir
+	Algorithm                string `toml:"algorithm"`
+	KeysDir                  string `toml:"keysDir"`                  // Diretório das chaves privadas PEM (<kid>.pem)
+	KeyRotationDays          int    `toml:"keyRotationDays"`          // Idade máxima da chave de assinatura (padrão: 30 dias)
+	RetiredKeyRetentionHours int    `toml:"retiredKeyRetentionHours"` // Tempo em que a chave substituída ainda verifica tokens (padrão: 24 horas)
+	AcceptLegacyHS256        bool   `toml:"acceptLegacyHS256"`        // Aceita tokens HS256 durante a migração para chaves assimétricas
+}
+
+type mail struct {
+	SMTPHost     string `toml:"smtpHost"` // Vazio: emails são apenas registrados no log
+	SMTPPort     int    `toml:"smtpPort"`
+	SMTPUsername string `toml:"smtpUsername"`
+	SMTPPassword string `toml:"smtpPassword" redact:"true"`
+	From         string `toml:"from"`
+	// URL base usada nos links enviados por email; o token é anexado como ?token=...
+	PasswordResetURL          string `toml:"passwordResetURL"`
+	PasswordResetTTLMinutes   int    `toml:"passwordResetTTLMinutes"` // Validade do link de redefinição (padrão: 30 minutos)
+	EmailVerificationURL      string `toml:"emailVerificationURL"`
+	EmailVerificationTTLHours int    `toml:"emailVerificationTTLHours"` // Validade do link de verificação (padrão: 48 horas)
+}
+
+type auth struct {
+	RequireVerifiedEmailForPurchase bool   `toml:"requireVerifiedEmailForPurchase" reload:"true"` // Bloqueia compras até o email ser confirmado
+	MaxFailedLoginsPerAccount       int    `toml:"maxFailedLoginsPerAccount" reload:"true"`       // Falhas até bloquear a conta (padrão: 5)
+	MaxFailedLoginsPerIP            int    `toml:"maxFailedLoginsPerIP" reload:"true"`            // Falhas até bloquear o IP (padrão: 20)
+	FailedLoginWindowMinutes        int    `toml:"failedLoginWindowMinutes" reload:"true"`        // Janela de contagem das falhas (padrão: 15)
+	LockoutMinutes                  int    `toml:"lockoutMinutes" reload:"true"`                  // Duração do bloqueio (padrão: 15)
+	RequireMFAForStaff              bool   `toml:"requireMFAForStaff" reload:"true"`              // Admins e instrutores só gerenciam conteúdo com 2FA ativo
+	MFAIssuer                       string `toml:"mfaIssuer" reload:"true"`                       // Nome exibido no aplicativo autenticador (padrão: Metabee)
+}
+
+type password struct {
+	HashAlgorithm    string `toml:"hashAlgorithm"`           // bcrypt (padrão) ou argon2id
+	BcryptCost       int    `toml:"bcryptCost"`              // Padrão: 12
+	Argon2MemoryKiB  int    `toml:"argon2MemoryKiB"`         // Padrão: 65536 (64 MiB)
+	Argon2Iterations int    `toml:"argon2Iterations"`        // Padrão: 3
+	Argon2Threads    int    `toml:"argon2Threads"`           // Padrão: 2
+	MinLength        int    `toml:"minLength" reload:"true"` // Padrão: 8
+	MaxLength        int    `toml:"maxLength" reload:"true"` // Padrão: 128 (72 com bcrypt)
+	RequireUppercase bool   `toml:"requireUppercase" reload:"true"`
+	RequireLowercase bool   `toml:"requireLowercase" reload:"true"`
+	RequireDigit     bool   `toml:"requireDigit" reload:"true"`
+	RequireSymbol    bool   `toml:"requireSymbol" reload:"true"`
+}
+
+type oidc struct {
+	GoogleClientID     string `toml:"googleClientID"`                   // Vazio: login com Google desativado
+	GoogleClientSecret string `toml:"googleClientSecret" redact:"true"` // Clientes do tipo "desktop" do Google também recebem um secret
+	// Padrão: https://accounts.google.com. Em testes aponte para um provedor OIDC falso local
+	GoogleIssuer string   `toml:"googleIssuer"`
+	RedirectURIs []string `toml:"redirectURIs" reload:"true"` // URIs de loopback aceitam qualquer porta
+	AllowSignup  bool     `toml:"allowSignup" reload:"true"`  // Cria a conta no primeiro login com Google
+}
+
+type centralSync struct {
+	// Servidor central usado por "POST /metabee/sync/run" no app desktop. Vazio: sincronização desativada nesta instância
+	CentralURL     string `toml:"centralURL" reload:"true"`
+	TimeoutSeconds int    `toml:"timeoutSeconds" reload:"true"` // Cada chamada ao servidor central (padrão: 30)
+	BatchSize      int    `toml:"batchSize" reload:"true"`      // Registros por envio e por página de alterações (padrão: 200, máximo 500)
 }
 
 type ConfigEnv struct {
-	Service  service  `toml:"service"`
-	Database database `toml:"database"`
-	Jwt      jwt      `toml:"jwt"`
+	Service  service     `toml:"service"`
+	Database database    `toml:"database"`
+	CORS     cors        `toml:"cors"`
+	Uploads  uploads     `toml:"uploads"`
+	Jwt      jwt         `toml:"jwt"`
+	Mail     mail        `toml:"mail"`
+	Auth     auth        `toml:"auth"`
+	Password password    `toml:"password"`
+	OIDC     oidc        `toml:"oidc"`
+	Sync     centralSync `toml:"sync"`
 }
 
-var Env ConfigEnv
-var loaded bool
+// current guarda a configuração em uso. É trocada por inteiro em Reload, então
+// quem lê Current() recebe sempre uma versão consistente e não deve alterá-la.
+var current atomic.Pointer[ConfigEnv]
 
-func Load() {
-	loaded = false
-	executablePath, err := os.Executable()
-	if err != nil {
-		log.Fatal(err)
-	}
-	executableDir := filepath.Dir(executablePath)
+// configFile é o arquivo lido por Load, relido por Reload
+var configFile string
 
-	fileConf := filepath.Join(executableDir, "metabee.conf")
-	
-	// Verificar se o arquivo existe
-	if _, err := os.Stat(fileConf); os.IsNotExist(err) {
-		log.Fatalf("Arquivo de configuração não encontrado: %s", fileConf)
-	}
+func init() {
+	defaults := Defaults()
+	current.Store(&defaults)
+}
+
+// Current retorna a configuração em uso
+func Current() *ConfigEnv {
+	return current.Load()
+}
+
+// Nome do arquivo procurado quando nenhum caminho é informado
+const defaultConfigFile = "metabee.conf"
 
-	metadata, err := toml.DecodeFile(fileConf, &Env)
+// Load monta e valida a configuração e encerra o processo em caso de erro. As variáveis
+// METABEE_* (ou METABEE_*_FILE, para segredos em arquivo) têm precedência sobre
+// o arquivo. O arquivo é escolhido pela flag --config, depois por METABEE_CONFIG,
+// depois ./metabee.conf e por fim metabee.conf ao lado do executável; sem
+// arquivo, apenas o ambiente é usado.
+func Load(explicitPath string) {
+	conf, fileConf, err := Read(explicitPath)
 	if err != nil {
 		log.Fatalf("Erro ao ler config: %v", err)
 	}
+	if err := Validate(conf); err != nil {
+		log.Fatalf("Config inválido:\n%v", err)
+	}
+	current.Store(&conf)
+	configFile = fileConf
 
-	// Log de campos não mapeados (para debug)
-	if len(metadata.Undecoded()) > 0 {
-		log.Printf("Aviso: Campos não mapeados no config: %v", metadata.Undecoded())
+	if fileConf == "" {
+		log.Printf("Nenhum %s encontrado, usando apenas variáveis de ambiente", defaultConfigFile)
+	} else {
+		log.Printf("Config lido de %s", fileConf)
 	}
 
-	log.Printf("Config carregado: Port=%d, MongoDB=%s, N8nWebhook=%s", 
-		Env.Service.Port, 
+	log.Printf("Config carregado: Port=%d, Backend=%s, MongoDB=%s, N8nWebhook=%s",
+		conf.Service.Port,
+		conf.Database.Backend,
 		func() string {
-			if Env.Database.MongoURI != "" {
+			if conf.Database.MongoURI != "" {
 				return "configurado"
 			}
 			return "não configurado"
 		}(),
 		func() string {
-			if Env.Service.N8nWebhookURL != "" {
-				return Env.Service.N8nWebhookURL
+			if conf.Service.N8nWebhookURL != "" {
+				return conf.Service.N8nWebhookURL
 			}
 			return "não configurado"
 		}())
-	loaded = true
 }
 
-// GetN8nWebhookURL retorna a URL do webhook n8n de forma segura
-func GetN8nWebhookURL() string {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("Panic ao acessar config.GetN8nWebhookURL: %v", r)
+// Read monta a configuração (padrões, arquivo e ambiente) sem validar nem
+// alterar a configuração em uso. Retorna também o arquivo usado (vazio quando nenhum foi encontrado).
+func Read(explicitPath string) (ConfigEnv, string, error) {
+	conf := Defaults()
+
+	fileConf, err := resolveConfigPath(explicitPath)
+	if err != nil {
+		return conf, "", err
+	}
+
+	if fileConf != "" {
+		metadata, err := toml.DecodeFile(fileConf, &conf)
+		if err != nil {
+			return conf, fileConf, fmt.Errorf("%s: %w", fileConf, err)
+		}
+
+		// Log de campos não mapeados (para debug)
+		if len(metadata.Undecoded()) > 0 {
+			log.Printf("Aviso: Campos não mapeados no config: %v", metadata.Undecoded())
 		}
-	}()
-	
-	if !loaded {
-		log.Println("Config não foi carregado ainda, usando URL padrão")
-		return "https://n8n.zenitoficial.com.br/webhook-test/gemini"
 	}
-	
-	url := Env.Service.N8nWebhookURL
-	if url != "" {
-		return url
+
+	if err := applyEnvOverrides(&conf, os.LookupEnv); err != nil {
+		return conf, fileConf, err
 	}
-	
-	log.Println("N8nWebhookURL vazio no config, usando URL padrão")
-	return "https://n8n.zenitoficial.com.br/webhook-test/gemini"
+
+	return conf, fileConf, nil
+}
+
+// resolveConfigPath escolhe o arquivo de configuração. Um caminho explícito
+// (flag ou METABEE_CONFIG) precisa existir; os locais padrão são opcionais.
+func resolveConfigPath(explicitPath string) (string, error) {
+	if explicitPath == "" {
+		explicitPath = os.Getenv("METABEE_CONFIG")
+	}
+	if explicitPath != "" {
+		if _, err := os.Stat(explicitPath); err != nil {
+			return "", fmt.Errorf("arquivo de configuração não encontrado: %s", explicitPath)
+		}
+		return explicitPath, nil
+	}
+
+	candidates := []string{defaultConfigFile}
+	if executablePath, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(executablePath), defaultConfigFile))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// GetN8nWebhookURL retorna a URL do webhook n8n configurada em [service]
+func GetN8nWebhookURL() string {
+	return Current().Service.N8nWebhookURL
 }
@@ -0,0 +1,73 @@
+package config
+
+const defaultN8nWebhookURL = "https://n8n.zenitoficial.com.br/webhook-test/gemini"
+
+// Defaults retorna a configuração usada para qualquer campo ausente no
+// metabee.conf e no ambiente. Campos sem padrão (URIs, segredos) ficam vazios.
+func Defaults() ConfigEnv {
+	return ConfigEnv{
+		Service: service{
+			Port:                   8080,
+			Mode:                   "debug",
+			N8nWebhookURL:          defaultN8nWebhookURL,
+			ChatTimeoutSeconds:     90,
+			ReadTimeoutSeconds:     30,
+			WriteTimeoutSeconds:    120,
+			IdleTimeoutSeconds:     60,
+			ShutdownTimeoutSeconds: 120,
+		},
+		Database: database{
+			Backend:               "mongodb",
+			EmbeddedPath:          "metabee.db",
+			DBName:                "metabee_db",
+			ConnectTimeoutSeconds: 10,
+			QueryTimeoutSeconds:   5,
+			MigrateOnStartup:      true,
+		},
+		CORS: cors{
+			AllowedOrigins: []string{"http://10.154.48.38:5173", "http://localhost:5173"},
+			AllowLoopback:  true,
+			MaxAgeHours:    12,
+		},
+		Uploads: uploads{
+			MaxAvatarMB: 5,
+			MaxImageMB:  10,
+		},
+		Jwt: jwt{
+			AccessTokenTTLMinutes:    15,
+			RefreshTokenTTLDays:      30,
+			Algorithm:                "HS256",
+			KeysDir:                  "./keys",
+			KeyRotationDays:          30,
+			RetiredKeyRetentionHours: 24,
+		},
+		Mail: mail{
+			SMTPPort:                  587,
+			PasswordResetTTLMinutes:   30,
+			EmailVerificationTTLHours: 48,
+		},
+		Auth: auth{
+			MaxFailedLoginsPerAccount: 5,
+			MaxFailedLoginsPerIP:      20,
+			FailedLoginWindowMinutes:  15,
+			LockoutMinutes:            15,
+			MFAIssuer:                 "Metabee",
+		},
+		Password: password{
+			HashAlgorithm:    "bcrypt",
+			BcryptCost:       12,
+			Argon2MemoryKiB:  64 * 1024,
+			Argon2Iterations: 3,
+			Argon2Threads:    2,
+			MinLength:        8,
+			MaxLength:        128,
+		},
+		OIDC: oidc{
+			GoogleIssuer: "https://accounts.google.com",
+		},
+		Sync: centralSync{
+			TimeoutSeconds: 30,
+			BatchSize:      200,
+		},
+	}
+}
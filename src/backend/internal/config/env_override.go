@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Prefixo das variáveis de ambiente. O nome é formado pela seção e pelo campo
+// do metabee.conf em maiúsculas: [database].mongoURI -> METABEE_DATABASE_MONGOURI.
+// Com o sufixo _FILE o valor é lido do arquivo indicado (ex.: Docker secrets).
+const envPrefix = "METABEE_"
+
+func envVarName(section, field string) string {
+	return envPrefix + strings.ToUpper(section) + "_" + strings.ToUpper(field)
+}
+
+// walkConfigFields percorre os campos de cada seção; section é a tag toml da seção
+func walkConfigFields(conf reflect.Value, visit func(section string, field reflect.StructField, value reflect.Value)) {
+	confType := conf.Type()
+	for i := 0; i < confType.NumField(); i++ {
+		section := confType.Field(i).Tag.Get("toml")
+		sectionValue := conf.Field(i)
+		sectionType := sectionValue.Type()
+
+		for j := 0; j < sectionType.NumField(); j++ {
+			visit(section, sectionType.Field(j), sectionValue.Field(j))
+		}
+	}
+}
+
+// applyEnvOverrides sobrescreve os campos que tiverem variável de ambiente definida.
+// lookup é os.LookupEnv em produção.
+func applyEnvOverrides(conf *ConfigEnv, lookup func(string) (string, bool)) error {
+	var errs []string
+
+	walkConfigFields(reflect.ValueOf(conf).Elem(), func(section string, field reflect.StructField, value reflect.Value) {
+		name := envVarName(section, field.Tag.Get("toml"))
+
+		raw, fromEnv := lookup(name)
+		secretFile, fromFile := lookup(name + "_FILE")
+		if fromEnv && fromFile {
+			errs = append(errs, fmt.Sprintf("%s e %s_FILE definidas ao mesmo tempo", name, name))
+			return
+		}
+		if fromFile {
+			content, err := os.ReadFile(secretFile)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s_FILE: %v", name, err))
+				return
+			}
+			raw = strings.TrimRight(string(content), "\r\n")
+		} else if !fromEnv {
+			return
+		}
+
+		if err := setConfigValue(value, raw); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("variáveis de ambiente inválidas: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func setConfigValue(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("número inteiro esperado, recebido %q", raw)
+		}
+		value.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("booleano esperado (true/false), recebido %q", raw)
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		// Listas separadas por vírgula; vazio limpa a lista do arquivo
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("tipo não suportado: %s", value.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Intervalo de verificação de alterações no arquivo de configuração
+const configWatchInterval = 2 * time.Second
+
+var (
+	reloadMu    sync.Mutex
+	reloadHooks []func(conf *ConfigEnv)
+)
+
+// OnReload registra uma função chamada após cada recarga com a nova configuração.
+// Usado por quem monta estruturas a partir do config na inicialização (ex.: CORS).
+func OnReload(hook func(conf *ConfigEnv)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reload relê o arquivo e o ambiente e troca atomicamente os campos marcados com
+// reload:"true". Requisições em andamento continuam com a versão que já leram.
+// Alterações em campos que exigem reinício são apenas registradas no log.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	next, _, err := Read(configFile)
+	if err != nil {
+		return err
+	}
+	if err := Validate(next); err != nil {
+		return err
+	}
+
+	merged := *Current()
+	applied, ignored := mergeReloadable(&merged, &next)
+	if len(ignored) > 0 {
+		log.Printf("⚠️  Config: alterações que exigem reinício foram ignoradas: %s", strings.Join(ignored, ", "))
+	}
+	if len(applied) == 0 {
+		log.Println("🔄 Config relido sem alterações aplicáveis")
+		return nil
+	}
+	// A combinação também precisa ser válida: as regras entre campos podem
+	// envolver um campo recarregado e outro que só muda com reinício
+	if err := Validate(merged); err != nil {
+		return err
+	}
+
+	current.Store(&merged)
+	log.Printf("🔄 Config recarregado: %s", strings.Join(applied, ", "))
+
+	for _, hook := range reloadHooks {
+		hook(&merged)
+	}
+	return nil
+}
+
+// mergeReloadable copia para dst os campos recarregáveis que mudaram em next e
+// retorna as chaves aplicadas e as ignoradas (que exigem reinício).
+func mergeReloadable(dst, next *ConfigEnv) (applied, ignored []string) {
+	dstValue := reflect.ValueOf(dst).Elem()
+	nextValue := reflect.ValueOf(next).Elem()
+	confType := dstValue.Type()
+
+	for i := 0; i < confType.NumField(); i++ {
+		section := confType.Field(i).Tag.Get("toml")
+		dstSection := dstValue.Field(i)
+		nextSection := nextValue.Field(i)
+		sectionType := dstSection.Type()
+
+		for j := 0; j < sectionType.NumField(); j++ {
+			field := sectionType.Field(j)
+			if reflect.DeepEqual(dstSection.Field(j).Interface(), nextSection.Field(j).Interface()) {
+				continue
+			}
+
+			key := section + "." + field.Tag.Get("toml")
+			if field.Tag.Get("reload") != "true" {
+				ignored = append(ignored, key)
+				continue
+			}
+			dstSection.Field(j).Set(nextSection.Field(j))
+			applied = append(applied, key)
+		}
+	}
+	return applied, ignored
+}
+
+// WatchConfig recarrega a configuração quando o arquivo muda ou o processo
+// recebe SIGHUP. Sem arquivo de configuração, apenas o sinal é observado.
+func WatchConfig() {
+	go watchReloadSignal()
+
+	if configFile == "" {
+		return
+	}
+
+	go func() {
+		lastModTime, lastSize := configFileStamp()
+		ticker := time.NewTicker(configWatchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			modTime, size := configFileStamp()
+			if modTime.Equal(lastModTime) && size == lastSize {
+				continue
+			}
+			lastModTime, lastSize = modTime, size
+
+			log.Printf("📝 %s alterado, recarregando config", configFile)
+			if err := Reload(); err != nil {
+				log.Printf("❌ Config não recarregado, mantendo a versão atual:\n%v", err)
+			}
+		}
+	}()
+}
+
+func configFileStamp() (time.Time, int64) {
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
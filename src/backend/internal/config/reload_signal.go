@@ -0,0 +1,22 @@
+//go:build !windows
+
+package config
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func watchReloadSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		log.Println("📝 SIGHUP recebido, recarregando config")
+		if err := Reload(); err != nil {
+			log.Printf("❌ Config não recarregado, mantendo a versão atual:\n%v", err)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package config
+
+// No Windows não há SIGHUP; a recarga acontece apenas pela alteração do arquivo.
+func watchReloadSignal() {}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const reloadTestBase = `
+[database]
+mongoURI = "mongodb://localhost:27017"
+
+[jwt]
+jwtsecret = "segredo-de-teste-com-mais-de-32-caracteres"
+`
+
+// useConfigFile grava content em um arquivo temporário e o carrega como a configuração em uso
+func useConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), defaultConfigFile)
+	writeConfigFile(t, path, content)
+
+	conf, _, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := Current()
+	current.Store(&conf)
+	configFile = path
+	t.Cleanup(func() {
+		current.Store(previous)
+		configFile = ""
+	})
+	return path
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(reloadTestBase+content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadAppliesReloadableFields(t *testing.T) {
+	path := useConfigFile(t, "[service]\nchatTimeoutSeconds = 30\nwriteTimeoutSeconds = 60\n")
+
+	writeConfigFile(t, path, "[service]\nchatTimeoutSeconds = 45\nwriteTimeoutSeconds = 120\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if service := Current().Service; service.ChatTimeoutSeconds != 45 || service.WriteTimeoutSeconds != 60 {
+		t.Fatalf("Reload deve aplicar só os campos recarregáveis: chat=%d write=%d", service.ChatTimeoutSeconds, service.WriteTimeoutSeconds)
+	}
+}
+
+// O arquivo novo é válido, mas o chatTimeoutSeconds recarregado não é menor que
+// o writeTimeoutSeconds em uso, que só muda com reinício
+func TestReloadRejectsInvalidMergedConfig(t *testing.T) {
+	path := useConfigFile(t, "[service]\nchatTimeoutSeconds = 30\nwriteTimeoutSeconds = 60\n")
+
+	writeConfigFile(t, path, "[service]\nchatTimeoutSeconds = 90\nwriteTimeoutSeconds = 120\n")
+	if err := Reload(); err == nil {
+		t.Fatal("Reload deve recusar a combinação inválida")
+	}
+	if chat := Current().Service.ChatTimeoutSeconds; chat != 30 {
+		t.Fatalf("a configuração em uso não deve mudar, chatTimeoutSeconds=%d", chat)
+	}
+}
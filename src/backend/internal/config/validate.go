@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Tamanho mínimo do jwtsecret quando tokens HS256 são emitidos ou aceitos
+const minJWTSecretLength = 32
+
+// MaxSyncBatchSize limita sync.batchSize e os registros aceitos em cada envio de sincronização
+const MaxSyncBatchSize = 500
+
+// ValidationError lista todos os problemas encontrados, não apenas o primeiro
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type configValidator struct {
+	problems []string
+}
+
+func (v *configValidator) addf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *configValidator) positive(key string, value int) {
+	if value <= 0 {
+		v.addf("%s deve ser maior que zero (atual: %d)", key, value)
+	}
+}
+
+func (v *configValidator) between(key string, value, min, max int) {
+	if value < min || value > max {
+		v.addf("%s deve estar entre %d e %d (atual: %d)", key, min, max, value)
+	}
+}
+
+func (v *configValidator) oneOf(key, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if strings.EqualFold(value, candidate) {
+			return
+		}
+	}
+	v.addf("%s deve ser um de: %s (atual: %q)", key, strings.Join(allowed, ", "), value)
+}
+
+func (v *configValidator) readableFile(key, path string) {
+	if path == "" {
+		return
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		v.addf("%s não é um arquivo legível: %q", key, path)
+	}
+}
+
+func (v *configValidator) absoluteURL(key, value string, schemes ...string) {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || (parsed.Host == "" && parsed.Opaque == "" && parsed.Path == "") {
+		v.addf("%s não é uma URL válida: %q", key, value)
+		return
+	}
+	if len(schemes) > 0 {
+		v.oneOf(key+" (esquema)", parsed.Scheme, schemes...)
+	}
+}
+
+// Validate confere a configuração completa e retorna *ValidationError com todos
+// os problemas encontrados.
+func Validate(conf ConfigEnv) error {
+	v := &configValidator{}
+
+	v.between("service.port", conf.Service.Port, 1, 65535)
+	v.oneOf("service.mode", conf.Service.Mode, "debug", "release", "test")
+	if conf.Service.N8nWebhookURL == "" {
+		v.addf("service.n8nWebhookURL é obrigatório")
+	} else {
+		v.absoluteURL("service.n8nWebhookURL", conf.Service.N8nWebhookURL, "http", "https")
+	}
+
+	v.positive("service.chatTimeoutSeconds", conf.Service.ChatTimeoutSeconds)
+	v.positive("service.readTimeoutSeconds", conf.Service.ReadTimeoutSeconds)
+	v.positive("service.writeTimeoutSeconds", conf.Service.WriteTimeoutSeconds)
+	v.positive("service.idleTimeoutSeconds", conf.Service.IdleTimeoutSeconds)
+	v.positive("service.shutdownTimeoutSeconds", conf.Service.ShutdownTimeoutSeconds)
+	if conf.Service.ChatTimeoutSeconds >= conf.Service.WriteTimeoutSeconds {
+		v.addf("service.chatTimeoutSeconds (%d) deve ser menor que service.writeTimeoutSeconds (%d)",
+			conf.Service.ChatTimeoutSeconds, conf.Service.WriteTimeoutSeconds)
+	}
+	if (conf.Service.TLSCertFile == "") != (conf.Service.TLSKeyFile == "") {
+		v.addf("service.tlsCertFile e service.tlsKeyFile devem ser definidos juntos")
+	}
+	v.readableFile("service.tlsCertFile", conf.Service.TLSCertFile)
+	v.readableFile("service.tlsKeyFile", conf.Service.TLSKeyFile)
+
+	v.oneOf("database.backend", conf.Database.Backend, "mongodb", "embedded")
+	if conf.Database.Embedded() {
+		if conf.Database.EmbeddedPath == "" {
+			v.addf("database.embeddedPath é obrigatório com o backend embedded")
+		}
+	} else {
+		if conf.Database.MongoURI == "" {
+			v.addf("database.mongoURI é obrigatório")
+		} else {
+			v.absoluteURL("database.mongoURI", conf.Database.MongoURI, "mongodb", "mongodb+srv")
+		}
+		if conf.Database.DBName == "" {
+			v.addf("database.dbName é obrigatório")
+		}
+	}
+	v.positive("database.connectTimeoutSeconds", conf.Database.ConnectTimeoutSeconds)
+	v.positive("database.queryTimeoutSeconds", conf.Database.QueryTimeoutSeconds)
+
+	for _, origin := range conf.CORS.AllowedOrigins {
+		if origin == "*" {
+			v.addf("cors.allowedOrigins não aceita \"*\" porque as credenciais são enviadas")
+			continue
+		}
+		v.absoluteURL("cors.allowedOrigins", origin, "http", "https")
+	}
+	v.positive("cors.maxAgeHours", conf.CORS.MaxAgeHours)
+
+	v.positive("uploads.maxAvatarMB", conf.Uploads.MaxAvatarMB)
+	v.positive("uploads.maxImageMB", conf.Uploads.MaxImageMB)
+
+	jwtConf := conf.Jwt
+	v.positive("jwt.accessTokenTTLMinutes", jwtConf.AccessTokenTTLMinutes)
+	v.positive("jwt.refreshTokenTTLDays", jwtConf.RefreshTokenTTLDays)
+	v.oneOf("jwt.algorithm", jwtConf.Algorithm, "HS256", "RS256", "EdDSA")
+	usesSharedSecret := strings.EqualFold(jwtConf.Algorithm, "HS256") || jwtConf.AcceptLegacyHS256
+	if usesSharedSecret && len(jwtConf.JWTSECRET) < minJWTSecretLength {
+		v.addf("jwt.jwtsecret deve ter pelo menos %d caracteres quando HS256 é usado ou aceito", minJWTSecretLength)
+	}
+	if !strings.EqualFold(jwtConf.Algorithm, "HS256") {
+		if jwtConf.KeysDir == "" {
+			v.addf("jwt.keysDir é obrigatório com o algoritmo %s", jwtConf.Algorithm)
+		}
+		v.positive("jwt.keyRotationDays", jwtConf.KeyRotationDays)
+		v.positive("jwt.retiredKeyRetentionHours", jwtConf.RetiredKeyRetentionHours)
+	}
+
+	mailConf := conf.Mail
+	if mailConf.SMTPHost != "" {
+		v.between("mail.smtpPort", mailConf.SMTPPort, 1, 65535)
+		if mailConf.From == "" {
+			v.addf("mail.from é obrigatório quando mail.smtpHost está definido")
+		}
+	}
+	if mailConf.PasswordResetURL != "" {
+		v.absoluteURL("mail.passwordResetURL", mailConf.PasswordResetURL)
+	}
+	if mailConf.EmailVerificationURL != "" {
+		v.absoluteURL("mail.emailVerificationURL", mailConf.EmailVerificationURL)
+	}
+	v.positive("mail.passwordResetTTLMinutes", mailConf.PasswordResetTTLMinutes)
+	v.positive("mail.emailVerificationTTLHours", mailConf.EmailVerificationTTLHours)
+
+	authConf := conf.Auth
+	v.positive("auth.maxFailedLoginsPerAccount", authConf.MaxFailedLoginsPerAccount)
+	v.positive("auth.maxFailedLoginsPerIP", authConf.MaxFailedLoginsPerIP)
+	v.positive("auth.failedLoginWindowMinutes", authConf.FailedLoginWindowMinutes)
+	v.positive("auth.lockoutMinutes", authConf.LockoutMinutes)
+	if authConf.MFAIssuer == "" {
+		v.addf("auth.mfaIssuer é obrigatório")
+	}
+
+	passwordConf := conf.Password
+	v.oneOf("password.hashAlgorithm", passwordConf.HashAlgorithm, "bcrypt", "argon2id")
+	v.between("password.bcryptCost", passwordConf.BcryptCost, 4, 31)
+	v.positive("password.argon2Iterations", passwordConf.Argon2Iterations)
+	v.between("password.argon2Threads", passwordConf.Argon2Threads, 1, 255)
+	if passwordConf.Argon2MemoryKiB < 8*passwordConf.Argon2Threads {
+		v.addf("password.argon2MemoryKiB deve ser pelo menos 8 × argon2Threads (atual: %d)", passwordConf.Argon2MemoryKiB)
+	}
+	v.positive("password.minLength", passwordConf.MinLength)
+	if passwordConf.MaxLength < passwordConf.MinLength {
+		v.addf("password.maxLength (%d) deve ser maior ou igual a password.minLength (%d)", passwordConf.MaxLength, passwordConf.MinLength)
+	}
+
+	oidcConf := conf.OIDC
+	if oidcConf.GoogleClientID != "" {
+		v.absoluteURL("oidc.googleIssuer", oidcConf.GoogleIssuer, "https", "http")
+		if len(oidcConf.RedirectURIs) == 0 {
+			v.addf("oidc.redirectURIs é obrigatório quando oidc.googleClientID está definido")
+		}
+		for _, redirectURI := range oidcConf.RedirectURIs {
+			v.absoluteURL("oidc.redirectURIs", redirectURI)
+		}
+	}
+
+	syncConf := conf.Sync
+	if syncConf.CentralURL != "" {
+		v.absoluteURL("sync.centralURL", syncConf.CentralURL, "http", "https")
+	}
+	v.positive("sync.timeoutSeconds", syncConf.TimeoutSeconds)
+	v.between("sync.batchSize", syncConf.BatchSize, 1, MaxSyncBatchSize)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// Redacted retorna uma cópia da configuração com os segredos mascarados,
+// segura para exibir no terminal ou no log.
+func Redacted(conf ConfigEnv) ConfigEnv {
+	// Listas são compartilhadas com o original; nenhuma delas é mascarada
+	walkConfigFields(reflect.ValueOf(&conf).Elem(), func(_ string, field reflect.StructField, value reflect.Value) {
+		if value.Kind() != reflect.String || value.String() == "" {
+			return
+		}
+
+		switch field.Tag.Get("redact") {
+		case "true":
+			value.SetString(redactedValue)
+		case "url":
+			value.SetString(redactURLPassword(value.String()))
+		}
+	})
+	return conf
+}
+
+const redactedValue = "***"
+
+func redactURLPassword(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangeEmailInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewEmail        string `json:"new_email"`
+}
+
+// ChangePassword troca a senha do usuário autenticado e encerra as outras sessões
+func (ctrl *Controller) ChangePassword(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+	tokenClaims, _ := c.Get("tokenClaims")
+	claims, ok := tokenClaims.(*service.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+
+	var input ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.CurrentPassword == "" || input.NewPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Senha atual e nova senha são obrigatórias"})
+		return
+	}
+
+	err := ctrl.services.ChangePassword(c.Request.Context(), user, claims.SessionID, input.CurrentPassword, input.NewPassword, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if respondLoginBlocked(c, err) || respondPasswordPolicy(c, err, "new_password") {
+			return
+		}
+		if errors.Is(err, service.ErrCurrentPasswordInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  err.Error(),
+				"code":   "invalid_current_password",
+				"fields": gin.H{"current_password": []gin.H{{"code": "invalid", "message": err.Error()}}},
+			})
+			return
+		}
+		log.Printf("❌ Erro ao alterar senha de %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao alterar senha"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Senha alterada com sucesso. As outras sessões foram encerradas."})
+}
+
+// ChangeEmail envia um link de confirmação para o novo email; a troca só ocorre após a confirmação
+func (ctrl *Controller) ChangeEmail(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+
+	var input ChangeEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.CurrentPassword == "" || input.NewEmail == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Senha atual e novo email são obrigatórios"})
+		return
+	}
+
+	err := ctrl.services.RequestEmailChange(c.Request.Context(), user, input.CurrentPassword, input.NewEmail, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if respondLoginBlocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrCurrentPasswordInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  err.Error(),
+				"code":   "invalid_current_password",
+				"fields": gin.H{"current_password": []gin.H{{"code": "invalid", "message": err.Error()}}},
+			})
+		case errors.Is(err, service.ErrEmailInvalid),
+			errors.Is(err, service.ErrEmailUnchanged),
+			errors.Is(err, service.ErrEmailInUse):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  err.Error(),
+				"fields": gin.H{"new_email": []gin.H{{"code": "invalid", "message": err.Error()}}},
+			})
+		default:
+			log.Printf("❌ Erro ao solicitar troca de email de %s: %v", user.Email, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao solicitar troca de email"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Enviamos um link de confirmação para o novo email. A troca será concluída após a confirmação."})
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"log"
+	"metabee/internal/model/dao"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UpdateUserRole altera o papel de um usuário (somente administradores)
+func (ctrl *Controller) UpdateUserRole(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	admin := currentUser.(dao.UserDao)
+
+	userID, err := bson.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do usuário inválido"})
+		return
+	}
+
+	var input struct {
+		Role string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || !dao.IsValidRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Papel inválido. Use admin, instructor ou student"})
+		return
+	}
+
+	if userID == admin.ID && input.Role != dao.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Não é possível remover o próprio papel de administrador"})
+		return
+	}
+
+	if err := ctrl.repos.Users.UpdateUserProfile(c.Request.Context(), userID, bson.M{"role": input.Role}); err != nil {
+		log.Printf("❌ Erro ao alterar papel do usuário %s: %v", userID.Hex(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao alterar papel"})
+		return
+	}
+
+	log.Printf("✅ Papel alterado - UserID: %s, Papel: %s, Por: %s", userID.Hex(), input.Role, admin.Email)
+	c.JSON(http.StatusOK, gin.H{"message": "Papel atualizado com sucesso", "role": input.Role})
+}
+
+// UnlockUser remove o bloqueio de login de um usuário (somente administradores)
+func (ctrl *Controller) UnlockUser(c *gin.Context) {
+	userID := c.Param("userId")
+
+	user, err := ctrl.repos.Users.FindUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+
+	if err := ctrl.services.UnlockAccount(c.Request.Context(), user.Email); err != nil {
+		log.Printf("❌ Erro ao desbloquear usuário %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao desbloquear usuário"})
+		return
+	}
+
+	log.Printf("🔓 Login desbloqueado - UserID: %s, Email: %s", userID, user.Email)
+	c.JSON(http.StatusOK, gin.H{"message": "Usuário desbloqueado com sucesso"})
+}
+
+// UnlockIP remove o bloqueio de login de um endereço IP (somente administradores)
+func (ctrl *Controller) UnlockIP(c *gin.Context) {
+	ip := c.Param("ip")
+	if net.ParseIP(ip) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Endereço IP inválido"})
+		return
+	}
+
+	if err := ctrl.services.UnlockIP(c.Request.Context(), ip); err != nil {
+		log.Printf("❌ Erro ao desbloquear IP %s: %v", ip, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao desbloquear IP"})
+		return
+	}
+
+	log.Printf("🔓 Login desbloqueado - IP: %s", ip)
+	c.JSON(http.StatusOK, gin.H{"message": "IP desbloqueado com sucesso"})
+}
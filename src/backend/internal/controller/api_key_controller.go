@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAPIKeyInput struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // Padrão: 90, máximo: 365
+}
+
+func apiKeyOutput(key dao.APIKeyDao) gin.H {
+	output := gin.H{
+		"id":         key.ID.Hex(),
+		"name":       key.Name,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"created_at": key.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"expires_at": key.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !key.LastUsedAt.IsZero() {
+		output["last_used_at"] = key.LastUsedAt.Format("2006-01-02T15:04:05Z07:00")
+		output["last_used_ip"] = key.LastUsedIP
+	}
+	return output
+}
+
+// GetAPIKeys lista as chaves de API ativas do usuário autenticado
+func (ctrl *Controller) GetAPIKeys(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	keys, err := ctrl.services.ListAPIKeys(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar chaves de API de %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar chaves de API"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, apiKeyOutput(key))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": result})
+}
+
+// CreateAPIKey gera uma chave de API. O valor completo só é exibido nesta resposta.
+func (ctrl *Controller) CreateAPIKey(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+
+	var input CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome e escopos são obrigatórios"})
+		return
+	}
+
+	rawKey, key, err := ctrl.services.CreateAPIKey(c.Request.Context(), user, strings.TrimSpace(input.Name), input.Scopes, input.ExpiresInDays)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAPIKeyScopeInvalid),
+			errors.Is(err, service.ErrAPIKeyExpiryInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAPIKeyScopeDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			log.Printf("❌ Erro ao criar chave de API para %s: %v", user.Email, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar chave de API"})
+		}
+		return
+	}
+
+	output := apiKeyOutput(key)
+	output["key"] = rawKey
+	c.JSON(http.StatusCreated, output)
+}
+
+// RevokeAPIKey revoga uma chave de API do usuário autenticado
+func (ctrl *Controller) RevokeAPIKey(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	keyID := c.Param("id")
+
+	if err := ctrl.services.RevokeAPIKey(c.Request.Context(), user.ID, keyID); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao revogar chave de API %s de %s: %v", keyID, user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar chave de API"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chave de API revogada"})
+}
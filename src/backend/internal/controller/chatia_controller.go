@@ -2,11 +2,14 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"metabee/internal/config"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +22,7 @@ type N8nResponse struct {
 	Output string `json:"output"`
 }
 
-func ChatIa(c *gin.Context) {
+func (ctrl *Controller) ChatIa(c *gin.Context) {
 	// Verificar se o contexto é válido
 	if c == nil {
 		log.Println("Erro: gin.Context é nil")
@@ -59,9 +62,26 @@ func ChatIa(c *gin.Context) {
 		return
 	}
 
-	// Fazer requisição para n8n
-	resp, err := http.Post(n8nWebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	// Fazer requisição para n8n, cancelada se o cliente desistir ou o tempo limite passar
+	chatTimeout := time.Duration(config.Current().Service.ChatTimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(c.Request.Context(), chatTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n8nWebhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		log.Printf("Erro ao criar requisição para o n8n: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar requisição"})
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Printf("Tempo limite ao chamar n8n (%s)", chatTimeout)
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "O serviço de IA demorou demais para responder"})
+			return
+		}
 		log.Printf("Erro ao chamar n8n: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao conectar com o serviço de IA"})
 		return
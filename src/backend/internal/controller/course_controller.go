@@ -1,25 +1,18 @@
 package controller
 
 import (
-	"context"
 	"log"
-	"metabee/internal/database"
-	"metabee/internal/model/dao"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // GetAllCourses retorna todos os cursos disponíveis
-func GetAllCourses(c *gin.Context) {
+func (ctrl *Controller) GetAllCourses(c *gin.Context) {
 	log.Printf("✅ GET /metabee/marketplace/courses - Requisição recebida")
 	log.Printf("   Path: %s, Method: %s, IP: %s", c.Request.URL.Path, c.Request.Method, c.ClientIP())
 	
-	var courseDao dao.CourseDao
-
-	courses, err := courseDao.GetAllCourses()
+	courses, err := ctrl.repos.Courses.GetAllCourses(c.Request.Context())
 	if err != nil {
 		log.Printf("Erro ao buscar cursos: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar cursos", "details": err.Error()})
@@ -33,16 +26,12 @@ func GetAllCourses(c *gin.Context) {
 }
 
 // GetMarketplaceStats retorna estatísticas do marketplace
-func GetMarketplaceStats(c *gin.Context) {
+func (ctrl *Controller) GetMarketplaceStats(c *gin.Context) {
 	log.Printf("✅ GET /metabee/marketplace/stats - Requisição recebida")
 	log.Printf("   Path: %s, Method: %s, IP: %s", c.Request.URL.Path, c.Request.Method, c.ClientIP())
-	
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
 
 	// Contar total de cursos
-	var courseDao dao.CourseDao
-	courses, err := courseDao.GetAllCourses()
+	courses, err := ctrl.repos.Courses.GetAllCourses(c.Request.Context())
 	if err != nil {
 		log.Printf("Erro ao buscar cursos para estatísticas: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar estatísticas"})
@@ -68,8 +57,7 @@ func GetMarketplaceStats(c *gin.Context) {
 	}
 
 	// Contar total de usuários na collection "user"
-	userCollection := database.DB.Collection("user")
-	totalUsersCount, err := userCollection.CountDocuments(ctx, bson.M{})
+	totalUsersCount, err := ctrl.repos.Users.CountUsers(c.Request.Context())
 	totalUsers := int64(0)
 	if err != nil {
 		log.Printf("Erro ao contar usuários: %v", err)
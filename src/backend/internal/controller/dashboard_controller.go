@@ -10,7 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-func Dashboard(c *gin.Context) {
+func (ctrl *Controller) Dashboard(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -21,35 +21,29 @@ func Dashboard(c *gin.Context) {
 	user := currentUser.(dao.UserDao)
 	userID := user.ID
 
-	// Buscar dados do dashboard
-	progressDao := dao.UserProgressDao{}
-	courseDao := dao.CourseDao{}
-	newsDao := dao.NewsDao{}
-	lessonDao := dao.LessonDao{}
-
 	// Cursos ativos
-	activeCourses, err := progressDao.GetActiveCourses(userID)
+	activeCourses, err := ctrl.repos.Progress.GetActiveCourses(c.Request.Context(), userID)
 	if err != nil {
 		log.Printf("Erro ao buscar cursos ativos: %v", err)
 		activeCourses = []dao.UserProgressDao{}
 	}
 
 	// Horas estudadas este mês
-	hoursThisMonth, err := progressDao.GetHoursThisMonth(userID)
+	hoursThisMonth, err := ctrl.repos.Progress.GetHoursThisMonth(c.Request.Context(), userID)
 	if err != nil {
 		log.Printf("Erro ao buscar horas do mês: %v", err)
 		hoursThisMonth = 0
 	}
 
 	// Progresso médio
-	avgProgress, err := progressDao.GetAverageProgress(userID)
+	avgProgress, err := ctrl.repos.Progress.GetAverageProgress(c.Request.Context(), userID)
 	if err != nil {
 		log.Printf("Erro ao buscar progresso médio: %v", err)
 		avgProgress = 0
 	}
 
 	// Aulas recentes
-	recentSessions, err := dao.GetRecentLessons(userID, 5)
+	recentSessions, err := ctrl.repos.Progress.GetRecentLessons(c.Request.Context(), userID, 5)
 	if err != nil {
 		log.Printf("Erro ao buscar aulas recentes: %v", err)
 		recentSessions = []dao.StudySessionDao{}
@@ -65,7 +59,7 @@ func Dashboard(c *gin.Context) {
 			}
 		}
 		if len(lessonIDs) > 0 {
-			recentLessons, err = lessonDao.GetLessonsByIDs(lessonIDs)
+			recentLessons, err = ctrl.repos.Lessons.GetLessonsByIDs(c.Request.Context(), lessonIDs)
 			if err != nil {
 				log.Printf("Erro ao buscar detalhes das aulas: %v", err)
 			}
@@ -73,14 +67,14 @@ func Dashboard(c *gin.Context) {
 	}
 
 	// Últimas 2 notícias
-	lastNews, err := newsDao.GetLastNews(2)
+	lastNews, err := ctrl.repos.News.GetLastNews(c.Request.Context(), 2)
 	if err != nil {
 		log.Printf("Erro ao buscar notícias: %v", err)
 		lastNews = []dao.NewsDao{}
 	}
 
 	// Últimos 2 cursos adicionados
-	lastCourses, err := courseDao.GetLastTwoCourses()
+	lastCourses, err := ctrl.repos.Courses.GetLastTwoCourses(c.Request.Context())
 	if err != nil {
 		log.Printf("Erro ao buscar cursos: %v", err)
 		lastCourses = []dao.CourseDao{}
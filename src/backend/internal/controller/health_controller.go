@@ -1,13 +1,42 @@
 package controller
 
 import (
+	"metabee/internal/buildinfo"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetHealth(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-	})
+func buildOutput() gin.H {
+	return gin.H{
+		"version":        buildinfo.Version,
+		"commit":         buildinfo.Commit,
+		"uptime_seconds": int64(buildinfo.Uptime().Seconds()),
+	}
+}
+
+// GetLiveness indica apenas que o processo está respondendo; não verifica dependências
+func (ctrl *Controller) GetLiveness(c *gin.Context) {
+	output := buildOutput()
+	output["status"] = "ok"
+	c.JSON(http.StatusOK, output)
+}
+
+// GetReadiness verifica o MongoDB e o backend de IA e responde 503 com os
+// detalhes de cada dependência quando alguma estiver indisponível
+func (ctrl *Controller) GetReadiness(c *gin.Context) {
+	dependencies := ctrl.services.CheckDependencies(c.Request.Context())
+
+	status, httpStatus := "ok", http.StatusOK
+	for _, dependency := range dependencies {
+		if !dependency.Healthy {
+			status, httpStatus = "degraded", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	output := buildOutput()
+	output["status"] = status
+	output["dependencies"] = dependencies
+	c.JSON(httpStatus, output)
 }
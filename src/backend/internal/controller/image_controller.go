@@ -1,22 +1,20 @@
 package controller
 
 import (
-	"context"
+	"fmt"
 	"io"
 	"log"
-	"metabee/internal/database"
-	"metabee/internal/model/dao"
+	"metabee/internal/config"
 	"net/http"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // GetCourseImage retorna a imagem de um curso
-func GetCourseImage(c *gin.Context) {
+func (ctrl *Controller) GetCourseImage(c *gin.Context) {
 	courseID := c.Param("courseId")
 	log.Printf("GET /metabee/courses/%s/image - Requisição recebida", courseID)
 
@@ -27,8 +25,7 @@ func GetCourseImage(c *gin.Context) {
 		return
 	}
 
-	var courseDao dao.CourseDao
-	course, err := courseDao.FindByID(objID)
+	course, err := ctrl.repos.Courses.FindByID(c.Request.Context(), objID)
 	if err != nil {
 		log.Printf("Curso não encontrado: %s - %v", courseID, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Curso não encontrado"})
@@ -67,7 +64,7 @@ func GetCourseImage(c *gin.Context) {
 }
 
 // UpdateCourseImage atualiza a imagem de um curso
-func UpdateCourseImage(c *gin.Context) {
+func (ctrl *Controller) UpdateCourseImage(c *gin.Context) {
 	courseID := c.Param("courseId")
 
 	objID, err := bson.ObjectIDFromHex(courseID)
@@ -84,6 +81,13 @@ func UpdateCourseImage(c *gin.Context) {
 	}
 	defer file.Close()
 
+	// Validar tamanho ([uploads].maxImageMB)
+	maxImageMB := config.Current().Uploads.MaxImageMB
+	if header.Size > int64(maxImageMB)*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Arquivo muito grande. Máximo %dMB", maxImageMB)})
+		return
+	}
+
 	// Ler os bytes da imagem
 	imageData, err := io.ReadAll(file)
 	if err != nil {
@@ -114,33 +118,15 @@ func UpdateCourseImage(c *gin.Context) {
 		}
 	}
 
-	// Criar Binary do MongoDB
-	binaryData := bson.Binary{
-		Subtype: 0, // Generic binary subtype
-		Data:    imageData,
-	}
-
 	// Atualizar o curso no banco
-	collection := database.DB.Collection("courses")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	update := bson.M{
-		"$set": bson.M{
-			"image_data": binaryData,
-			"image_type": contentType,
-			"updated_at": time.Now(),
-		},
-	}
-
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	updated, err := ctrl.repos.Courses.UpdateCourseImage(c.Request.Context(), objID, imageData, contentType)
 	if err != nil {
 		log.Printf("Erro ao atualizar imagem: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar imagem"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
+	if !updated {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Curso não encontrado"})
 		return
 	}
@@ -153,7 +139,7 @@ func UpdateCourseImage(c *gin.Context) {
 }
 
 // GetImageByName serve uma imagem pelo nome (images/nome-da-img.extensao)
-func GetImageByName(c *gin.Context) {
+func (ctrl *Controller) GetImageByName(c *gin.Context) {
 	imageName := c.Param("imageName")
 	log.Printf("GET /images/%s - Requisição recebida", imageName)
 
@@ -162,8 +148,7 @@ func GetImageByName(c *gin.Context) {
 		return
 	}
 
-	var imageDao dao.ImageDao
-	image, err := imageDao.FindImageByName(imageName)
+	image, err := ctrl.repos.Images.FindImageByName(c.Request.Context(), imageName)
 	if err != nil {
 		log.Printf("Imagem não encontrada: %s - %v", imageName, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Imagem não encontrada"})
@@ -196,7 +181,7 @@ func GetImageByName(c *gin.Context) {
 }
 
 // GetNewsImage retorna a imagem de uma notícia
-func GetNewsImage(c *gin.Context) {
+func (ctrl *Controller) GetNewsImage(c *gin.Context) {
 	newsID := c.Param("id")
 	log.Printf("GET /metabee/news/%s/image - Requisição recebida", newsID)
 
@@ -207,8 +192,7 @@ func GetNewsImage(c *gin.Context) {
 		return
 	}
 
-	var newsDao dao.NewsDao
-	news, err := newsDao.FindByID(objID)
+	news, err := ctrl.repos.News.FindByID(c.Request.Context(), objID)
 	if err != nil {
 		log.Printf("Notícia não encontrada: %s - %v", newsID, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Notícia não encontrada"})
@@ -217,8 +201,7 @@ func GetNewsImage(c *gin.Context) {
 
 	// 1. Se ImageID está presente, buscar da collection images
 	if !news.ImageID.IsZero() {
-		var imageDao dao.ImageDao
-		image, err := imageDao.FindImageByID(news.ImageID)
+		image, err := ctrl.repos.Images.FindImageByID(c.Request.Context(), news.ImageID)
 		if err == nil && len(image.Data.Data) > 0 {
 			contentType := image.MimeType
 			if contentType == "" {
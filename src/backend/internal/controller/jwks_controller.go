@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publica as chaves públicas usadas para verificar os access tokens
+func (ctrl *Controller) GetJWKS(c *gin.Context) {
+	// Cache menor que o atraso de publicação das chaves novas (ver service.signingKeyPublicationDelay)
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, service.PublicJWKS())
+}
@@ -12,7 +12,7 @@ import (
 )
 
 // GetCourseLessons retorna as aulas de um curso baixado localmente
-func GetCourseLessons(c *gin.Context) {
+func (ctrl *Controller) GetCourseLessons(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -29,8 +29,7 @@ func GetCourseLessons(c *gin.Context) {
 	}
 
 	// Verificar se o usuário comprou o curso
-	purchaseDao := dao.PurchaseDao{}
-	purchase, err := purchaseDao.GetPurchaseByUserAndCourse(user.ID, courseObjID)
+	purchase, err := ctrl.repos.Purchases.GetPurchaseByUserAndCourse(c.Request.Context(), user.ID, courseObjID)
 	if err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Curso não comprado ou não encontrado"})
 		return
@@ -57,7 +56,7 @@ func GetCourseLessons(c *gin.Context) {
 }
 
 // GetLessonVideo retorna informações sobre um vídeo específico
-func GetLessonVideo(c *gin.Context) {
+func (ctrl *Controller) GetLessonVideo(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -75,8 +74,7 @@ func GetLessonVideo(c *gin.Context) {
 	}
 
 	// Verificar se o usuário comprou o curso
-	purchaseDao := dao.PurchaseDao{}
-	purchase, err := purchaseDao.GetPurchaseByUserAndCourse(user.ID, courseObjID)
+	purchase, err := ctrl.repos.Purchases.GetPurchaseByUserAndCourse(c.Request.Context(), user.ID, courseObjID)
 	if err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Curso não comprado ou não encontrado"})
 		return
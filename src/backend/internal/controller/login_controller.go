@@ -1,23 +1,44 @@
 package controller
 
 import (
+	"errors"
 	"log"
+	"math"
 	"metabee/internal/adapter"
 	"metabee/internal/model/dao"
 	"metabee/internal/service"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type LoginOutput struct {
+	Token        string `json:"token"` // Mesmo valor de access_token, mantido para clientes antigos
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 	UserID       string `json:"user_id"`
+	// Conta de equipe sem 2FA: o login funciona, mas as rotas de gerenciamento exigem o cadastro
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
 }
 
-func Login(c *gin.Context) {
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func newLoginOutput(tokens service.TokenPair) LoginOutput {
+	return LoginOutput{
+		Token:        tokens.AccessToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		UserID:       tokens.UserID,
+	}
+}
+
+func (ctrl *Controller) Login(c *gin.Context) {
 	var input dao.LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos: " + err.Error()})
@@ -34,44 +55,183 @@ func Login(c *gin.Context) {
 	originalEmail := input.Email
 	input.Email = strings.TrimSpace(strings.ToLower(input.Email))
 	log.Printf("🔐 Tentativa de login - Email original: '%s', normalizado: '%s'", originalEmail, input.Email)
-	log.Printf("🔐 Senha recebida tem %d caracteres", len(input.Password))
+
+	clientIP := c.ClientIP()
+	if err := ctrl.services.CheckLoginAllowed(c.Request.Context(), input.Email, clientIP); err != nil {
+		if respondLoginBlocked(c, err) {
+			log.Printf("🔒 Login recusado para '%s' (IP %s): %v", input.Email, clientIP, err)
+			return
+		}
+		log.Printf("❌ Erro ao verificar tentativas de login: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao processar login"})
+		return
+	}
 
 	userDto := adapter.UserAdapter{}.LoginInputToDao(input)
 
-	var userDao dao.UserDao
-	user, err := userDao.FindUserByEmail(userDto.Email)
+	user, err := ctrl.repos.Users.FindUserByEmail(c.Request.Context(), userDto.Email)
 	if err != nil {
 		log.Printf("❌ Erro ao buscar usuário por email '%s': %v", userDto.Email, err)
+		ctrl.services.RegisterFailedLogin(c.Request.Context(), input.Email, clientIP)
 		c.JSON(http.StatusBadRequest, gin.H{"erro": "Email ou senha inválidos"})
 		return
 	}
 
-	log.Printf("✅ Usuário encontrado: email='%s', ID='%s'", user.Email, user.ID.Hex())
-	log.Printf("🔍 Verificando senha... (hash no banco tem %d caracteres)", len(user.Password))
-	
+
 	if user.Password == "" {
+		if user.GoogleSubject != "" {
+			// Mesma resposta de uma senha errada: indicar o login com Google revelaria que o email está cadastrado
+			log.Printf("❌ Login com senha em conta só com Google: %s", user.Email)
+			ctrl.services.RegisterFailedLogin(c.Request.Context(), input.Email, clientIP)
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Email ou senha inválidos"})
+			return
+		}
 		log.Printf("❌ Hash de senha vazio no banco de dados!")
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Problema na senha do usuário. Contate o suporte."})
 		return
 	}
 
 	// Verificar se a senha está correta
-	log.Printf("🔍 Comparando senha fornecida com hash do banco...")
-	if !userDao.CheckPasswordHash(input.Password, user.Password) {
+	passwordOK, needsRehash := service.VerifyPassword(input.Password, user.Password)
+	if !passwordOK {
 		log.Printf("❌ Senha incorreta para usuário: %s", user.Email)
+		ctrl.services.RegisterFailedLogin(c.Request.Context(), input.Email, clientIP)
 		c.JSON(http.StatusBadRequest, gin.H{"erro": "Email ou senha inválidos"})
 		return
 	}
 
 	log.Printf("✅ Senha verificada com sucesso para usuário: %s", user.Email)
+	ctrl.services.RehashPasswordIfNeeded(c.Request.Context(), user, input.Password, needsRehash)
 
-	token, err := service.GenerateJWT(user.ID.Hex())
+	ctrl.completeLogin(c, user)
+}
+
+// completeLogin entrega o par de tokens após a autenticação primária (senha ou
+// Google) ou, com 2FA ativo, o token intermediário para /auth/mfa/verify.
+func (ctrl *Controller) completeLogin(c *gin.Context, user dao.UserDao) {
+	if user.MFAEnabled {
+		mfaToken, err := service.GenerateMFAPendingToken(user)
+		if err != nil {
+			log.Printf("❌ Erro ao gerar token de verificação em duas etapas: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"user_id":      user.ID.Hex(),
+		})
+		return
+	}
+
+	ctrl.services.RegisterSuccessfulLogin(c.Request.Context(), user.Email)
+
+	tokens, err := ctrl.services.IssueTokenPair(c.Request.Context(), user, sessionInfo(c))
 	if err != nil {
+		log.Printf("❌ Erro ao gerar tokens: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
+	output := newLoginOutput(tokens)
+	output.MFAEnrollmentRequired = service.MFAEnrollmentRequired(user)
+	c.JSON(http.StatusOK, output)
+}
+
+// sessionInfo identifica o dispositivo da requisição. O cliente desktop envia o
+// nome do computador no header X-Device-Name.
+func sessionInfo(c *gin.Context) service.SessionInfo {
+	deviceName := strings.TrimSpace(c.GetHeader("X-Device-Name"))
+	// Corta por caracteres, não por bytes, para não partir um caractere acentuado ao meio
+	if runes := []rune(deviceName); len(runes) > 100 {
+		deviceName = string(runes[:100])
+	}
+
+	return service.SessionInfo{
+		DeviceName: deviceName,
+		IP:         c.ClientIP(),
+		UserAgent:  c.GetHeader("User-Agent"),
+	}
+}
+
+// respondLoginBlocked responde 429 com o código do bloqueio se err for um
+// *service.LoginBlockedError; retorna false para qualquer outro erro.
+func respondLoginBlocked(c *gin.Context, err error) bool {
+	var blocked *service.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"erro":        "Muitas tentativas de login. Tente novamente mais tarde.",
+		"code":        blocked.Code,
+		"retry_after": retryAfter,
 	})
+	return true
+}
+
+// RefreshToken troca um refresh token válido por um novo par de tokens
+func (ctrl *Controller) RefreshToken(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token é obrigatório"})
+		return
+	}
+
+	tokens, err := ctrl.services.RefreshTokenPair(c.Request.Context(), input.RefreshToken, sessionInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenInvalid),
+			errors.Is(err, service.ErrRefreshTokenExpired),
+			errors.Is(err, service.ErrRefreshTokenReused):
+			log.Printf("❌ Refresh recusado: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			log.Printf("❌ Erro ao renovar tokens: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao renovar token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginOutput(tokens))
+}
+
+// Logout encerra a sessão atual (access token e refresh tokens da mesma família)
+func (ctrl *Controller) Logout(c *gin.Context) {
+	tokenClaims, _ := c.Get("tokenClaims")
+	claims, ok := tokenClaims.(*service.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	if err := ctrl.services.RevokeSession(c.Request.Context(), claims); err != nil {
+		log.Printf("❌ Erro ao encerrar sessão do usuário %s: %v", claims.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessão"})
+		return
+	}
+
+	log.Printf("✅ Sessão encerrada - UserID: %s, Sessão: %s", claims.UserID, claims.SessionID)
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada com sucesso"})
+}
+
+// LogoutAll encerra todas as sessões do usuário em todos os dispositivos
+func (ctrl *Controller) LogoutAll(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	if err := ctrl.services.RevokeAllSessions(c.Request.Context(), user.ID); err != nil {
+		log.Printf("❌ Erro ao encerrar todas as sessões do usuário %s: %v", user.ID.Hex(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessões"})
+		return
+	}
+
+	log.Printf("✅ Todas as sessões encerradas - UserID: %s", user.ID.Hex())
+	c.JSON(http.StatusOK, gin.H{"message": "Todas as sessões foram encerradas"})
 }
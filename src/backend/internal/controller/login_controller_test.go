@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Nomes de computador acentuados não podem ser cortados no meio de um caractere
+func TestSessionInfoTruncatesDeviceNameByRunes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	c.Request.Header.Set("X-Device-Name", "a"+strings.Repeat("ç", 120))
+
+	name := sessionInfo(c).DeviceName
+	if !utf8.ValidString(name) {
+		t.Fatalf("nome do dispositivo com UTF-8 inválido: %q", name)
+	}
+	if count := utf8.RuneCountInString(name); count != 100 {
+		t.Fatalf("o nome deve ter 100 caracteres, tem %d", count)
+	}
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFACodeInput struct {
+	Code string `json:"code"`
+}
+
+type MFAVerifyInput struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // Código TOTP ou código de recuperação
+}
+
+// StartMFAEnrollment gera o segredo TOTP e a URI otpauth:// para o aplicativo autenticador
+func (ctrl *Controller) StartMFAEnrollment(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	enrollment, err := ctrl.services.StartMFAEnrollment(c.Request.Context(), user)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao iniciar cadastro de 2FA para %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao iniciar cadastro"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      enrollment.Secret,
+		"otpauth_uri": enrollment.OTPAuthURI,
+	})
+}
+
+// ConfirmMFAEnrollment ativa o 2FA e devolve os códigos de recuperação
+func (ctrl *Controller) ConfirmMFAEnrollment(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+
+	var input MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Código é obrigatório"})
+		return
+	}
+
+	recoveryCodes, err := ctrl.services.ConfirmMFAEnrollment(c.Request.Context(), user, input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMFAInvalidCode),
+			errors.Is(err, service.ErrMFAAlreadyEnabled),
+			errors.Is(err, service.ErrMFANoPendingEnrollment):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("❌ Erro ao confirmar 2FA para %s: %v", user.Email, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ativar verificação em duas etapas"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Verificação em duas etapas ativada. Guarde os códigos de recuperação em local seguro.",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableMFA desativa o 2FA mediante um código válido
+func (ctrl *Controller) DisableMFA(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+
+	var input MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Código é obrigatório"})
+		return
+	}
+
+	if err := ctrl.services.DisableMFA(c.Request.Context(), user, input.Code); err != nil {
+		if errors.Is(err, service.ErrMFAInvalidCode) || errors.Is(err, service.ErrMFANotEnabled) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao desativar 2FA para %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao desativar verificação em duas etapas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verificação em duas etapas desativada"})
+}
+
+// VerifyMFALogin conclui o login em duas etapas
+func (ctrl *Controller) VerifyMFALogin(c *gin.Context) {
+	var input MFAVerifyInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.MFAToken == "" || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa_token e code são obrigatórios"})
+		return
+	}
+
+	tokens, err := ctrl.services.CompleteMFALogin(c.Request.Context(), input.MFAToken, input.Code, sessionInfo(c))
+	if err != nil {
+		if respondLoginBlocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrMFAPendingTokenInvalid):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrMFAInvalidCode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("❌ Erro ao concluir login em duas etapas: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginOutput(tokens))
+}
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"log"
-	"metabee/internal/model/dao"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,13 +9,11 @@ import (
 )
 
 // GetAllNews retorna todas as notícias disponíveis
-func GetAllNews(c *gin.Context) {
+func (ctrl *Controller) GetAllNews(c *gin.Context) {
 	log.Printf("✅ GET /metabee/news - Requisição recebida")
 	log.Printf("   Path: %s, Method: %s, IP: %s", c.Request.URL.Path, c.Request.Method, c.ClientIP())
 	
-	var newsDao dao.NewsDao
-
-	news, err := newsDao.GetAllNews()
+	news, err := ctrl.repos.News.GetAllNews(c.Request.Context())
 	if err != nil {
 		log.Printf("Erro ao buscar notícias: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar notícias", "details": err.Error()})
@@ -30,7 +27,7 @@ func GetAllNews(c *gin.Context) {
 }
 
 // GetNewsByID retorna uma notícia específica por ID
-func GetNewsByID(c *gin.Context) {
+func (ctrl *Controller) GetNewsByID(c *gin.Context) {
 	newsID := c.Param("id")
 	log.Printf("✅ GET /metabee/news/%s - Requisição recebida", newsID)
 
@@ -41,8 +38,7 @@ func GetNewsByID(c *gin.Context) {
 		return
 	}
 
-	var newsDao dao.NewsDao
-	news, err := newsDao.FindByID(objID)
+	news, err := ctrl.repos.News.FindByID(c.Request.Context(), objID)
 	if err != nil {
 		log.Printf("Notícia não encontrada: %s - %v", newsID, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Notícia não encontrada"})
@@ -55,13 +51,12 @@ func GetNewsByID(c *gin.Context) {
 }
 
 // GetLastNews retorna as últimas notícias
-func GetLastNews(c *gin.Context) {
+func (ctrl *Controller) GetLastNews(c *gin.Context) {
 	log.Printf("✅ GET /metabee/news/last - Requisição recebida")
 	
-	var newsDao dao.NewsDao
 	limit := 5 // Padrão: 5 últimas notícias
 
-	news, err := newsDao.GetLastNews(limit)
+	news, err := ctrl.repos.News.GetLastNews(c.Request.Context(), limit)
 	if err != nil {
 		log.Printf("Erro ao buscar últimas notícias: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar notícias"})
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCStartInput struct {
+	RedirectURI string `json:"redirect_uri"` // Ex.: http://127.0.0.1:53682/oauth/callback
+}
+
+type OIDCCallbackInput struct {
+	State string `json:"state"`
+	Code  string `json:"code"`
+}
+
+// StartGoogleLogin devolve a URL de autorização que o cliente abre no navegador do sistema
+func (ctrl *Controller) StartGoogleLogin(c *gin.Context) {
+	var input OIDCStartInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.RedirectURI == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "redirect_uri é obrigatória"})
+		return
+	}
+
+	authorization, err := ctrl.services.StartOIDCLogin(c.Request.Context(), input.RedirectURI)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCNotConfigured):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOIDCRedirectURIInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("❌ Erro ao iniciar login com Google: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao iniciar login com Google"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"authorization_url": authorization.AuthorizationURL,
+		"state":             authorization.State,
+	})
+}
+
+// CompleteGoogleLogin recebe o code e o state capturados no redirect e entrega os tokens da Metabee
+func (ctrl *Controller) CompleteGoogleLogin(c *gin.Context) {
+	var input OIDCCallbackInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.State == "" || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state e code são obrigatórios"})
+		return
+	}
+
+	user, err := ctrl.services.CompleteOIDCLogin(c.Request.Context(), input.State, input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCNotConfigured):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOIDCStateInvalid),
+			errors.Is(err, service.ErrOIDCEmailNotVerified):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOIDCAccountNotVerified),
+			errors.Is(err, service.ErrOIDCAccountLinkConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOIDCSignupDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			log.Printf("❌ Erro ao concluir login com Google: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao concluir login com Google"})
+		}
+		return
+	}
+
+	log.Printf("✅ Login com Google - UserID: %s", user.ID.Hex())
+	ctrl.completeLogin(c, user)
+}
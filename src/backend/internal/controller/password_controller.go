@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForgotPasswordInput struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ForgotPassword envia um link de redefinição de senha para o email informado
+func (ctrl *Controller) ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Email) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email é obrigatório"})
+		return
+	}
+
+	email := strings.TrimSpace(strings.ToLower(input.Email))
+	if err := ctrl.services.RequestPasswordReset(c.Request.Context(), email); err != nil {
+		// Só falha para emails cadastrados: um erro na resposta revelaria quais existem
+		log.Printf("❌ Erro ao solicitar redefinição de senha para %s: %v", email, err)
+	}
+
+	// Mesma resposta para emails cadastrados ou não
+	c.JSON(http.StatusOK, gin.H{"message": "Se o email estiver cadastrado, você receberá as instruções para redefinir a senha"})
+}
+
+// ResetPassword define uma nova senha a partir do token recebido por email
+func (ctrl *Controller) ResetPassword(c *gin.Context) {
+	var input ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos: " + err.Error()})
+		return
+	}
+
+	if input.Token == "" || input.NewPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token e nova senha são obrigatórios"})
+		return
+	}
+
+	if err := ctrl.services.ResetPassword(c.Request.Context(), input.Token, input.NewPassword); err != nil {
+		if respondPasswordPolicy(c, err, "new_password") {
+			return
+		}
+		if errors.Is(err, service.ErrPasswordResetInvalid) || errors.Is(err, service.ErrPasswordResetExpired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao redefinir senha: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao redefinir senha"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Senha redefinida com sucesso. Faça login novamente."})
+}
+
+// GetPasswordPolicy retorna os requisitos de senha para o cliente exibir no formulário
+func (ctrl *Controller) GetPasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, service.CurrentPasswordPolicy())
+}
+
+// respondPasswordPolicy responde 400 com as violações agrupadas pelo campo do
+// formulário se err for um *service.PasswordPolicyError; retorna false caso contrário.
+func respondPasswordPolicy(c *gin.Context, err error, field string) bool {
+	var policyErr *service.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": policyErr.Error(),
+		"code":  "weak_password",
+		"fields": gin.H{
+			field: policyErr.Violations,
+		},
+	})
+	return true
+}
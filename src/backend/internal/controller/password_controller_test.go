@@ -0,0 +1,196 @@
+package controller_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"metabee/internal/controller"
+	"metabee/internal/mailer"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"metabee/internal/repository/embedded"
+	"metabee/internal/service"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeSMTP é um servidor SMTP local mínimo (sem TLS nem autenticação) que
+// guarda as mensagens recebidas. Com rejectRecipients, recusa todo destinatário.
+type fakeSMTP struct {
+	host, port       string
+	rejectRecipients bool
+	messages         chan string
+}
+
+func startFakeSMTP(t *testing.T, rejectRecipients bool) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao abrir o SMTP falso: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	server := &fakeSMTP{host: host, port: port, rejectRecipients: rejectRecipients, messages: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 smtp falso")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "RCPT") && s.rejectRecipients:
+			reply("550 caixa indisponível")
+		case command == "DATA":
+			reply("354 envie a mensagem")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.messages <- data.String()
+			reply("250 ok")
+		case command == "QUIT":
+			reply("221 até mais")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// use troca o mailer padrão por um que envia para este servidor
+func (s *fakeSMTP) use(t *testing.T) {
+	port, _ := net.LookupPort("tcp", s.port)
+	mailer.SetDefault(mailer.SMTPMailer{Host: s.host, Port: port, From: "Metabee <nao-responda@metabee.test>"})
+	t.Cleanup(func() { mailer.SetDefault(nil) })
+}
+
+func newPasswordRouter(t *testing.T) (*gin.Engine, repository.Repositories) {
+	t.Helper()
+	storage, err := embedded.Open(filepath.Join(t.TempDir(), "metabee.db"))
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco local: %v", err)
+	}
+	t.Cleanup(func() { storage.Close(context.Background()) })
+
+	handlers := controller.New(storage.Repositories(), service.New(storage))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/auth/forgot-password", handlers.ForgotPassword)
+	router.POST("/auth/reset-password", handlers.ResetPassword)
+	return router, storage.Repositories()
+}
+
+func createPasswordUser(t *testing.T, repos repository.Repositories, email string) dao.UserDao {
+	t.Helper()
+	hash, err := service.HashPassword("SenhaAntiga123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := repos.Users.CreateUser(context.Background(), dao.UserDao{Name: "Ana", Email: email, Password: hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func postJSON(router *gin.Engine, path string, body any) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(body)
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(raw)))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+var resetCodePattern = regexp.MustCompile(`Código de redefinição: (\S+)`)
+
+func TestForgotPasswordSendsResetCodeBySMTP(t *testing.T) {
+	smtpServer := startFakeSMTP(t, false)
+	smtpServer.use(t)
+	router, repos := newPasswordRouter(t)
+	user := createPasswordUser(t, repos, "ana@metabee.test")
+
+	response := postJSON(router, "/auth/forgot-password", gin.H{"email": "Ana@Metabee.test"})
+	if response.Code != http.StatusOK {
+		t.Fatalf("forgot-password retornou %d: %s", response.Code, response.Body)
+	}
+
+	var message string
+	select {
+	case message = <-smtpServer.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("o SMTP falso não recebeu o email")
+	}
+	if !strings.Contains(message, "To: <ana@metabee.test>") {
+		t.Fatalf("email enviado para o destinatário errado:\n%s", message)
+	}
+	match := resetCodePattern.FindStringSubmatch(message)
+	if match == nil {
+		t.Fatalf("o email não tem o código de redefinição:\n%s", message)
+	}
+
+	reset := gin.H{"token": match[1], "new_password": "SenhaNova456"}
+	if response := postJSON(router, "/auth/reset-password", reset); response.Code != http.StatusOK {
+		t.Fatalf("reset-password retornou %d: %s", response.Code, response.Body)
+	}
+	saved, err := repos.Users.FindUserByEmail(context.Background(), user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := service.VerifyPassword("SenhaNova456", saved.Password); !ok {
+		t.Fatal("a senha não foi trocada")
+	}
+	if response := postJSON(router, "/auth/reset-password", reset); response.Code != http.StatusBadRequest {
+		t.Fatalf("o token não deve valer uma segunda vez, retornou %d", response.Code)
+	}
+}
+
+// Uma falha no envio só acontece para emails cadastrados: a resposta precisa
+// ser a mesma de um email desconhecido
+func TestForgotPasswordHidesMailFailure(t *testing.T) {
+	startFakeSMTP(t, true).use(t)
+	router, repos := newPasswordRouter(t)
+	createPasswordUser(t, repos, "ana@metabee.test")
+
+	registered := postJSON(router, "/auth/forgot-password", gin.H{"email": "ana@metabee.test"})
+	unknown := postJSON(router, "/auth/forgot-password", gin.H{"email": "ninguem@metabee.test"})
+	if registered.Code != http.StatusOK || registered.Body.String() != unknown.Body.String() {
+		t.Fatalf("respostas diferentes: cadastrado %d %s, desconhecido %d %s",
+			registered.Code, registered.Body, unknown.Code, unknown.Body)
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"log"
+	"metabee/internal/config"
 	"metabee/internal/model/dao"
 	"net/http"
 
@@ -21,7 +22,7 @@ type PurchaseResponse struct {
 }
 
 // PurchaseCourse cria uma compra de curso
-func PurchaseCourse(c *gin.Context) {
+func (ctrl *Controller) PurchaseCourse(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -29,6 +30,15 @@ func PurchaseCourse(c *gin.Context) {
 	}
 
 	user := currentUser.(dao.UserDao)
+
+	if config.Current().Auth.RequireVerifiedEmailForPurchase && !user.IsEmailVerified() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Confirme seu email antes de comprar cursos",
+			"code":  "email_not_verified",
+		})
+		return
+	}
+
 	var req PurchaseRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -43,33 +53,28 @@ func PurchaseCourse(c *gin.Context) {
 	}
 
 	// Buscar o curso para pegar o link do Drive
-	courseDao := dao.CourseDao{}
-	course, err := courseDao.FindByID(courseID)
+	course, err := ctrl.repos.Courses.FindByID(c.Request.Context(), courseID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Curso não encontrado"})
 		return
 	}
 
-	// Verificar se o usuário já comprou este curso
-	purchaseDao := dao.PurchaseDao{}
-	existingPurchase, err := purchaseDao.GetPurchaseByUserAndCourse(user.ID, courseID)
-	if err == nil && existingPurchase.ID.Hex() != "" {
-		c.JSON(http.StatusOK, PurchaseResponse{
-			PurchaseID: existingPurchase.ID.Hex(),
-			DriveLink:  existingPurchase.DriveLink,
-			Status:     existingPurchase.Status,
-			Message:    "Curso já comprado",
-		})
-		return
-	}
-
-	// Criar a compra com o link do Drive do curso
-	purchase, err := purchaseDao.CreatePurchase(user.ID, courseID, course.DriveLink)
+	// Compra, progresso e lançamento são gravados juntos; repetir o pedido retorna a compra existente
+	purchase, created, err := ctrl.services.PurchaseCourse(c.Request.Context(), user.ID, course)
 	if err != nil {
 		log.Printf("Erro ao criar compra: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar compra"})
 		return
 	}
+	if !created {
+		c.JSON(http.StatusOK, PurchaseResponse{
+			PurchaseID: purchase.ID.Hex(),
+			DriveLink:  purchase.DriveLink,
+			Status:     purchase.Status,
+			Message:    "Curso já comprado",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, PurchaseResponse{
 		PurchaseID: purchase.ID.Hex(),
@@ -80,7 +85,7 @@ func PurchaseCourse(c *gin.Context) {
 }
 
 // GetMyCourses retorna os cursos comprados pelo usuário
-func GetMyCourses(c *gin.Context) {
+func (ctrl *Controller) GetMyCourses(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -88,9 +93,8 @@ func GetMyCourses(c *gin.Context) {
 	}
 
 	user := currentUser.(dao.UserDao)
-	purchaseDao := dao.PurchaseDao{}
 
-	purchases, err := purchaseDao.GetPurchasesByUser(user.ID)
+	purchases, err := ctrl.repos.Purchases.GetPurchasesByUser(c.Request.Context(), user.ID)
 	if err != nil {
 		log.Printf("Erro ao buscar compras: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar cursos"})
@@ -98,11 +102,10 @@ func GetMyCourses(c *gin.Context) {
 	}
 
 	// Buscar detalhes dos cursos
-	courseDao := dao.CourseDao{}
 	courses := make([]gin.H, 0)
 
 	for _, purchase := range purchases {
-		course, err := courseDao.FindByID(purchase.CourseID)
+		course, err := ctrl.repos.Courses.FindByID(c.Request.Context(), purchase.CourseID)
 		if err != nil {
 			log.Printf("Erro ao buscar curso %s: %v", purchase.CourseID.Hex(), err)
 			continue
@@ -129,7 +132,7 @@ func GetMyCourses(c *gin.Context) {
 }
 
 // UpdateDownloadStatus atualiza o status do download
-func UpdateDownloadStatus(c *gin.Context) {
+func (ctrl *Controller) UpdateDownloadStatus(c *gin.Context) {
 	_, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -154,8 +157,7 @@ func UpdateDownloadStatus(c *gin.Context) {
 		return
 	}
 
-	purchaseDao := dao.PurchaseDao{}
-	err = purchaseDao.UpdatePurchaseStatus(purchaseID, req.Status, req.LocalPath)
+	err = ctrl.repos.Purchases.UpdatePurchaseStatus(c.Request.Context(), purchaseID, req.Status, req.LocalPath)
 	if err != nil {
 		log.Printf("Erro ao atualizar status: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar status"})
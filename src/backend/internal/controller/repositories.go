@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"metabee/internal/repository"
+	"metabee/internal/service"
+)
+
+// Controller atende as rotas usando os repositórios e serviços recebidos em New
+type Controller struct {
+	repos    repository.Repositories
+	services *service.Service
+}
+
+// New cria os controllers; SetupMainRouter passa o backend selecionado
+func New(repos repository.Repositories, services *service.Service) *Controller {
+	return &Controller{repos: repos, services: services}
+}
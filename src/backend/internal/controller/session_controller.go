@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions lista os dispositivos com sessão ativa do usuário autenticado
+func (ctrl *Controller) GetSessions(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+	tokenClaims, _ := c.Get("tokenClaims")
+	claims, ok := tokenClaims.(*service.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	sessions, err := ctrl.services.ListSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("❌ Erro ao listar sessões de %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar sessões"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, gin.H{
+			"id":           session.ID.Hex(),
+			"device_name":  session.DeviceName,
+			"ip":           session.IP,
+			"user_agent":   session.UserAgent,
+			"created_at":   session.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			"last_seen_at": session.LastSeenAt.Format("2006-01-02T15:04:05Z07:00"),
+			"current":      session.ID.Hex() == claims.SessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": result})
+}
+
+// DeleteSession encerra uma sessão do usuário autenticado
+func (ctrl *Controller) DeleteSession(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	sessionID := c.Param("id")
+
+	if err := ctrl.services.EndSession(c.Request.Context(), user.ID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao encerrar sessão %s de %s: %v", sessionID, user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessão"})
+		return
+	}
+
+	log.Printf("✅ Sessão encerrada pelo usuário - UserID: %s, Sessão: %s", user.ID.Hex(), sessionID)
+	c.JSON(http.StatusOK, gin.H{"message": "Sessão encerrada com sucesso"})
+}
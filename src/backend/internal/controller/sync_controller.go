@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/model/dto"
+	"metabee/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type syncRunRequest struct {
+	CentralToken string `json:"central_token"` // Access token do usuário no servidor central
+}
+
+// GetSyncChanges lista os registros do usuário alterados depois de ?since (seq)
+func (ctrl *Controller) GetSyncChanges(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+	user := currentUser.(dao.UserDao)
+
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since deve ser um número inteiro não negativo"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit deve ser um número inteiro"})
+		return
+	}
+
+	changes, err := ctrl.services.PullChanges(c.Request.Context(), user.ID, since, limit)
+	if err != nil {
+		log.Printf("❌ Erro ao listar alterações de %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao listar alterações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// PushSyncChanges grava as sessões de estudo, o progresso e as notas registrados offline
+func (ctrl *Controller) PushSyncChanges(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+	user := currentUser.(dao.UserDao)
+
+	var batch dto.SyncBatch
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+		return
+	}
+
+	result, err := ctrl.services.PushChanges(c.Request.Context(), user.ID, batch)
+	if err != nil {
+		if errors.Is(err, service.ErrSyncRecordInvalid) || errors.Is(err, service.ErrSyncBatchTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao gravar alterações de %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gravar alterações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RunSync sincroniza esta instância (app desktop) com o servidor central
+func (ctrl *Controller) RunSync(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+	user := currentUser.(dao.UserDao)
+
+	var input syncRunRequest
+	if err := c.ShouldBindJSON(&input); err != nil || input.CentralToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "central_token é obrigatório"})
+		return
+	}
+
+	result, err := ctrl.services.RunCentralSync(c.Request.Context(), user.ID, input.CentralToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSyncNotConfigured):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "sync_not_configured"})
+		case errors.Is(err, service.ErrSyncCentralUnauthorized):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "central_unauthorized"})
+		case errors.Is(err, service.ErrSyncCentralUnavailable):
+			log.Printf("❌ Falha na sincronização de %s com o servidor central: %v", user.Email, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "code": "central_unavailable"})
+		default:
+			log.Printf("❌ Erro na sincronização de %s: %v", user.Email, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro na sincronização"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,19 +1,20 @@
 package controller
 
 import (
+	"fmt"
 	"log"
+	"metabee/internal/config"
 	"metabee/internal/adapter"
 	"metabee/internal/model/dao"
 	"metabee/internal/model/dto"
 	"metabee/internal/service"
-	"metabee/internal/util"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-func Register(c *gin.Context) {
+func (ctrl *Controller) Register(c *gin.Context) {
 	var input dto.User
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -26,13 +27,18 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	if err := service.ValidatePassword(input.Password); err != nil {
+		respondPasswordPolicy(c, err, "password")
+		return
+	}
+
 	var err error
 	user := adapter.UserAdapter{}.DtoToDao(input)
 	
 	log.Printf("🔐 Registrando novo usuário: %s", input.Email)
 	log.Printf("📝 Senha original tem %d caracteres", len(user.Password))
 	
-	user.Password, err = util.HashPassword(user.Password)
+	user.Password, err = service.HashPassword(user.Password)
 	if err != nil {
 		log.Printf("❌ Erro ao hashear a senha: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao hashear a senha"})
@@ -41,8 +47,7 @@ func Register(c *gin.Context) {
 	
 	log.Printf("✅ Senha hasheada com sucesso (hash tem %d caracteres)", len(user.Password))
 
-	var userDao dao.UserDao
-	user, err = userDao.CreateUser(user)
+	user, err = ctrl.repos.Users.CreateUser(c.Request.Context(), user)
 	if err != nil {
 		log.Printf("❌ Erro ao criar usuário: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -51,7 +56,12 @@ func Register(c *gin.Context) {
 
 	log.Printf("✅ Usuário criado com sucesso: %s (ID: %s)", user.Email, user.ID.Hex())
 
-	token, err := service.GenerateJWT(user.ID.Hex())
+	// Falha no envio não impede o cadastro; o usuário pode pedir o reenvio depois
+	if err := ctrl.services.SendEmailVerification(c.Request.Context(), user, user.Email); err != nil {
+		log.Printf("⚠️  Erro ao enviar email de verificação para %s: %v", user.Email, err)
+	}
+
+	tokens, err := ctrl.services.IssueTokenPair(c.Request.Context(), user, sessionInfo(c))
 	if err != nil {
 		log.Println("❌ Erro ao gerar token:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno"})
@@ -59,10 +69,10 @@ func Register(c *gin.Context) {
 	}
 
 	log.Printf("✅ Token gerado com sucesso para usuário: %s", user.Email)
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, newLoginOutput(tokens))
 }
 
-func ValidateToken(c *gin.Context) {
+func (ctrl *Controller) ValidateToken(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
@@ -76,7 +86,7 @@ func ValidateToken(c *gin.Context) {
 }
 
 // GetProfile retorna os dados do perfil do usuário autenticado
-func GetProfile(c *gin.Context) {
+func (ctrl *Controller) GetProfile(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -85,8 +95,7 @@ func GetProfile(c *gin.Context) {
 
 	user := currentUser.(dao.UserDao)
 	
-	var userDao dao.UserDao
-	profile, err := userDao.GetUserProfile(user.ID)
+	profile, err := ctrl.repos.Users.GetUserProfile(c.Request.Context(), user.ID)
 	if err != nil {
 		log.Printf("❌ Erro ao buscar perfil: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar perfil"})
@@ -94,7 +103,7 @@ func GetProfile(c *gin.Context) {
 	}
 
 	// Verificar se tem avatar usando método auxiliar
-	hasAvatar := userDao.HasAvatar(user.ID)
+	hasAvatar := ctrl.repos.Users.HasAvatar(c.Request.Context(), user.ID)
 
 	// Retornar dados do perfil em snake_case
 	c.JSON(http.StatusOK, gin.H{
@@ -106,11 +115,14 @@ func GetProfile(c *gin.Context) {
 		"created_at": profile.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"updated_at": profile.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"has_avatar": hasAvatar,
+		"email_verified": profile.IsEmailVerified(),
+		"role":           profile.EffectiveRole(),
+		"mfa_enabled":    profile.MFAEnabled,
 	})
 }
 
 // GetProfileImage retorna a imagem de perfil do usuário
-func GetProfileImage(c *gin.Context) {
+func (ctrl *Controller) GetProfileImage(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -119,8 +131,7 @@ func GetProfileImage(c *gin.Context) {
 
 	user := currentUser.(dao.UserDao)
 	
-	var userDao dao.UserDao
-	avatar, mimeType, err := userDao.GetUserAvatar(user.ID)
+	avatar, mimeType, err := ctrl.repos.Users.GetUserAvatar(c.Request.Context(), user.ID)
 	if err != nil {
 		log.Printf("❌ Erro ao buscar avatar: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar avatar"})
@@ -145,7 +156,7 @@ func GetProfileImage(c *gin.Context) {
 }
 
 // UpdateProfile atualiza os dados do perfil do usuário
-func UpdateProfile(c *gin.Context) {
+func (ctrl *Controller) UpdateProfile(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -182,8 +193,7 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	var userDao dao.UserDao
-	err := userDao.UpdateUserProfile(user.ID, updates)
+	err := ctrl.repos.Users.UpdateUserProfile(c.Request.Context(), user.ID, updates)
 	if err != nil {
 		log.Printf("Erro ao atualizar perfil: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar perfil"})
@@ -195,7 +205,7 @@ func UpdateProfile(c *gin.Context) {
 }
 
 // UpdateProfileImage atualiza a foto de perfil do usuário
-func UpdateProfileImage(c *gin.Context) {
+func (ctrl *Controller) UpdateProfileImage(c *gin.Context) {
 	currentUser, exists := c.Get("currentUser")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
@@ -211,9 +221,10 @@ func UpdateProfileImage(c *gin.Context) {
 		return
 	}
 
-	// Validar tamanho (máximo 5MB)
-	if file.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo muito grande. Máximo 5MB"})
+	// Validar tamanho ([uploads].maxAvatarMB)
+	maxAvatarMB := config.Current().Uploads.MaxAvatarMB
+	if file.Size > int64(maxAvatarMB)*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Arquivo muito grande. Máximo %dMB", maxAvatarMB)})
 		return
 	}
 
@@ -269,8 +280,7 @@ func UpdateProfileImage(c *gin.Context) {
 		"avatar_mime_type": contentType,
 	}
 
-	var userDao dao.UserDao
-	err = userDao.UpdateUserProfile(user.ID, updates)
+	err = ctrl.repos.Users.UpdateUserProfile(c.Request.Context(), user.ID, updates)
 	if err != nil {
 		log.Printf("Erro ao atualizar foto de perfil: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar foto de perfil"})
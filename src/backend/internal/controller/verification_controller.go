@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmail confirma o email a partir do link enviado na criação da conta ou na troca de email
+func (ctrl *Controller) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token de verificação não fornecido"})
+		return
+	}
+
+	userID, err := ctrl.services.VerifyEmail(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailVerificationInvalid) || errors.Is(err, service.ErrEmailVerificationExpired) ||
+			errors.Is(err, service.ErrEmailInUse) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Erro ao verificar email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verificado com sucesso",
+		"user_id": userID.Hex(),
+	})
+}
+
+// ResendVerification reenvia o link de confirmação para o usuário autenticado
+func (ctrl *Controller) ResendVerification(c *gin.Context) {
+	currentUser, exists := c.Get("currentUser")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	user := currentUser.(dao.UserDao)
+	if user.IsEmailVerified() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email já verificado"})
+		return
+	}
+
+	if err := ctrl.services.SendEmailVerification(c.Request.Context(), user, user.Email); err != nil {
+		log.Printf("❌ Erro ao reenviar verificação para %s: %v", user.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao enviar email de verificação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email de verificação enviado"})
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"log"
 	"metabee/internal/config"
+	"sync"
 	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
@@ -14,14 +16,16 @@ var MongoClient *mongo.Client
 var DB *mongo.Database
 
 func ConnectMongoDB() {
-	mongoURI := config.Env.Database.MongoURI
+	mongoURI := config.Current().Database.MongoURI
 	if mongoURI == "" {
 		log.Fatal("MongoDB URI não configurada. Verifique o arquivo metabee.conf")
 	}
 
-	clientOptions := options.Client().ApplyURI(mongoURI)
+	clientOptions := options.Client().ApplyURI(mongoURI).SetConnectTimeout(
+		time.Duration(config.Current().Database.ConnectTimeoutSeconds) * time.Second)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	connectTimeout := time.Duration(config.Current().Database.ConnectTimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
 	defer cancel()
 
 	client, err := mongo.Connect(clientOptions)
@@ -35,7 +39,7 @@ func ConnectMongoDB() {
 	}
 
 	MongoClient = client
-	dbName := config.Env.Database.DBName
+	dbName := config.Current().Database.DBName
 	if dbName == "" {
 		dbName = "metabee_db"
 	}
@@ -43,3 +47,62 @@ func ConnectMongoDB() {
 
 	log.Println("✅ Conectado ao MongoDB com sucesso")
 }
+
+// DisconnectMongoDB encerra as conexões com o MongoDB, esperando as operações em andamento até o fim de ctx
+func DisconnectMongoDB(ctx context.Context) error {
+	if MongoClient == nil {
+		return nil
+	}
+	if err := MongoClient.Disconnect(ctx); err != nil {
+		return err
+	}
+
+	log.Println("✅ Conexão com o MongoDB encerrada")
+	return nil
+}
+
+// QueryTimeout retorna o limite de cada operação no banco ([database].queryTimeoutSeconds)
+func QueryTimeout() time.Duration {
+	if config.Current().Database.QueryTimeoutSeconds > 0 {
+		return time.Duration(config.Current().Database.QueryTimeoutSeconds) * time.Second
+	}
+	return 5 * time.Second
+}
+
+// WithQueryTimeout deriva de ctx (normalmente o contexto da requisição) o limite de uma operação no banco.
+// Vale o que acontecer primeiro: o cliente desconectar ou o limite configurado expirar.
+func WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, QueryTimeout())
+}
+
+var (
+	transactionsMu      sync.Mutex
+	transactionsChecked bool
+	transactionsEnabled bool
+)
+
+// SupportsTransactions informa se o servidor aceita transações (replica set ou
+// mongos). A resposta é guardada depois da primeira consulta bem-sucedida.
+func SupportsTransactions(ctx context.Context) (bool, error) {
+	transactionsMu.Lock()
+	defer transactionsMu.Unlock()
+
+	if transactionsChecked {
+		return transactionsEnabled, nil
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := MongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+
+	transactionsChecked = true
+	transactionsEnabled = hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !transactionsEnabled {
+		log.Println("⚠️ MongoDB sem replica set: operações em várias collections rodam sem transação (os índices únicos evitam duplicidade)")
+	}
+	return transactionsEnabled, nil
+}
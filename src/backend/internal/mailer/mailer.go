@@ -0,0 +1,111 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"metabee/internal/config"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Message é um email de texto simples.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envia emails transacionais (redefinição de senha, verificação etc.).
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPMailer envia emails por um servidor SMTP. Sem usuário configurado a
+// conexão é feita sem autenticação, o que permite testar contra um servidor
+// SMTP falso local (ex.: MailHog em localhost:1025).
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m SMTPMailer) Send(msg Message) error {
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("remetente inválido: %v", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("destinatário inválido: %v", err)
+	}
+
+	return smtp.SendMail(addr, auth, from.Address, []string{to.Address}, buildMessage(from.String(), to.String(), msg))
+}
+
+// LogMailer apenas registra o email no log. Usado quando o SMTP não está configurado.
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("📧 [LogMailer] Para: %s | Assunto: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+var current Mailer
+
+// Default retorna o mailer configurado em metabee.conf
+func Default() Mailer {
+	if current != nil {
+		return current
+	}
+
+	mailConf := config.Current().Mail
+	if mailConf.SMTPHost == "" {
+		return LogMailer{}
+	}
+
+	port := mailConf.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	return SMTPMailer{
+		Host:     mailConf.SMTPHost,
+		Port:     port,
+		Username: mailConf.SMTPUsername,
+		Password: mailConf.SMTPPassword,
+		From:     mailConf.From,
+	}
+}
+
+// SetDefault substitui o mailer padrão (útil em testes)
+func SetDefault(m Mailer) {
+	current = m
+}
+
+func buildMessage(from, to string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"metabee/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AllowAPIKey habilita chaves de API com o escopo informado na rota. Deve vir
+// antes do AuthMiddleware; rotas sem ele recusam chaves de API.
+func AllowAPIKey(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("apiKeyScope", scope)
+		c.Next()
+	}
+}
+
+// apiKeyFromRequest extrai a chave de API do header X-API-Key ou do Authorization: Bearer mbk_...
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader("X-API-Key")); key != "" {
+		return key
+	}
+
+	bearer := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if service.IsAPIKey(bearer) {
+		return bearer
+	}
+	return ""
+}
+
+func authenticateAPIKey(c *gin.Context, services *service.Service, rawKey string) {
+	scope, allowed := c.Get("apiKeyScope")
+	if !allowed {
+		log.Printf("❌ AuthMiddleware: Chave de API usada em rota sem suporte - Rota: %s", c.FullPath())
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Chaves de API não são aceitas nesta rota"})
+		return
+	}
+
+	key, user, err := services.AuthenticateAPIKey(c.Request.Context(), rawKey, scope.(string), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyScopeMissing) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error(), "required_scope": scope})
+			return
+		}
+		log.Printf("❌ AuthMiddleware: Chave de API recusada - %v", err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Chave de API inválida"})
+		return
+	}
+
+	log.Printf("✅ AuthMiddleware: Chave de API %s autenticada - UserID: %s", key.Prefix, user.ID.Hex())
+	c.Set("currentUser", user)
+	c.Set("userID", user.ID.Hex())
+	c.Set("apiKey", key)
+	c.Next()
+}
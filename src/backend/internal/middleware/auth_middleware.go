@@ -2,7 +2,7 @@ package middleware
 
 import (
 	"log"
-	"metabee/internal/model/dao"
+	"metabee/internal/repository"
 	"metabee/internal/service"
 	"net/http"
 	"strings"
@@ -10,42 +10,65 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(c *gin.Context) {
+// AuthMiddleware autentica a requisição pelo access token (ou pela chave de API,
+// nas rotas com AllowAPIKey) e carrega o usuário de users.
+func AuthMiddleware(users repository.UserRepository, services *service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey := apiKeyFromRequest(c); apiKey != "" {
+			authenticateAPIKey(c, services, apiKey)
+			return
+		}
 
-	authHeader := c.GetHeader("Authorization")
-	if !strings.HasPrefix(authHeader, "Bearer ") {
-		log.Printf("❌ AuthMiddleware: Token não fornecido ou formato inválido")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token não fornecido"})
-		return
-	}
-	
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	log.Printf("🔍 AuthMiddleware: Token recebido (length: %d)", len(token))
-	
-	userId, err := service.ValidateJWT(token)
-	if err != nil {
-		log.Printf("❌ AuthMiddleware: Erro ao validar token - %v", err)
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
-		return
-	}
-	
-	log.Printf("✅ AuthMiddleware: Token válido - UserID: %s", userId)
-	
-	userDao := dao.UserDao{}
-	loggedInUser, err := userDao.FindUserByID(userId)
-	if err != nil {
-		log.Printf("❌ AuthMiddleware: Erro ao buscar usuário - UserID: %s - Erro: %v", userId, err)
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Erro ao verificar usuário"})
-		return
-	}
-	
-	if loggedInUser.Email == "" {
-		log.Printf("❌ AuthMiddleware: Usuário encontrado mas sem email - UserID: %s", userId)
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Usuário não encontrado"})
-		return
-	}
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			log.Printf("❌ AuthMiddleware: Token não fornecido ou formato inválido")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token não fornecido"})
+			return
+		}
+		
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		log.Printf("🔍 AuthMiddleware: Token recebido (length: %d)", len(token))
+		
+		claims, err := service.ParseJWT(token)
+		if err != nil {
+			log.Printf("❌ AuthMiddleware: Erro ao validar token - %v", err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+			return
+		}
+		userId := claims.UserID
+		
+		log.Printf("✅ AuthMiddleware: Token válido - UserID: %s", userId)
+		
+		loggedInUser, err := users.FindUserByID(c.Request.Context(), userId)
+		if err != nil {
+			log.Printf("❌ AuthMiddleware: Erro ao buscar usuário - UserID: %s - Erro: %v", userId, err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Erro ao verificar usuário"})
+			return
+		}
+		
+		if loggedInUser.Email == "" {
+			log.Printf("❌ AuthMiddleware: Usuário encontrado mas sem email - UserID: %s", userId)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Usuário não encontrado"})
+			return
+		}
+
+		revoked, err := services.IsTokenRevoked(c.Request.Context(), claims, loggedInUser)
+		if err != nil {
+			log.Printf("❌ AuthMiddleware: Erro ao verificar revogação do token - UserID: %s - Erro: %v", userId, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar token"})
+			return
+		}
+		if revoked {
+			log.Printf("❌ AuthMiddleware: Token revogado - UserID: %s", userId)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token revogado"})
+			return
+		}
 
-	log.Printf("✅ AuthMiddleware: Usuário autenticado com sucesso - Email: %s", loggedInUser.Email)
-	c.Set("currentUser", loggedInUser)
-	c.Next()
+		log.Printf("✅ AuthMiddleware: Usuário autenticado com sucesso - Email: %s", loggedInUser.Email)
+		services.TouchSession(c.Request.Context(), claims, c.ClientIP())
+		c.Set("currentUser", loggedInUser)
+		c.Set("userID", userId)
+		c.Set("tokenClaims", claims)
+		c.Next()
+	}
 }
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole restringe a rota aos papéis informados. Deve ser usado depois do
+// AuthMiddleware; o papel é lido do usuário carregado do banco, e não do token,
+// para que uma mudança de papel tenha efeito imediato.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		currentUser, exists := c.Get("currentUser")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+			return
+		}
+
+		user := currentUser.(dao.UserDao)
+		if !allowed[user.EffectiveRole()] {
+			log.Printf("❌ RequireRole: Acesso negado - UserID: %s, Papel: %s, Rota: %s", user.ID.Hex(), user.EffectiveRole(), c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+			return
+		}
+
+		if service.MFAEnrollmentRequired(user) {
+			log.Printf("❌ RequireRole: 2FA obrigatório não cadastrado - UserID: %s, Rota: %s", user.ID.Hex(), c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Ative a verificação em duas etapas para acessar esta área",
+				"code":  "mfa_enrollment_required",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
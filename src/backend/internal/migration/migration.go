@@ -0,0 +1,113 @@
+// Package migration aplica as alterações versionadas do banco: índices e
+// correções de dados. Cada versão aplicada é registrada na collection
+// schema_migrations e não roda de novo.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Migration é uma alteração do banco. Up precisa ser idempotente: duas
+// instâncias iniciando juntas podem executar a mesma versão.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record é o registro de uma migração aplicada
+type Record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+	DurationMs  int64     `bson:"duration_ms"`
+}
+
+const collectionName = "schema_migrations"
+
+// All retorna todas as migrações conhecidas, em ordem de versão
+func All() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// Applied retorna as migrações já registradas no banco, por versão
+func Applied(ctx context.Context) (map[int]Record, error) {
+	collection := database.DB.Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Pending retorna as migrações ainda não aplicadas, em ordem de versão
+func Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := Applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", collectionName, err)
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if _, done := applied[migration.Version]; !done {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Run aplica as migrações pendentes em ordem e para na primeira que falhar.
+// Retorna as que foram aplicadas nesta execução.
+func Run(ctx context.Context) ([]Migration, error) {
+	pending, err := Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := database.DB.Collection(collectionName)
+	var applied []Migration
+	for _, migration := range pending {
+		log.Printf("🗄️  Aplicando migração %d: %s", migration.Version, migration.Description)
+		start := time.Now()
+
+		if err := migration.Up(ctx, database.DB); err != nil {
+			return applied, fmt.Errorf("migração %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		record := Record{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+			DurationMs:  time.Since(start).Milliseconds(),
+		}
+		// Outra instância pode ter registrado a mesma versão enquanto esta executava
+		if _, err := collection.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return applied, fmt.Errorf("migração %d aplicada, mas não registrada: %w", migration.Version, err)
+		}
+
+		log.Printf("✅ Migração %d aplicada em %s", migration.Version, time.Since(start).Round(time.Millisecond))
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
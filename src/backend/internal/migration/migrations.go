@@ -0,0 +1,223 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"metabee/internal/model/dao"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// migrations em ordem de versão. Uma versão publicada não deve ser alterada:
+// correções entram como uma nova versão no fim da lista.
+var migrations = []Migration{
+	{Version: 1, Description: "índices das collections de autenticação (únicos e TTL)", Up: authIndexes},
+	{Version: 2, Description: "normaliza emails de usuários para minúsculas", Up: normalizeUserEmails},
+	{Version: 3, Description: "índices únicos de email e conta Google em user", Up: userIndexes},
+	{Version: 4, Description: "índices de compras, progresso e sessões de estudo", Up: learningIndexes},
+	{Version: 5, Description: "índices de ordenação de cursos e notícias e de busca de imagens", Up: catalogIndexes},
+	{Version: 6, Description: "índices de sincronização (seq) de sessões, progresso e notas", Up: syncIndexes},
+	{Version: 7, Description: "índices do livro de lançamentos (ledger)", Up: ledgerIndexes},
+}
+
+// authIndexes cria os índices que antes eram criados a cada inicialização
+func authIndexes(ctx context.Context, db *mongo.Database) error {
+	steps := []struct {
+		name   string
+		ensure func(ctx context.Context, db *mongo.Database) error
+	}{
+		{"refresh tokens", dao.RefreshTokenDao{}.EnsureIndexes},
+		{"lista de revogação", dao.RevokedTokenDao{}.EnsureIndexes},
+		{"redefinição de senha", dao.PasswordResetDao{}.EnsureIndexes},
+		{"verificação de email", dao.EmailVerificationDao{}.EnsureIndexes},
+		{"tentativas de login", dao.LoginAttemptDao{}.EnsureIndexes},
+		{"sessões", dao.SessionDao{}.EnsureIndexes},
+		{"login OIDC", dao.OIDCStateDao{}.EnsureIndexes},
+		{"chaves de API", dao.APIKeyDao{}.EnsureIndexes},
+		{"auditoria", dao.AuditLogDao{}.EnsureIndexes},
+	}
+
+	for _, step := range steps {
+		if err := step.ensure(ctx, db); err != nil {
+			return fmt.Errorf("índices de %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// normalizeUserEmails grava os emails em minúsculas e sem espaços, para que a
+// busca por email use o índice único em vez de regex. Contas que colidem após
+// a normalização precisam ser resolvidas manualmente antes.
+func normalizeUserEmails(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("user")
+	normalized := bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$email"}}}
+
+	cursor, err := users.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"email": bson.M{"$type": "string"}}},
+		bson.M{"$group": bson.M{"_id": normalized, "count": bson.M{"$sum": 1}}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var duplicates []struct {
+		Email string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		emails := make([]string, 0, len(duplicates))
+		for _, duplicate := range duplicates {
+			emails = append(emails, fmt.Sprintf("%s (%d contas)", duplicate.Email, duplicate.Count))
+		}
+		return fmt.Errorf("emails que só diferem em maiúsculas/espaços: %s", strings.Join(emails, ", "))
+	}
+
+	result, err := users.UpdateMany(ctx,
+		bson.M{"email": bson.M{"$type": "string"}},
+		bson.A{bson.M{"$set": bson.M{"email": normalized}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("   %d email(s) normalizado(s)", result.ModifiedCount)
+	return nil
+}
+
+func userIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db, "user",
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Apenas contas vinculadas ao Google têm google_sub
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "google_sub", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"google_sub": bson.M{"$type": "string"}}),
+		},
+	)
+}
+
+func learningIndexes(ctx context.Context, db *mongo.Database) error {
+	// Uma compra e um registro de progresso por usuário/curso; o prefixo user_id atende as listagens do usuário
+	userCourse := bson.D{{Key: "user_id", Value: 1}, {Key: "course_id", Value: 1}}
+
+	for _, collection := range []string{"purchase", "user_progress"} {
+		if err := checkUserCourseDuplicates(ctx, db, collection); err != nil {
+			return err
+		}
+	}
+
+	if err := createIndexes(ctx, db, "purchase",
+		mongo.IndexModel{Keys: userCourse, Options: options.Index().SetUnique(true)},
+	); err != nil {
+		return err
+	}
+	if err := createIndexes(ctx, db, "user_progress",
+		mongo.IndexModel{Keys: userCourse, Options: options.Index().SetUnique(true)},
+	); err != nil {
+		return err
+	}
+	return createIndexes(ctx, db, "study_session",
+		mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "date", Value: -1}}},
+	)
+}
+
+// checkUserCourseDuplicates falha listando os pares usuário/curso com mais de um
+// registro, que impediriam o índice único. Quais registros manter (compras têm
+// valor e pagamento associados) precisa ser decidido manualmente antes.
+func checkUserCourseDuplicates(ctx context.Context, db *mongo.Database, collection string) error {
+	cursor, err := db.Collection(collection).Aggregate(ctx, bson.A{
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"user_id": "$user_id", "course_id": "$course_id"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var duplicates []struct {
+		Key struct {
+			UserID   bson.ObjectID `bson:"user_id"`
+			CourseID bson.ObjectID `bson:"course_id"`
+		} `bson:"_id"`
+		IDs []bson.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		ids := make([]string, 0, len(duplicate.IDs))
+		for _, id := range duplicate.IDs {
+			ids = append(ids, id.Hex())
+		}
+		pairs = append(pairs, fmt.Sprintf("usuário %s, curso %s (%s)",
+			duplicate.Key.UserID.Hex(), duplicate.Key.CourseID.Hex(), strings.Join(ids, ", ")))
+	}
+	return fmt.Errorf("%s com mais de um registro por usuário/curso: %s", collection, strings.Join(pairs, "; "))
+}
+
+func catalogIndexes(ctx context.Context, db *mongo.Database) error {
+	if err := createIndexes(ctx, db, "courses",
+		mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	); err != nil {
+		return err
+	}
+	if err := createIndexes(ctx, db, "news",
+		mongo.IndexModel{Keys: bson.D{{Key: "date", Value: -1}}},
+	); err != nil {
+		return err
+	}
+	return createIndexes(ctx, db, "images",
+		mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}},
+	)
+}
+
+// syncIndexes atende o /metabee/sync/changes, que lista os registros do usuário por seq
+func syncIndexes(ctx context.Context, db *mongo.Database) error {
+	userSeq := bson.D{{Key: "user_id", Value: 1}, {Key: "seq", Value: 1}}
+
+	for _, collection := range []string{"study_session", "user_progress", "note"} {
+		if err := createIndexes(ctx, db, collection, mongo.IndexModel{Keys: userSeq}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ledgerIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db, "ledger",
+		// Um lançamento de cada tipo por compra: repetir a compra não duplica o lançamento
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "purchase_id", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	)
+}
+
+func createIndexes(ctx context.Context, db *mongo.Database, collection string, models ...mongo.IndexModel) error {
+	if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("índices de %s: %w", collection, err)
+	}
+	return nil
+}
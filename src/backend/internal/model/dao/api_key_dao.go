@@ -0,0 +1,191 @@
+package dao
+
+import (
+	"context"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// APIKeyDao é uma chave de API pessoal usada por scripts e integrações. Apenas o
+// hash é persistido; o prefixo permite identificar a chave sem revelá-la.
+type APIKeyDao struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	UserID     bson.ObjectID `bson:"user_id"`
+	Name       string        `bson:"name"`
+	Prefix     string        `bson:"prefix"` // Ex.: mbk_1a2b3c4d
+	KeyHash    string        `bson:"key_hash"`
+	Scopes     []string      `bson:"scopes"`
+	ExpiresAt  time.Time     `bson:"expires_at"`
+	LastUsedAt time.Time     `bson:"last_used_at,omitempty"`
+	LastUsedIP string        `bson:"last_used_ip,omitempty"`
+	RevokedAt  time.Time     `bson:"revoked_at,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at"`
+}
+
+// Escopos que podem ser concedidos a uma chave de API. Cada escopo corresponde
+// a rotas marcadas com AllowAPIKey; escrita de notícias e relatórios ainda não
+// têm rotas, então não há escopo para eles.
+const (
+	ScopeCoursesWrite = "courses:write"
+)
+
+const apiKeyCollectionName = "api_key"
+
+// HasScope indica se a chave concede o escopo informado
+func (dao APIKeyDao) HasScope(scope string) bool {
+	for _, granted := range dao.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureIndexes cria o índice único de hash e o índice de listagem por usuário
+func (dao APIKeyDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(apiKeyCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// CreateAPIKey persiste uma nova chave
+func (dao APIKeyDao) CreateAPIKey(ctx context.Context, key APIKeyDao) (APIKeyDao, error) {
+	key.ID = bson.NewObjectID()
+	key.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(apiKeyCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, key)
+	if err != nil {
+		return APIKeyDao{}, err
+	}
+
+	return key, nil
+}
+
+// FindByHash busca uma chave pelo hash
+func (dao APIKeyDao) FindByHash(ctx context.Context, keyHash string) (APIKeyDao, error) {
+	collection := database.DB.Collection(apiKeyCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var key APIKeyDao
+	err := collection.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&key)
+	if err != nil {
+		return APIKeyDao{}, err
+	}
+
+	return key, nil
+}
+
+// FindByUser lista as chaves não revogadas do usuário, da mais recente para a mais antiga
+func (dao APIKeyDao) FindByUser(ctx context.Context, userID bson.ObjectID) ([]APIKeyDao, error) {
+	collection := database.DB.Collection(apiKeyCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.M{"key_hash": 0})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []APIKeyDao{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke revoga a chave do usuário; retorna false se ela não existir ou já estiver revogada
+func (dao APIKeyDao) Revoke(ctx context.Context, keyID, userID bson.ObjectID) (bool, error) {
+	collection := database.DB.Collection(apiKeyCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":        keyID,
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// RevokeAllForUser revoga todas as chaves ativas do usuário
+func (dao APIKeyDao) RevokeAllForUser(ctx context.Context, userID bson.ObjectID) error {
+	collection := database.DB.Collection(apiKeyCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// TouchIfIdle registra o último uso apenas se ele for anterior a idleSince,
+// evitando uma escrita por requisição
+func (dao APIKeyDao) TouchIfIdle(ctx context.Context, keyID bson.ObjectID, ip string, idleSince time.Time) error {
+	collection := database.DB.Collection(apiKeyCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id": keyID,
+		"$or": bson.A{
+			bson.M{"last_used_at": bson.M{"$lt": idleSince}},
+			bson.M{"last_used_at": bson.M{"$exists": false}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"last_used_at": time.Now(), "last_used_ip": ip},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
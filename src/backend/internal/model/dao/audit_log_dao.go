@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"context"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// AuditLogDao registra alterações sensíveis na conta (senha, email etc.).
+// Os registros não são alterados nem removidos pela aplicação.
+type AuditLogDao struct {
+	ID        bson.ObjectID     `bson:"_id,omitempty"`
+	UserID    bson.ObjectID     `bson:"user_id"`
+	Action    string            `bson:"action"`
+	IP        string            `bson:"ip,omitempty"`
+	UserAgent string            `bson:"user_agent,omitempty"`
+	Details   map[string]string `bson:"details,omitempty"`
+	CreatedAt time.Time         `bson:"created_at"`
+}
+
+// Ações registradas no audit trail
+const (
+	AuditPasswordChanged      = "password_changed"
+	AuditPasswordReset        = "password_reset"
+	AuditEmailChangeRequested = "email_change_requested"
+	AuditEmailChanged         = "email_changed"
+	AuditGoogleLinked         = "google_linked"
+)
+
+const auditLogCollectionName = "audit_log"
+
+// EnsureIndexes cria o índice usado para listar o histórico de um usuário
+func (dao AuditLogDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(auditLogCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// CreateAuditLog persiste um novo registro
+func (dao AuditLogDao) CreateAuditLog(ctx context.Context, entry AuditLogDao) error {
+	entry.ID = bson.NewObjectID()
+	entry.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(auditLogCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, entry)
+	return err
+}
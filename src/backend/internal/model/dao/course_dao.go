@@ -75,10 +75,10 @@ func (c CourseDao) MarshalJSON() ([]byte, error) {
 	})
 }
 
-func (dao CourseDao) GetLastTwoCourses() ([]CourseDao, error) {
+func (dao CourseDao) GetLastTwoCourses(ctx context.Context) ([]CourseDao, error) {
 	collection := database.DB.Collection(courseCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(2)
@@ -96,13 +96,13 @@ func (dao CourseDao) GetLastTwoCourses() ([]CourseDao, error) {
 	return courses, nil
 }
 
-func (dao CourseDao) CreateCourse(course CourseDao) (CourseDao, error) {
+func (dao CourseDao) CreateCourse(ctx context.Context, course CourseDao) (CourseDao, error) {
 	course.ID = bson.NewObjectID()
 	course.CreatedAt = time.Now()
 	course.UpdatedAt = time.Now()
 
 	collection := database.DB.Collection(courseCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	_, err := collection.InsertOne(ctx, course)
@@ -113,10 +113,10 @@ func (dao CourseDao) CreateCourse(course CourseDao) (CourseDao, error) {
 	return course, nil
 }
 
-func (dao CourseDao) FindByID(courseID bson.ObjectID) (CourseDao, error) {
+func (dao CourseDao) FindByID(ctx context.Context, courseID bson.ObjectID) (CourseDao, error) {
 	collection := database.DB.Collection(courseCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	var course CourseDao
@@ -128,10 +128,10 @@ func (dao CourseDao) FindByID(courseID bson.ObjectID) (CourseDao, error) {
 	return course, nil
 }
 
-func (dao CourseDao) GetAllCourses() ([]CourseDao, error) {
+func (dao CourseDao) GetAllCourses(ctx context.Context) ([]CourseDao, error) {
 	collection := database.DB.Collection(courseCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	cursor, err := collection.Find(ctx, bson.M{})
@@ -148,3 +148,26 @@ func (dao CourseDao) GetAllCourses() ([]CourseDao, error) {
 	return courses, nil
 }
 
+// UpdateCourseImage grava a imagem enviada no próprio documento do curso.
+// Retorna false se o curso não existir.
+func (dao CourseDao) UpdateCourseImage(ctx context.Context, courseID bson.ObjectID, data []byte, contentType string) (bool, error) {
+	collection := database.DB.Collection(courseCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"image_data": bson.Binary{Subtype: 0, Data: data},
+			"image_type": contentType,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": courseID}, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
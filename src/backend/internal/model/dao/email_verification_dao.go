@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EmailVerificationDao representa um link de confirmação enviado para um endereço de email.
+type EmailVerificationDao struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id"`
+	Email     string        `bson:"email"`             // Endereço que está sendo confirmado
+	Purpose   string        `bson:"purpose,omitempty"` // Ver EffectivePurpose
+	TokenHash string        `bson:"token_hash"`
+	ExpiresAt time.Time     `bson:"expires_at"`
+	UsedAt    time.Time     `bson:"used_at,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+// Finalidades de um link de verificação. Reenviar a confirmação do email atual
+// não pode invalidar uma troca de email pendente, e vice-versa.
+const (
+	EmailVerificationPurposeConfirm = "confirm" // Confirma o email atual da conta
+	EmailVerificationPurposeChange  = "change"  // Confirma o novo endereço de uma troca de email
+)
+
+const emailVerificationCollectionName = "email_verification"
+
+// EffectivePurpose retorna a finalidade do link, considerando links sem finalidade como confirm
+func (dao EmailVerificationDao) EffectivePurpose() string {
+	if dao.Purpose == "" {
+		return EmailVerificationPurposeConfirm
+	}
+	return dao.Purpose
+}
+
+// ErrEmailVerificationAlreadyUsed indica que o link já foi utilizado ou substituído
+var ErrEmailVerificationAlreadyUsed = errors.New("link de verificação já utilizado")
+
+// EnsureIndexes cria o índice de busca por hash e o índice TTL que remove links expirados
+func (dao EmailVerificationDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(emailVerificationCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// CreateEmailVerification persiste um novo link de verificação
+func (dao EmailVerificationDao) CreateEmailVerification(ctx context.Context, verification EmailVerificationDao) (EmailVerificationDao, error) {
+	verification.ID = bson.NewObjectID()
+	verification.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(emailVerificationCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, verification)
+	if err != nil {
+		return EmailVerificationDao{}, err
+	}
+
+	return verification, nil
+}
+
+// FindByHash busca um link de verificação pelo hash do token
+func (dao EmailVerificationDao) FindByHash(ctx context.Context, tokenHash string) (EmailVerificationDao, error) {
+	collection := database.DB.Collection(emailVerificationCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var verification EmailVerificationDao
+	err := collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&verification)
+	if err != nil {
+		return EmailVerificationDao{}, err
+	}
+
+	return verification, nil
+}
+
+// MarkUsed consome o link de forma atômica, garantindo uso único
+func (dao EmailVerificationDao) MarkUsed(ctx context.Context, verificationID bson.ObjectID) error {
+	collection := database.DB.Collection(emailVerificationCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":     verificationID,
+		"used_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"used_at": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrEmailVerificationAlreadyUsed
+	}
+
+	return nil
+}
+
+// InvalidateForUser invalida os links pendentes do usuário com a finalidade
+// informada (um novo envio substitui os anteriores)
+func (dao EmailVerificationDao) InvalidateForUser(ctx context.Context, userID bson.ObjectID, purpose string) error {
+	collection := database.DB.Collection(emailVerificationCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userID,
+		"purpose": purpose,
+		"used_at": bson.M{"$exists": false},
+	}
+	if purpose == EmailVerificationPurposeConfirm {
+		// Links sem o campo purpose também confirmam o email atual
+		filter["purpose"] = bson.M{"$in": bson.A{purpose, nil}}
+	}
+	update := bson.M{
+		"$set": bson.M{"used_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -47,7 +47,7 @@ func (i ImageDao) MarshalJSON() ([]byte, error) {
 }
 
 // CreateImage cria uma nova imagem no banco
-func (dao ImageDao) CreateImage(name string, data []byte, mimeType string) (ImageDao, error) {
+func (dao ImageDao) CreateImage(ctx context.Context, name string, data []byte, mimeType string) (ImageDao, error) {
 	image := ImageDao{
 		ID:        bson.NewObjectID(),
 		Name:      name,
@@ -58,7 +58,7 @@ func (dao ImageDao) CreateImage(name string, data []byte, mimeType string) (Imag
 	}
 
 	collection := database.DB.Collection(imageCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	_, err := collection.InsertOne(ctx, image)
@@ -70,10 +70,10 @@ func (dao ImageDao) CreateImage(name string, data []byte, mimeType string) (Imag
 }
 
 // FindImageByName busca uma imagem pelo nome
-func (dao ImageDao) FindImageByName(name string) (ImageDao, error) {
+func (dao ImageDao) FindImageByName(ctx context.Context, name string) (ImageDao, error) {
 	collection := database.DB.Collection(imageCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	var image ImageDao
@@ -86,10 +86,10 @@ func (dao ImageDao) FindImageByName(name string) (ImageDao, error) {
 }
 
 // FindImageByID busca uma imagem pelo ID
-func (dao ImageDao) FindImageByID(id bson.ObjectID) (ImageDao, error) {
+func (dao ImageDao) FindImageByID(ctx context.Context, id bson.ObjectID) (ImageDao, error) {
 	collection := database.DB.Collection(imageCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	var image ImageDao
@@ -102,10 +102,10 @@ func (dao ImageDao) FindImageByID(id bson.ObjectID) (ImageDao, error) {
 }
 
 // UpdateImage atualiza uma imagem existente
-func (dao ImageDao) UpdateImage(id bson.ObjectID, name string, data []byte, mimeType string) error {
+func (dao ImageDao) UpdateImage(ctx context.Context, id bson.ObjectID, name string, data []byte, mimeType string) error {
 	collection := database.DB.Collection(imageCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	update := bson.M{
@@ -122,10 +122,10 @@ func (dao ImageDao) UpdateImage(id bson.ObjectID, name string, data []byte, mime
 }
 
 // DeleteImage deleta uma imagem
-func (dao ImageDao) DeleteImage(id bson.ObjectID) error {
+func (dao ImageDao) DeleteImage(ctx context.Context, id bson.ObjectID) error {
 	collection := database.DB.Collection(imageCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	_, err := collection.DeleteOne(ctx, bson.M{"_id": id})
@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Tipos de lançamento
+const (
+	LedgerEntryPurchase = "purchase"
+)
+
+// LedgerEntryDao é um lançamento financeiro. Cada compra tem no máximo um
+// lançamento de cada tipo (índice único purchase_id/type).
+type LedgerEntryDao struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	UserID     bson.ObjectID `bson:"user_id"`
+	CourseID   bson.ObjectID `bson:"course_id"`
+	PurchaseID bson.ObjectID `bson:"purchase_id"`
+	Type       string        `bson:"type"`
+	Amount     float64       `bson:"amount"` // Preço do curso no momento da compra
+	CreatedAt  time.Time     `bson:"created_at"`
+}
+
+const ledgerCollectionName = "ledger"
+
+var ErrLedgerEntryExists = errors.New("lançamento já registrado para esta compra")
+
+// RecordEntry grava o lançamento; retorna ErrLedgerEntryExists se a compra já tiver um do mesmo tipo
+func (dao LedgerEntryDao) RecordEntry(ctx context.Context, entry LedgerEntryDao) (LedgerEntryDao, error) {
+	entry.ID = bson.NewObjectID()
+	entry.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(ledgerCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return LedgerEntryDao{}, ErrLedgerEntryExists
+	}
+	if err != nil {
+		return LedgerEntryDao{}, err
+	}
+
+	return entry, nil
+}
+
+// FindByPurchase retorna o lançamento do tipo para a compra
+func (dao LedgerEntryDao) FindByPurchase(ctx context.Context, purchaseID bson.ObjectID, entryType string) (LedgerEntryDao, error) {
+	collection := database.DB.Collection(ledgerCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var entry LedgerEntryDao
+	if err := collection.FindOne(ctx, bson.M{"purchase_id": purchaseID, "type": entryType}).Decode(&entry); err != nil {
+		return LedgerEntryDao{}, err
+	}
+
+	return entry, nil
+}
@@ -23,10 +23,10 @@ type LessonDao struct {
 
 const lessonCollectionName = "lesson"
 
-func (dao LessonDao) GetLessonsByIDs(lessonIDs []bson.ObjectID) ([]LessonDao, error) {
+func (dao LessonDao) GetLessonsByIDs(ctx context.Context, lessonIDs []bson.ObjectID) ([]LessonDao, error) {
 	collection := database.DB.Collection(lessonCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	filter := bson.M{
@@ -49,3 +49,20 @@ func (dao LessonDao) GetLessonsByIDs(lessonIDs []bson.ObjectID) ([]LessonDao, er
 	return lessons, nil
 }
 
+// CreateLesson cria uma aula
+func (dao LessonDao) CreateLesson(ctx context.Context, lesson LessonDao) (LessonDao, error) {
+	lesson.ID = bson.NewObjectID()
+	lesson.CreatedAt = time.Now()
+	lesson.UpdatedAt = time.Now()
+
+	collection := database.DB.Collection(lessonCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, lesson)
+	if err != nil {
+		return LessonDao{}, err
+	}
+
+	return lesson, nil
+}
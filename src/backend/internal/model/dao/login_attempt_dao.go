@@ -0,0 +1,128 @@
+package dao
+
+import (
+	"context"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// LoginAttemptDao acumula as falhas de login de uma chave ("email:<email>" ou "ip:<ip>").
+// O documento expira sozinho pelo índice TTL quando deixa de ser relevante.
+type LoginAttemptDao struct {
+	ID            bson.ObjectID `bson:"_id,omitempty"`
+	Key           string        `bson:"key"`
+	Failures      int           `bson:"failures"`
+	LastFailureAt time.Time     `bson:"last_failure_at"`
+	LockedUntil   time.Time     `bson:"locked_until,omitempty"`
+	ExpiresAt     time.Time     `bson:"expires_at"`
+}
+
+const loginAttemptCollectionName = "login_attempt"
+
+// EnsureIndexes cria o índice único por chave e o índice TTL de expires_at
+func (dao LoginAttemptDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(loginAttemptCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// FindByKey retorna o registro de falhas da chave; sem registro, retorna um valor vazio
+func (dao LoginAttemptDao) FindByKey(ctx context.Context, key string) (LoginAttemptDao, error) {
+	collection := database.DB.Collection(loginAttemptCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var attempt LoginAttemptDao
+	err := collection.FindOne(ctx, bson.M{"key": key}).Decode(&attempt)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return LoginAttemptDao{Key: key}, nil
+		}
+		return LoginAttemptDao{}, err
+	}
+
+	return attempt, nil
+}
+
+// RegisterFailure incrementa o contador de falhas da chave de forma atômica.
+// Falhas mais antigas que a janela informada são descartadas antes da contagem.
+func (dao LoginAttemptDao) RegisterFailure(ctx context.Context, key string, window time.Duration, retention time.Duration) (LoginAttemptDao, error) {
+	collection := database.DB.Collection(loginAttemptCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	// Reinicia a contagem se a última falha estiver fora da janela
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"key": key, "last_failure_at": bson.M{"$lt": now.Add(-window)}},
+		bson.M{"$set": bson.M{"failures": 0}},
+	)
+	if err != nil {
+		return LoginAttemptDao{}, err
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{
+			"last_failure_at": now,
+			"expires_at":      now.Add(retention),
+		},
+		"$setOnInsert": bson.M{"_id": bson.NewObjectID()},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt LoginAttemptDao
+	err = collection.FindOneAndUpdate(ctx, bson.M{"key": key}, update, opts).Decode(&attempt)
+	if err != nil {
+		return LoginAttemptDao{}, err
+	}
+
+	return attempt, nil
+}
+
+// Lock bloqueia a chave até o horário informado
+func (dao LoginAttemptDao) Lock(ctx context.Context, key string, until time.Time) error {
+	collection := database.DB.Collection(loginAttemptCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{"locked_until": until},
+		"$max": bson.M{"expires_at": until},
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"key": key}, update)
+	return err
+}
+
+// Reset remove o histórico de falhas e o bloqueio da chave
+func (dao LoginAttemptDao) Reset(ctx context.Context, key string) error {
+	collection := database.DB.Collection(loginAttemptCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.DeleteOne(ctx, bson.M{"key": key})
+	return err
+}
@@ -72,10 +72,10 @@ func (n NewsDao) MarshalJSON() ([]byte, error) {
 	})
 }
 
-func (dao NewsDao) GetAllNews() ([]NewsDao, error) {
+func (dao NewsDao) GetAllNews(ctx context.Context) ([]NewsDao, error) {
 	collection := database.DB.Collection(newsCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}})
@@ -93,10 +93,10 @@ func (dao NewsDao) GetAllNews() ([]NewsDao, error) {
 	return news, nil
 }
 
-func (dao NewsDao) GetLastNews(limit int) ([]NewsDao, error) {
+func (dao NewsDao) GetLastNews(ctx context.Context, limit int) ([]NewsDao, error) {
 	collection := database.DB.Collection(newsCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}}).SetLimit(int64(limit))
@@ -114,10 +114,10 @@ func (dao NewsDao) GetLastNews(limit int) ([]NewsDao, error) {
 	return news, nil
 }
 
-func (dao NewsDao) FindByID(newsID bson.ObjectID) (NewsDao, error) {
+func (dao NewsDao) FindByID(ctx context.Context, newsID bson.ObjectID) (NewsDao, error) {
 	collection := database.DB.Collection(newsCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	var news NewsDao
@@ -129,7 +129,7 @@ func (dao NewsDao) FindByID(newsID bson.ObjectID) (NewsDao, error) {
 	return news, nil
 }
 
-func (dao NewsDao) CreateNews(news NewsDao) (NewsDao, error) {
+func (dao NewsDao) CreateNews(ctx context.Context, news NewsDao) (NewsDao, error) {
 	news.ID = bson.NewObjectID()
 	if news.Date.IsZero() {
 		news.Date = time.Now()
@@ -138,7 +138,7 @@ func (dao NewsDao) CreateNews(news NewsDao) (NewsDao, error) {
 	news.UpdatedAt = time.Now()
 
 	collection := database.DB.Collection(newsCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	_, err := collection.InsertOne(ctx, news)
@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// NoteDao é uma anotação do aluno em um curso ou aula. É criada no app (inclusive
+// offline), com o ID gerado no dispositivo, e só é gravada pela sincronização.
+// Uma exclusão é uma nota com Deleted, para que ela também seja replicada.
+type NoteDao struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id"`
+	CourseID  bson.ObjectID `bson:"course_id"`
+	LessonID  bson.ObjectID `bson:"lesson_id,omitempty"`
+	Content   string        `bson:"content"`
+	Deleted   bool          `bson:"deleted,omitempty"`
+	Version   int64         `bson:"version"`    // Incrementada pelo dispositivo a cada edição
+	DeviceID  string        `bson:"device_id"`  // Dispositivo da última edição
+	UpdatedAt time.Time     `bson:"updated_at"` // Relógio do dispositivo na última edição
+	Seq       int64         `bson:"seq"`        // Sequência de sincronização (ver SyncDao)
+}
+
+const noteCollectionName = "note"
+
+// Supersedes decide, de forma determinística, se esta edição substitui a atual:
+// vence a maior versão; no empate, o maior updated_at; depois, o maior device_id.
+// A mesma edição recebida duas vezes não substitui a si mesma.
+func (dao NoteDao) Supersedes(current NoteDao) bool {
+	if dao.Version != current.Version {
+		return dao.Version > current.Version
+	}
+	if !dao.UpdatedAt.Equal(current.UpdatedAt) {
+		return dao.UpdatedAt.After(current.UpdatedAt)
+	}
+	return dao.DeviceID > current.DeviceID
+}
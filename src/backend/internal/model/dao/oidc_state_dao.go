@@ -0,0 +1,75 @@
+package dao
+
+import (
+	"context"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// OIDCStateDao guarda os dados de um login OIDC em andamento entre o início do
+// fluxo e o retorno do provedor. O code_verifier do PKCE nunca sai do servidor.
+type OIDCStateDao struct {
+	ID           bson.ObjectID `bson:"_id,omitempty"`
+	StateHash    string        `bson:"state_hash"`
+	Provider     string        `bson:"provider"`
+	CodeVerifier string        `bson:"code_verifier"`
+	Nonce        string        `bson:"nonce"`
+	RedirectURI  string        `bson:"redirect_uri"`
+	ExpiresAt    time.Time     `bson:"expires_at"`
+	CreatedAt    time.Time     `bson:"created_at"`
+}
+
+const oidcStateCollectionName = "oidc_state"
+
+// EnsureIndexes cria o índice único de state e o índice TTL que remove fluxos abandonados
+func (dao OIDCStateDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(oidcStateCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "state_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// CreateOIDCState persiste o início de um fluxo de login
+func (dao OIDCStateDao) CreateOIDCState(ctx context.Context, state OIDCStateDao) error {
+	state.ID = bson.NewObjectID()
+	state.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(oidcStateCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, state)
+	return err
+}
+
+// ConsumeByHash busca e remove o fluxo em uma única operação, garantindo que o state seja usado uma vez
+func (dao OIDCStateDao) ConsumeByHash(ctx context.Context, stateHash string) (OIDCStateDao, error) {
+	collection := database.DB.Collection(oidcStateCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var state OIDCStateDao
+	err := collection.FindOneAndDelete(ctx, bson.M{"state_hash": stateHash}).Decode(&state)
+	if err != nil {
+		return OIDCStateDao{}, err
+	}
+
+	return state, nil
+}
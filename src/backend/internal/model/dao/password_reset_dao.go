@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// PasswordResetDao representa um pedido de redefinição de senha. Assim como nos
+// refresh tokens, apenas o hash do token enviado por email é persistido.
+type PasswordResetDao struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id"`
+	TokenHash string        `bson:"token_hash"`
+	ExpiresAt time.Time     `bson:"expires_at"`
+	UsedAt    time.Time     `bson:"used_at,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+const passwordResetCollectionName = "password_reset"
+
+// ErrPasswordResetAlreadyUsed indica que o token já foi utilizado ou invalidado
+var ErrPasswordResetAlreadyUsed = errors.New("token de redefinição já utilizado")
+
+// EnsureIndexes cria o índice de busca por hash e o índice TTL que remove pedidos expirados
+func (dao PasswordResetDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(passwordResetCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// CreatePasswordReset persiste um novo pedido de redefinição
+func (dao PasswordResetDao) CreatePasswordReset(ctx context.Context, reset PasswordResetDao) (PasswordResetDao, error) {
+	reset.ID = bson.NewObjectID()
+	reset.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(passwordResetCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, reset)
+	if err != nil {
+		return PasswordResetDao{}, err
+	}
+
+	return reset, nil
+}
+
+// FindByHash busca um pedido de redefinição pelo hash do token
+func (dao PasswordResetDao) FindByHash(ctx context.Context, tokenHash string) (PasswordResetDao, error) {
+	collection := database.DB.Collection(passwordResetCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var reset PasswordResetDao
+	err := collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&reset)
+	if err != nil {
+		return PasswordResetDao{}, err
+	}
+
+	return reset, nil
+}
+
+// MarkUsed consome o token de forma atômica, garantindo uso único
+func (dao PasswordResetDao) MarkUsed(ctx context.Context, resetID bson.ObjectID) error {
+	collection := database.DB.Collection(passwordResetCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":     resetID,
+		"used_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"used_at": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrPasswordResetAlreadyUsed
+	}
+
+	return nil
+}
+
+// Release devolve um token consumido por MarkUsed quando a troca de senha falha,
+// para que o usuário possa tentar de novo com o mesmo link
+func (dao PasswordResetDao) Release(ctx context.Context, resetID bson.ObjectID) error {
+	collection := database.DB.Collection(passwordResetCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": resetID}, bson.M{"$unset": bson.M{"used_at": ""}})
+	return err
+}
+
+// InvalidateForUser invalida os pedidos pendentes do usuário (um novo pedido substitui os anteriores)
+func (dao PasswordResetDao) InvalidateForUser(ctx context.Context, userID bson.ObjectID) error {
+	collection := database.DB.Collection(passwordResetCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userID,
+		"used_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"used_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
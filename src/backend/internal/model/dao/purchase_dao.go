@@ -2,10 +2,12 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"metabee/internal/database"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type PurchaseDao struct {
@@ -21,8 +23,11 @@ type PurchaseDao struct {
 
 const purchaseCollectionName = "purchase"
 
-// CreatePurchase cria uma nova compra de curso
-func (dao PurchaseDao) CreatePurchase(userID, courseID bson.ObjectID, driveLink string) (PurchaseDao, error) {
+var ErrPurchaseExists = errors.New("compra já registrada para este usuário e curso")
+
+// CreatePurchase cria uma nova compra de curso; retorna ErrPurchaseExists se o
+// usuário já tiver comprado o curso (índice único usuário/curso)
+func (dao PurchaseDao) CreatePurchase(ctx context.Context, userID, courseID bson.ObjectID, driveLink string) (PurchaseDao, error) {
 	purchase := PurchaseDao{
 		ID:        bson.NewObjectID(),
 		UserID:    userID,
@@ -34,10 +39,13 @@ func (dao PurchaseDao) CreatePurchase(userID, courseID bson.ObjectID, driveLink
 	}
 
 	collection := database.DB.Collection(purchaseCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	_, err := collection.InsertOne(ctx, purchase)
+	if mongo.IsDuplicateKeyError(err) {
+		return PurchaseDao{}, ErrPurchaseExists
+	}
 	if err != nil {
 		return PurchaseDao{}, err
 	}
@@ -46,10 +54,10 @@ func (dao PurchaseDao) CreatePurchase(userID, courseID bson.ObjectID, driveLink
 }
 
 // GetPurchasesByUser retorna todas as compras do usuário
-func (dao PurchaseDao) GetPurchasesByUser(userID bson.ObjectID) ([]PurchaseDao, error) {
+func (dao PurchaseDao) GetPurchasesByUser(ctx context.Context, userID bson.ObjectID) ([]PurchaseDao, error) {
 	collection := database.DB.Collection(purchaseCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	cursor, err := collection.Find(ctx, bson.M{"user_id": userID})
@@ -67,10 +75,10 @@ func (dao PurchaseDao) GetPurchasesByUser(userID bson.ObjectID) ([]PurchaseDao,
 }
 
 // GetPurchaseByUserAndCourse retorna uma compra específica
-func (dao PurchaseDao) GetPurchaseByUserAndCourse(userID, courseID bson.ObjectID) (PurchaseDao, error) {
+func (dao PurchaseDao) GetPurchaseByUserAndCourse(ctx context.Context, userID, courseID bson.ObjectID) (PurchaseDao, error) {
 	collection := database.DB.Collection(purchaseCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	var purchase PurchaseDao
@@ -87,10 +95,10 @@ func (dao PurchaseDao) GetPurchaseByUserAndCourse(userID, courseID bson.ObjectID
 }
 
 // UpdatePurchaseStatus atualiza o status da compra
-func (dao PurchaseDao) UpdatePurchaseStatus(purchaseID bson.ObjectID, status string, localPath string) error {
+func (dao PurchaseDao) UpdatePurchaseStatus(ctx context.Context, purchaseID bson.ObjectID, status string, localPath string) error {
 	collection := database.DB.Collection(purchaseCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	update := bson.M{
@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RefreshTokenDao representa um refresh token emitido. Apenas o hash SHA-256
+// do token é persistido; o valor original só é conhecido pelo cliente.
+type RefreshTokenDao struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	UserID     bson.ObjectID `bson:"user_id"`
+	FamilyID   bson.ObjectID `bson:"family_id"` // Todos os tokens de uma mesma sessão compartilham a família
+	TokenHash  string        `bson:"token_hash"`
+	ExpiresAt  time.Time     `bson:"expires_at"`
+	UsedAt     time.Time     `bson:"used_at,omitempty"`     // Preenchido quando o token é trocado por um novo
+	RevokedAt  time.Time     `bson:"revoked_at,omitempty"`  // Preenchido quando a família é revogada
+	ReplacedBy bson.ObjectID `bson:"replaced_by,omitempty"` // Token emitido na rotação
+	CreatedAt  time.Time     `bson:"created_at"`
+}
+
+const refreshTokenCollectionName = "refresh_token"
+
+// ErrRefreshTokenAlreadyUsed indica que o token já foi rotacionado ou revogado
+var ErrRefreshTokenAlreadyUsed = errors.New("refresh token já utilizado")
+
+// EnsureIndexes cria o índice único de hash, o índice por família e o índice TTL de expires_at
+func (dao RefreshTokenDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// CreateRefreshToken persiste um novo refresh token
+func (dao RefreshTokenDao) CreateRefreshToken(ctx context.Context, token RefreshTokenDao) (RefreshTokenDao, error) {
+	if token.ID.IsZero() {
+		token.ID = bson.NewObjectID()
+	}
+	token.CreatedAt = time.Now()
+
+	collection := database.DB.Collection(refreshTokenCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, token)
+	if err != nil {
+		return RefreshTokenDao{}, err
+	}
+
+	return token, nil
+}
+
+// FindByHash busca um refresh token pelo hash
+func (dao RefreshTokenDao) FindByHash(ctx context.Context, tokenHash string) (RefreshTokenDao, error) {
+	collection := database.DB.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var token RefreshTokenDao
+	err := collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if err != nil {
+		return RefreshTokenDao{}, err
+	}
+
+	return token, nil
+}
+
+// MarkUsed marca o token como utilizado de forma atômica. Se o token já tiver
+// sido usado ou revogado (por exemplo, em duas requisições simultâneas),
+// retorna ErrRefreshTokenAlreadyUsed.
+func (dao RefreshTokenDao) MarkUsed(ctx context.Context, tokenID, replacedBy bson.ObjectID) error {
+	collection := database.DB.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":        tokenID,
+		"used_at":    bson.M{"$exists": false},
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"used_at":     time.Now(),
+			"replaced_by": replacedBy,
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrRefreshTokenAlreadyUsed
+	}
+
+	return nil
+}
+
+// RevokeFamily revoga todos os tokens ainda ativos de uma família (sessão)
+func (dao RefreshTokenDao) RevokeFamily(ctx context.Context, familyID bson.ObjectID) error {
+	collection := database.DB.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"family_id":  familyID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// RevokeAllForUser revoga todos os refresh tokens ativos do usuário
+func (dao RefreshTokenDao) RevokeAllForUser(ctx context.Context, userID bson.ObjectID) error {
+	collection := database.DB.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// RevokeAllForUserExcept revoga os refresh tokens ativos do usuário, exceto os da família informada
+func (dao RefreshTokenDao) RevokeAllForUserExcept(ctx context.Context, userID, keepFamilyID bson.ObjectID) error {
+	collection := database.DB.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"family_id":  bson.M{"$ne": keepFamilyID},
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// IsFamilyRevoked indica se a família (sessão) foi revogada
+func (dao RefreshTokenDao) IsFamilyRevoked(ctx context.Context, familyID bson.ObjectID) (bool, error) {
+	collection := database.DB.Collection(refreshTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"family_id":  familyID,
+		"revoked_at": bson.M{"$exists": true},
+	}
+
+	count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
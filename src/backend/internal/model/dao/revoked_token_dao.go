@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RevokedTokenDao registra o jti de um access token revogado antes de expirar.
+// O documento é removido automaticamente pelo índice TTL após expires_at.
+type RevokedTokenDao struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	JTI       string        `bson:"jti"`
+	UserID    bson.ObjectID `bson:"user_id"`
+	ExpiresAt time.Time     `bson:"expires_at"` // Expiração original do token
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+const revokedTokenCollectionName = "revoked_token"
+
+// EnsureIndexes cria o índice único de jti e o índice TTL de expires_at
+func (dao RevokedTokenDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(revokedTokenCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "jti", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// RevokeToken adiciona o jti à lista de revogação. Revogar o mesmo token duas vezes não é erro.
+func (dao RevokedTokenDao) RevokeToken(ctx context.Context, jti string, userID bson.ObjectID, expiresAt time.Time) error {
+	collection := database.DB.Collection(revokedTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":        bson.NewObjectID(),
+			"user_id":    userID,
+			"expires_at": expiresAt,
+			"created_at": time.Now(),
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"jti": jti}, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// IsRevoked verifica se o jti está na lista de revogação
+func (dao RevokedTokenDao) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	collection := database.DB.Collection(revokedTokenCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	count, err := collection.CountDocuments(ctx, bson.M{"jti": jti})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
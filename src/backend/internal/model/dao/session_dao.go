@@ -0,0 +1,186 @@
+package dao
+
+import (
+	"context"
+	"metabee/internal/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SessionDao representa um login em um dispositivo. O ID é o mesmo da família
+// de refresh tokens (claim "sid" do access token).
+type SessionDao struct {
+	ID         bson.ObjectID `bson:"_id,omitempty"`
+	UserID     bson.ObjectID `bson:"user_id"`
+	DeviceName string        `bson:"device_name,omitempty"` // Informado pelo cliente (header X-Device-Name)
+	IP         string        `bson:"ip,omitempty"`          // IP do último acesso
+	UserAgent  string        `bson:"user_agent,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at"`
+	LastSeenAt time.Time     `bson:"last_seen_at"`
+	ExpiresAt  time.Time     `bson:"expires_at"` // Expiração do refresh token mais recente
+	RevokedAt  time.Time     `bson:"revoked_at,omitempty"`
+}
+
+const sessionCollectionName = "session"
+
+// EnsureIndexes cria o índice de listagem por usuário e o índice TTL que remove sessões expiradas
+func (dao SessionDao) EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(sessionCollectionName)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// CreateSession persiste uma nova sessão
+func (dao SessionDao) CreateSession(ctx context.Context, session SessionDao) (SessionDao, error) {
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	collection := database.DB.Collection(sessionCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, session)
+	if err != nil {
+		return SessionDao{}, err
+	}
+
+	return session, nil
+}
+
+// FindActiveByUser lista as sessões não revogadas e não expiradas do usuário, da mais recente para a mais antiga
+func (dao SessionDao) FindActiveByUser(ctx context.Context, userID bson.ObjectID) ([]SessionDao, error) {
+	collection := database.DB.Collection(sessionCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []SessionDao{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// FindByIDForUser busca uma sessão garantindo que ela pertence ao usuário
+func (dao SessionDao) FindByIDForUser(ctx context.Context, sessionID, userID bson.ObjectID) (SessionDao, error) {
+	collection := database.DB.Collection(sessionCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var session SessionDao
+	err := collection.FindOne(ctx, bson.M{"_id": sessionID, "user_id": userID}).Decode(&session)
+	if err != nil {
+		return SessionDao{}, err
+	}
+
+	return session, nil
+}
+
+// Touch atualiza o último acesso da sessão. Com expiresAt preenchido (renovação
+// de tokens) a expiração também é estendida.
+func (dao SessionDao) Touch(ctx context.Context, sessionID bson.ObjectID, ip string, expiresAt time.Time) error {
+	collection := database.DB.Collection(sessionCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	set := bson.M{"last_seen_at": time.Now()}
+	if ip != "" {
+		set["ip"] = ip
+	}
+	if !expiresAt.IsZero() {
+		set["expires_at"] = expiresAt
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": sessionID}, bson.M{"$set": set})
+	return err
+}
+
+// TouchIfIdle atualiza o último acesso apenas se ele for anterior a idleSince,
+// evitando uma escrita por requisição autenticada
+func (dao SessionDao) TouchIfIdle(ctx context.Context, sessionID bson.ObjectID, ip string, idleSince time.Time) error {
+	collection := database.DB.Collection(sessionCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":          sessionID,
+		"last_seen_at": bson.M{"$lt": idleSince},
+	}
+	update := bson.M{
+		"$set": bson.M{"last_seen_at": time.Now(), "ip": ip},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// Revoke marca a sessão como encerrada
+func (dao SessionDao) Revoke(ctx context.Context, sessionID bson.ObjectID) error {
+	collection := database.DB.Collection(sessionCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":        sessionID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RevokeAllForUserExcept encerra as sessões do usuário, exceto keepSessionID
+// (use bson.NilObjectID para encerrar todas)
+func (dao SessionDao) RevokeAllForUserExcept(ctx context.Context, userID, keepSessionID bson.ObjectID) error {
+	collection := database.DB.Collection(sessionCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"_id":        bson.M{"$ne": keepSessionID},
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,422 @@
+package dao
+
+import (
+	"context"
+	"log"
+	"metabee/internal/database"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SyncChanges é uma página dos registros de um usuário alterados depois de uma
+// seq, em ordem de seq
+type SyncChanges struct {
+	StudySessions []StudySessionDao
+	Progress      []UserProgressDao
+	Notes         []NoteDao
+	Next          int64 // Seq do último registro incluído (a seq inicial quando não há nenhum)
+	More          bool  // Há registros depois de Next
+}
+
+// Page mantém apenas os limit registros de menor seq, preenchendo Next e More.
+// Cada lista pode vir em qualquer ordem e com mais de limit registros.
+func (changes SyncChanges) Page(since int64, limit int) SyncChanges {
+	seqs := make([]int64, 0, len(changes.StudySessions)+len(changes.Progress)+len(changes.Notes))
+	for _, session := range changes.StudySessions {
+		seqs = append(seqs, session.Seq)
+	}
+	for _, progress := range changes.Progress {
+		seqs = append(seqs, progress.Seq)
+	}
+	for _, note := range changes.Notes {
+		seqs = append(seqs, note.Seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	page := SyncChanges{
+		StudySessions: []StudySessionDao{},
+		Progress:      []UserProgressDao{},
+		Notes:         []NoteDao{},
+		Next:          since,
+		More:          len(seqs) > limit,
+	}
+	if len(seqs) == 0 {
+		return page
+	}
+	if page.More {
+		seqs = seqs[:limit]
+	}
+	page.Next = seqs[len(seqs)-1]
+
+	for _, session := range changes.StudySessions {
+		if session.Seq <= page.Next {
+			page.StudySessions = append(page.StudySessions, session)
+		}
+	}
+	for _, progress := range changes.Progress {
+		if progress.Seq <= page.Next {
+			page.Progress = append(page.Progress, progress)
+		}
+	}
+	for _, note := range changes.Notes {
+		if note.Seq <= page.Next {
+			page.Notes = append(page.Notes, note)
+		}
+	}
+	sort.Slice(page.StudySessions, func(i, j int) bool { return page.StudySessions[i].Seq < page.StudySessions[j].Seq })
+	sort.Slice(page.Progress, func(i, j int) bool { return page.Progress[i].Seq < page.Progress[j].Seq })
+	sort.Slice(page.Notes, func(i, j int) bool { return page.Notes[i].Seq < page.Notes[j].Seq })
+	return page
+}
+
+// SyncStateDao guarda, no app desktop, até onde o usuário já sincronizou com o servidor central
+type SyncStateDao struct {
+	UserID     bson.ObjectID `bson:"_id"`
+	PushedSeq  int64         `bson:"pushed_seq"` // Última seq local enviada ao servidor central
+	PulledSeq  int64         `bson:"pulled_seq"` // Última seq do servidor central já aplicada aqui
+	LastSyncAt time.Time     `bson:"last_sync_at,omitempty"`
+}
+
+type syncCounterDao struct {
+	UserID  bson.ObjectID        `bson:"_id"`
+	Seq     int64                `bson:"seq"`
+	Pending []syncReservationDao `bson:"pending,omitempty"` // Seqs reservadas cuja gravação ainda não terminou
+}
+
+type syncReservationDao struct {
+	Seq int64     `bson:"seq"`
+	At  time.Time `bson:"at"`
+}
+
+// syncReservationLease é por quanto tempo uma seq reservada segura Changes. A
+// gravação termina antes (ela respeita database.QueryTimeout); passado o prazo,
+// a reserva é de um processo que caiu no meio da gravação.
+func syncReservationLease() time.Duration {
+	return 2 * database.QueryTimeout()
+}
+
+// committedSeq retorna a maior seq até a qual todas as gravações já terminaram:
+// uma gravação com seq menor ainda pode aparecer depois de outra com seq maior
+func (counter syncCounterDao) committedSeq(now time.Time, lease time.Duration) int64 {
+	committed := counter.Seq
+	for _, reservation := range counter.Pending {
+		if reservation.Seq <= committed && now.Sub(reservation.At) < lease {
+			committed = reservation.Seq - 1
+		}
+	}
+	return committed
+}
+
+const (
+	syncCounterCollectionName = "sync_counter"
+	syncStateCollectionName   = "sync_state"
+)
+
+// SyncDao grava os registros sincronizados entre o app desktop e o servidor
+// central: sessões de estudo, progresso e notas. Cada gravação que altera um
+// registro recebe o próximo número da sequência do usuário (seq), usada por
+// Changes; a sequência pode ter lacunas. A seq é reservada antes da gravação,
+// então duas gravações do mesmo usuário podem terminar fora de ordem: Changes
+// só lista até a seq confirmada (committedSeq). As gravações condicionais
+// dependem dos índices únicos de user_progress (migração 4).
+type SyncDao struct{}
+
+// reserveSeq reserva o próximo número da sequência do usuário. release deve ser
+// chamada quando a gravação terminar, com ou sem erro.
+func (dao SyncDao) reserveSeq(ctx context.Context, userID bson.ObjectID) (int64, func(), error) {
+	collection := database.DB.Collection(syncCounterCollectionName)
+
+	// Incrementa e registra a reserva na mesma atualização, descartando as vencidas
+	now := time.Now()
+	update := bson.A{
+		bson.M{"$set": bson.M{"seq": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$seq", 0}}, 1}}}},
+		bson.M{"$set": bson.M{"pending": bson.M{"$concatArrays": bson.A{
+			bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$pending", bson.A{}}},
+				"cond":  bson.M{"$gt": bson.A{"$$this.at", now.Add(-syncReservationLease())}},
+			}},
+			bson.A{bson.M{"seq": "$seq", "at": now}},
+		}}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter syncCounterDao
+	if err := collection.FindOneAndUpdate(ctx, bson.M{"_id": userID}, update, opts).Decode(&counter); err != nil {
+		return 0, nil, err
+	}
+
+	release := func() {
+		// Libera mesmo com ctx cancelado; se falhar, a reserva vence sozinha
+		ctx, cancel := database.WithQueryTimeout(context.WithoutCancel(ctx))
+		defer cancel()
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$pull": bson.M{"pending": bson.M{"seq": counter.Seq}}}); err != nil {
+			log.Printf("⚠️ Erro ao liberar a seq %d de sincronização - UserID: %s, Erro: %v", counter.Seq, userID.Hex(), err)
+		}
+	}
+	return counter.Seq, release, nil
+}
+
+// committedSeq retorna a seq confirmada do usuário (syncCounterDao.committedSeq)
+func (dao SyncDao) committedSeq(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	collection := database.DB.Collection(syncCounterCollectionName)
+
+	var counter syncCounterDao
+	err := collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&counter)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.committedSeq(time.Now(), syncReservationLease()), nil
+}
+
+// ApplyStudySession insere a sessão com o ID gerado no dispositivo e atualiza as
+// horas do progresso do curso (syncHours). Sessões são imutáveis: retorna false
+// se o ID já existir.
+func (dao SyncDao) ApplyStudySession(ctx context.Context, session StudySessionDao) (bool, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	seq, release, err := dao.reserveSeq(ctx, session.UserID)
+	if err != nil {
+		return false, err
+	}
+	session.Seq = seq
+	session.CreatedAt = time.Now()
+
+	_, err = database.DB.Collection(studySessionCollectionName).InsertOne(ctx, session)
+	release()
+	inserted := err == nil
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// Também no reenvio: completa as horas se a gravação anterior parou depois do insert
+	if err := dao.syncHours(ctx, session.UserID, session.CourseID); err != nil {
+		return false, err
+	}
+
+	return inserted, nil
+}
+
+// syncHours grava nas horas do progresso a soma das sessões de estudo do curso,
+// criando o progresso se preciso. As sessões só são inseridas, então a soma só
+// cresce: $max descarta a soma de uma gravação concorrente que viu menos sessões.
+func (dao SyncDao) syncHours(ctx context.Context, userID, courseID bson.ObjectID) error {
+	pipeline := []bson.M{
+		{"$match": bson.M{"user_id": userID, "course_id": courseID}},
+		{"$group": bson.M{"_id": nil, "hours": bson.M{"$sum": "$duration"}}},
+	}
+	cursor, err := database.DB.Collection(studySessionCollectionName).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var totals []struct {
+		Hours float64 `bson:"hours"`
+	}
+	err = cursor.All(ctx, &totals)
+	cursor.Close(ctx)
+	if err != nil || len(totals) == 0 {
+		return err
+	}
+	hours := totals[0].Hours
+
+	seq, release, err := dao.reserveSeq(ctx, userID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// Sem match o upsert esbarra no índice único de usuário/curso: as horas já estão em dia
+	now := time.Now()
+	filter := bson.M{"user_id": userID, "course_id": courseID, "hours": bson.M{"$lt": hours}}
+	update := bson.M{
+		"$max":         bson.M{"hours": hours},
+		"$set":         bson.M{"seq": seq, "updated_at": now},
+		"$setOnInsert": bson.M{"_id": bson.NewObjectID(), "progress": 0.0, "last_study": time.Time{}, "created_at": now},
+	}
+	_, err = database.DB.Collection(userProgressCollectionName).UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// ApplyProgress combina o progresso recebido com o registro do par usuário/curso
+// (UserProgressDao.Merge) e retorna o resultado e se ele mudou
+func (dao SyncDao) ApplyProgress(ctx context.Context, progress UserProgressDao) (UserProgressDao, bool, error) {
+	collection := database.DB.Collection(userProgressCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	seq, release, err := dao.reserveSeq(ctx, progress.UserID)
+	if err != nil {
+		return UserProgressDao{}, false, err
+	}
+	defer release()
+
+	now := time.Now()
+	// Só casa se algum campo for aumentar; sem match o upsert esbarra no índice
+	// único de usuário/curso, o que significa que nada mudou
+	filter := bson.M{
+		"user_id":   progress.UserID,
+		"course_id": progress.CourseID,
+		"$or": bson.A{
+			bson.M{"progress": bson.M{"$lt": progress.Progress}},
+			bson.M{"last_study": bson.M{"$lt": progress.LastStudy}},
+		},
+	}
+	// As horas vêm das sessões de estudo (syncHours), não do dispositivo
+	update := bson.M{
+		"$max": bson.M{
+			"progress":   progress.Progress,
+			"last_study": progress.LastStudy,
+		},
+		"$set":         bson.M{"seq": seq, "updated_at": now},
+		"$setOnInsert": bson.M{"_id": bson.NewObjectID(), "hours": 0.0, "created_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved UserProgressDao
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&saved)
+	if mongo.IsDuplicateKeyError(err) {
+		var current UserProgressDao
+		filter := bson.M{"user_id": progress.UserID, "course_id": progress.CourseID}
+		if err := collection.FindOne(ctx, filter).Decode(&current); err != nil {
+			return UserProgressDao{}, false, err
+		}
+		return current, false, nil
+	}
+	if err != nil {
+		return UserProgressDao{}, false, err
+	}
+
+	return saved, true, nil
+}
+
+// ApplyNote grava a nota se ela substituir a atual (NoteDao.Supersedes) e
+// retorna a versão vigente e se ela mudou
+func (dao SyncDao) ApplyNote(ctx context.Context, note NoteDao) (NoteDao, bool, error) {
+	collection := database.DB.Collection(noteCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	seq, release, err := dao.reserveSeq(ctx, note.UserID)
+	if err != nil {
+		return NoteDao{}, false, err
+	}
+	defer release()
+	note.Seq = seq
+
+	// Mesmo critério de NoteDao.Supersedes; sem match o upsert esbarra no _id
+	filter := bson.M{
+		"_id":     note.ID,
+		"user_id": note.UserID,
+		"$or": bson.A{
+			bson.M{"version": bson.M{"$lt": note.Version}},
+			bson.M{"version": note.Version, "updated_at": bson.M{"$lt": note.UpdatedAt}},
+			bson.M{"version": note.Version, "updated_at": note.UpdatedAt, "device_id": bson.M{"$lt": note.DeviceID}},
+		},
+	}
+
+	_, err = collection.ReplaceOne(ctx, filter, note, options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		var current NoteDao
+		if err := collection.FindOne(ctx, bson.M{"_id": note.ID, "user_id": note.UserID}).Decode(&current); err != nil {
+			return NoteDao{}, false, err
+		}
+		return current, false, nil
+	}
+	if err != nil {
+		return NoteDao{}, false, err
+	}
+
+	return note, true, nil
+}
+
+// Changes retorna até limit registros do usuário com seq maior que since e até
+// a seq confirmada: um registro com seq menor ainda pode estar sendo gravado
+func (dao SyncDao) Changes(ctx context.Context, userID bson.ObjectID, since int64, limit int) (SyncChanges, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	committed, err := dao.committedSeq(ctx, userID)
+	if err != nil {
+		return SyncChanges{}, err
+	}
+	if committed <= since {
+		return SyncChanges{}.Page(since, limit), nil
+	}
+
+	filter := bson.M{"user_id": userID, "seq": bson.M{"$gt": since, "$lte": committed}}
+	// limit+1 por collection: se sobrar algum, Page sabe que há mais
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}).SetLimit(int64(limit) + 1)
+
+	var changes SyncChanges
+	lists := []struct {
+		collection string
+		result     any
+	}{
+		{studySessionCollectionName, &changes.StudySessions},
+		{userProgressCollectionName, &changes.Progress},
+		{noteCollectionName, &changes.Notes},
+	}
+	for _, list := range lists {
+		cursor, err := database.DB.Collection(list.collection).Find(ctx, filter, opts)
+		if err != nil {
+			return SyncChanges{}, err
+		}
+		err = cursor.All(ctx, list.result)
+		cursor.Close(ctx)
+		if err != nil {
+			return SyncChanges{}, err
+		}
+	}
+
+	return changes.Page(since, limit), nil
+}
+
+// LatestSeq retorna a seq confirmada do usuário: todas as gravações até ela já terminaram
+func (dao SyncDao) LatestSeq(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return dao.committedSeq(ctx, userID)
+}
+
+// GetSyncState retorna o estado zerado quando o usuário nunca sincronizou
+func (dao SyncDao) GetSyncState(ctx context.Context, userID bson.ObjectID) (SyncStateDao, error) {
+	collection := database.DB.Collection(syncStateCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var state SyncStateDao
+	err := collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return SyncStateDao{UserID: userID}, nil
+	}
+	if err != nil {
+		return SyncStateDao{}, err
+	}
+
+	return state, nil
+}
+
+func (dao SyncDao) SaveSyncState(ctx context.Context, state SyncStateDao) error {
+	collection := database.DB.Collection(syncStateCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": state.UserID}, state, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"testing"
+	"time"
+)
+
+// Dois gravadores do mesmo usuário: A reserva a seq 1, B reserva a 2 e termina
+// primeiro. Enquanto A não terminar, Changes não pode passar da seq 0.
+func TestCommittedSeqWaitsForEarlierWriter(t *testing.T) {
+	now := time.Now()
+	lease := time.Minute
+
+	counter := syncCounterDao{}
+	reserve := func() int64 {
+		counter.Seq++
+		counter.Pending = append(counter.Pending, syncReservationDao{Seq: counter.Seq, At: now})
+		return counter.Seq
+	}
+	release := func(seq int64) {
+		for i, reservation := range counter.Pending {
+			if reservation.Seq == seq {
+				counter.Pending = append(counter.Pending[:i], counter.Pending[i+1:]...)
+				return
+			}
+		}
+	}
+
+	writerA := reserve()
+	writerB := reserve()
+	if got := counter.committedSeq(now, lease); got != 0 {
+		t.Fatalf("com as duas gravações em andamento a seq confirmada deve ser 0, foi %d", got)
+	}
+	release(writerB)
+	if got := counter.committedSeq(now, lease); got != 0 {
+		t.Fatalf("B terminou antes de A: a seq confirmada deve continuar 0, foi %d", got)
+	}
+	release(writerA)
+	if got := counter.committedSeq(now, lease); got != writerB {
+		t.Fatalf("com as duas gravações terminadas a seq confirmada deve ser %d, foi %d", writerB, got)
+	}
+}
+
+func TestCommittedSeqIgnoresExpiredReservation(t *testing.T) {
+	now := time.Now()
+	counter := syncCounterDao{Seq: 3, Pending: []syncReservationDao{
+		{Seq: 1, At: now.Add(-2 * time.Minute)}, // Processo que caiu no meio da gravação
+		{Seq: 3, At: now},
+	}}
+	if got := counter.committedSeq(now, time.Minute); got != 2 {
+		t.Fatalf("a reserva vencida não deve segurar a seq confirmada: esperado 2, foi %d", got)
+	}
+}
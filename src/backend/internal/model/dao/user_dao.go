@@ -6,99 +6,115 @@ import (
 	"fmt"
 	"log"
 	"metabee/internal/database"
-	"regexp"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserDao struct {
-	ID             bson.ObjectID `bson:"_id,omitempty"`
-	Name           string        `bson:"name"`
-	Email          string        `bson:"email"`
-	Password       string        `bson:"password"`
-	Bio            string        `bson:"bio,omitempty"`         // Biografia do usuário
-	Location       string        `bson:"location,omitempty"`    // Local onde reside
-	Avatar         bson.Binary   `bson:"avatar,omitempty"`      // Foto de perfil (binário)
-	AvatarMimeType string        `bson:"avatar_mime_type,omitempty"` // Tipo MIME da imagem
-	CreatedAt      time.Time     `bson:"created_at"`
-	UpdatedAt      time.Time     `bson:"updated_at,omitempty"`
+	ID               bson.ObjectID `bson:"_id,omitempty"`
+	Name             string        `bson:"name"`
+	Email            string        `bson:"email"`
+	Password         string        `bson:"password"`
+	Bio              string        `bson:"bio,omitempty"`                // Biografia do usuário
+	Location         string        `bson:"location,omitempty"`           // Local onde reside
+	Avatar           bson.Binary   `bson:"avatar,omitempty"`             // Foto de perfil (binário)
+	AvatarMimeType   string        `bson:"avatar_mime_type,omitempty"`   // Tipo MIME da imagem
+	TokensValidAfter time.Time     `bson:"tokens_valid_after,omitempty"` // Tokens emitidos antes desta data são rejeitados
+	EmailUnverified  bool          `bson:"email_unverified,omitempty"`   // Contas antigas (sem o campo) são consideradas verificadas
+	EmailVerifiedAt  time.Time     `bson:"email_verified_at,omitempty"`
+	Role             string        `bson:"role,omitempty"` // admin, instructor ou student (vazio = student)
+	MFAEnabled       bool          `bson:"mfa_enabled,omitempty"`
+	MFASecret        string        `bson:"mfa_secret,omitempty"`         // Segredo TOTP (base32)
+	MFAPendingSecret string        `bson:"mfa_pending_secret,omitempty"` // Segredo aguardando confirmação do cadastro
+	MFARecoveryCodes []string      `bson:"mfa_recovery_codes,omitempty"` // Hashes dos códigos de recuperação ainda não usados
+	MFALastStep      int64         `bson:"mfa_last_step,omitempty"`      // Último passo TOTP aceito (impede reuso do código)
+	GoogleSubject    string        `bson:"google_sub,omitempty"`         // Claim "sub" da conta Google vinculada
+	CreatedAt        time.Time     `bson:"created_at"`
+	UpdatedAt        time.Time     `bson:"updated_at,omitempty"`
 }
 
-type LoginInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+// Papéis de usuário usados no controle de acesso
+const (
+	RoleAdmin      = "admin"
+	RoleInstructor = "instructor"
+	RoleStudent    = "student"
+)
+
+// IsValidRole verifica se o papel informado é conhecido
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleInstructor || role == RoleStudent
 }
 
-const userCollectionName = "user"
+// EffectiveRole retorna o papel do usuário, considerando contas sem papel como student
+func (dao UserDao) EffectiveRole() string {
+	if dao.Role == "" {
+		return RoleStudent
+	}
+	return dao.Role
+}
 
-func (dao UserDao) HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+// IsStaff indica se o usuário gerencia conteúdo (cursos, notícias)
+func (dao UserDao) IsStaff() bool {
+	role := dao.EffectiveRole()
+	return role == RoleAdmin || role == RoleInstructor
 }
 
-func (dao UserDao) CheckPasswordHash(password, hash string) bool {
-	if hash == "" {
-		return false
-	}
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	if err != nil {
-		log.Printf("❌ Erro ao comparar senha: %v (hash length: %d, password length: %d)", err, len(hash), len(password))
-		return false
-	}
-	return true
+// IsEmailVerified indica se o email do usuário foi confirmado
+func (dao UserDao) IsEmailVerified() bool {
+	return !dao.EmailUnverified
 }
 
-func (dao UserDao) FindUserByEmail(email string) (UserDao, error) {
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+const userCollectionName = "user"
+
+func (dao UserDao) FindUserByEmail(ctx context.Context, email string) (UserDao, error) {
 	collection := database.DB.Collection(userCollectionName)
 
-	// Normalizar email: trim e lowercase
-	normalizedEmail := strings.TrimSpace(strings.ToLower(email))
+	// Emails são gravados normalizados (migração 2), então a busca exata usa o índice único
+	normalizedEmail := NormalizeEmail(email)
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	log.Printf("🔍 Buscando usuário com email: '%s' (normalizado: '%s')", email, normalizedEmail)
 
 	var user UserDao
 	
-	// Primeiro tentar busca exata (case-insensitive com regex)
-	escapedEmail := regexp.QuoteMeta(normalizedEmail)
-	filter := bson.M{"email": bson.M{"$regex": "^" + escapedEmail + "$", "$options": "i"}}
-	
 	// Usar projection para excluir o campo avatar se causar problemas
 	opts := options.FindOne().SetProjection(bson.M{
 		"avatar": 0, // Excluir avatar da busca para evitar problemas de decodificação
 	})
 	
-	err := collection.FindOne(ctx, filter, opts).Decode(&user)
+	err := collection.FindOne(ctx, bson.M{"email": normalizedEmail}, opts).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			// Se não encontrou, tentar busca sem normalização (pode ter sido salvo com maiúsculas)
-			log.Printf("⚠️  Busca normalizada falhou, tentando busca direta...")
-			filter2 := bson.M{"email": email}
-			err2 := collection.FindOne(ctx, filter2, opts).Decode(&user)
-			if err2 != nil {
-				log.Printf("❌ Usuário não encontrado. Email buscado: '%s' (normalizado: '%s')", email, normalizedEmail)
-				return UserDao{}, fmt.Errorf("usuário com email %s não encontrado", email)
-			}
-		} else {
-			log.Printf("❌ Erro ao buscar usuário: %v", err)
-			return UserDao{}, fmt.Errorf("erro ao buscar usuário: %v", err)
+			log.Printf("❌ Usuário não encontrado. Email buscado: '%s' (normalizado: '%s')", email, normalizedEmail)
+			return UserDao{}, fmt.Errorf("usuário com email %s não encontrado", email)
 		}
+		log.Printf("❌ Erro ao buscar usuário: %v", err)
+		return UserDao{}, fmt.Errorf("erro ao buscar usuário: %v", err)
 	}
 
 	log.Printf("✅ Usuário encontrado: email no banco='%s', email buscado='%s'", user.Email, normalizedEmail)
 	return user, nil
 }
 
-func (dao UserDao) CreateUser(user UserDao) (UserDao, error) {
+// NormalizeEmail retorna o email como é gravado: sem espaços nas pontas e em minúsculas
+func NormalizeEmail(email string) string {
+	return strings.TrimSpace(strings.ToLower(email))
+}
+
+func (dao UserDao) CreateUser(ctx context.Context, user UserDao) (UserDao, error) {
 	// Verificar se o usuário já existe
-	_, err := dao.FindUserByEmail(user.Email)
+	_, err := dao.FindUserByEmail(ctx, user.Email)
 	if err == nil {
 		// Se não retornou erro, significa que encontrou um usuário
 		return UserDao{}, errors.New("usuário com este email já existe")
@@ -110,15 +126,16 @@ func (dao UserDao) CreateUser(user UserDao) (UserDao, error) {
 	log.Printf("Criando novo usuário com ObjectID: %s (tipo: %T)", newUserID.Hex(), newUserID)
 	
 	newUser := UserDao{
-		ID:        newUserID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Password:  user.Password, // Usar a senha já hasheada que veio do controller
-		CreatedAt: time.Now(),
+		ID:              newUserID,
+		Name:            user.Name,
+		Email:           NormalizeEmail(user.Email),
+		Password:        user.Password, // Usar a senha já hasheada que veio do controller
+		EmailUnverified: true,
+		CreatedAt:       time.Now(),
 	}
 
 	collection := database.DB.Collection(userCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	// Forçar ObjectId explicitamente usando bson.D para garantir o tipo correto
@@ -127,10 +144,15 @@ func (dao UserDao) CreateUser(user UserDao) (UserDao, error) {
 		{Key: "name", Value: newUser.Name},
 		{Key: "email", Value: newUser.Email},
 		{Key: "password", Value: newUser.Password},
+		{Key: "email_unverified", Value: newUser.EmailUnverified},
 		{Key: "created_at", Value: newUser.CreatedAt},
 	}
 
 	result, err := collection.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		// Cadastro concorrente com o mesmo email (índice único de email)
+		return UserDao{}, errors.New("usuário com este email já existe")
+	}
 	if err != nil {
 		return UserDao{}, fmt.Errorf("falha ao inserir usuário: %v", err)
 	}
@@ -147,7 +169,7 @@ func (dao UserDao) CreateUser(user UserDao) (UserDao, error) {
 	return newUser, nil
 }
 
-func (dao *UserDao) FindUserByID(idString string) (UserDao, error) {
+func (dao *UserDao) FindUserByID(ctx context.Context, idString string) (UserDao, error) {
 	objID, err := bson.ObjectIDFromHex(idString)
 	if err != nil {
 		log.Printf("❌ Erro ao converter ID para ObjectID: %s - %v", idString, err)
@@ -156,7 +178,7 @@ func (dao *UserDao) FindUserByID(idString string) (UserDao, error) {
 
 	filter := bson.M{"_id": objID}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	collection := database.DB.Collection(userCollectionName)
@@ -183,9 +205,9 @@ func (dao *UserDao) FindUserByID(idString string) (UserDao, error) {
 }
 
 // HasAvatar verifica se o usuário tem avatar sem decodificar o binário
-func (dao *UserDao) HasAvatar(userID bson.ObjectID) bool {
+func (dao *UserDao) HasAvatar(ctx context.Context, userID bson.ObjectID) bool {
 	collection := database.DB.Collection(userCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	// Buscar apenas o campo avatar sem decodificar
@@ -201,9 +223,9 @@ func (dao *UserDao) HasAvatar(userID bson.ObjectID) bool {
 }
 
 // GetUserAvatar retorna o avatar do usuário
-func (dao *UserDao) GetUserAvatar(userID bson.ObjectID) (bson.Binary, string, error) {
+func (dao *UserDao) GetUserAvatar(ctx context.Context, userID bson.ObjectID) (bson.Binary, string, error) {
 	collection := database.DB.Collection(userCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	// Buscar apenas avatar e avatar_mime_type
@@ -227,9 +249,9 @@ func (dao *UserDao) GetUserAvatar(userID bson.ObjectID) (bson.Binary, string, er
 }
 
 // GetUserProfile retorna os dados do perfil do usuário (sem senha)
-func (dao *UserDao) GetUserProfile(userID bson.ObjectID) (UserDao, error) {
+func (dao *UserDao) GetUserProfile(ctx context.Context, userID bson.ObjectID) (UserDao, error) {
 	collection := database.DB.Collection(userCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	// Usar projection para excluir o campo avatar e password para evitar problemas de decodificação
@@ -250,7 +272,7 @@ func (dao *UserDao) GetUserProfile(userID bson.ObjectID) (UserDao, error) {
 	}
 
 	// Verificar se o avatar existe usando método auxiliar
-	hasAvatar := dao.HasAvatar(userID)
+	hasAvatar := dao.HasAvatar(ctx, userID)
 	if hasAvatar {
 		// Inicializar Avatar vazio - o controller verificará usando HasAvatar
 		user.Avatar = bson.Binary{Subtype: 0, Data: []byte{}}
@@ -261,9 +283,9 @@ func (dao *UserDao) GetUserProfile(userID bson.ObjectID) (UserDao, error) {
 }
 
 // UpdateUserProfile atualiza os dados do perfil do usuário
-func (dao *UserDao) UpdateUserProfile(userID bson.ObjectID, updates bson.M) error {
+func (dao *UserDao) UpdateUserProfile(ctx context.Context, userID bson.ObjectID, updates bson.M) error {
 	collection := database.DB.Collection(userCollectionName)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	// Adicionar updated_at
@@ -284,3 +306,97 @@ func (dao *UserDao) UpdateUserProfile(userID bson.ObjectID, updates bson.M) erro
 
 	return nil
 }
+
+// FindUserByGoogleSubject busca o usuário vinculado à conta Google (claim "sub")
+func (dao *UserDao) FindUserByGoogleSubject(ctx context.Context, subject string) (UserDao, error) {
+	collection := database.DB.Collection(userCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	opts := options.FindOne().SetProjection(bson.M{"avatar": 0})
+
+	var user UserDao
+	err := collection.FindOne(ctx, bson.M{"google_sub": subject}, opts).Decode(&user)
+	if err != nil {
+		return UserDao{}, err
+	}
+
+	return user, nil
+}
+
+// ReplacePasswordHash troca o hash da senha apenas se ele ainda for currentHash,
+// para não sobrescrever uma troca de senha feita em paralelo
+func (dao *UserDao) ReplacePasswordHash(ctx context.Context, userID bson.ObjectID, currentHash, newHash string) (bool, error) {
+	collection := database.DB.Collection(userCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":      userID,
+		"password": currentHash,
+	}
+	update := bson.M{
+		"$set": bson.M{"password": newHash, "updated_at": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// RecordMFAStep registra o passo TOTP usado, falhando se ele (ou um posterior) já tiver sido usado
+func (dao *UserDao) RecordMFAStep(ctx context.Context, userID bson.ObjectID, step int64) (bool, error) {
+	collection := database.DB.Collection(userCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id": userID,
+		"$or": bson.A{
+			bson.M{"mfa_last_step": bson.M{"$lt": step}},
+			bson.M{"mfa_last_step": bson.M{"$exists": false}},
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"mfa_last_step": step}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// ConsumeRecoveryCode remove o código de recuperação (hash) do usuário, garantindo uso único
+func (dao *UserDao) ConsumeRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) (bool, error) {
+	collection := database.DB.Collection(userCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":                userID,
+		"mfa_recovery_codes": codeHash,
+	}
+	update := bson.M{
+		"$pull": bson.M{"mfa_recovery_codes": codeHash},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// CountUsers retorna o total de usuários cadastrados
+func (dao *UserDao) CountUsers(ctx context.Context) (int64, error) {
+	collection := database.DB.Collection(userCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return collection.CountDocuments(ctx, bson.M{})
+}
@@ -16,6 +16,7 @@ type UserProgressDao struct {
 	Progress  float64           `bson:"progress"` // 0-100
 	Hours     float64           `bson:"hours"`    // horas estudadas
 	LastStudy time.Time         `bson:"last_study"`
+	Seq       int64             `bson:"seq,omitempty"` // Sequência de sincronização da última alteração (ver SyncDao)
 	CreatedAt time.Time         `bson:"created_at"`
 	UpdatedAt time.Time         `bson:"updated_at"`
 }
@@ -27,17 +28,35 @@ type StudySessionDao struct {
 	LessonID  bson.ObjectID `bson:"lesson_id,omitempty"`
 	Duration  float64           `bson:"duration"` // em horas
 	Date      time.Time         `bson:"date"`
+	Seq       int64             `bson:"seq,omitempty"` // Sequência de sincronização (ver SyncDao)
 	CreatedAt time.Time         `bson:"created_at"`
 }
 
+// Merge combina o progresso recebido de outro dispositivo com este, campo a
+// campo pelo maior valor: o resultado não depende da ordem em que os
+// dispositivos sincronizam. Retorna false quando nada muda. Hours não entra:
+// as horas são a soma das sessões de estudo sincronizadas, e o maior valor
+// perderia as horas estudadas offline em outro dispositivo.
+func (dao UserProgressDao) Merge(incoming UserProgressDao) (UserProgressDao, bool) {
+	merged := dao
+	if incoming.Progress > merged.Progress {
+		merged.Progress = incoming.Progress
+	}
+	if incoming.LastStudy.After(merged.LastStudy) {
+		merged.LastStudy = incoming.LastStudy
+	}
+	changed := merged.Progress != dao.Progress || !merged.LastStudy.Equal(dao.LastStudy)
+	return merged, changed
+}
+
 const userProgressCollectionName = "user_progress"
 const studySessionCollectionName = "study_session"
 
 // GetActiveCourses retorna os cursos ativos do usuário (com progresso > 0 e < 100)
-func (dao UserProgressDao) GetActiveCourses(userID bson.ObjectID) ([]UserProgressDao, error) {
+func (dao UserProgressDao) GetActiveCourses(ctx context.Context, userID bson.ObjectID) ([]UserProgressDao, error) {
 	collection := database.DB.Collection(userProgressCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	filter := bson.M{
@@ -63,10 +82,10 @@ func (dao UserProgressDao) GetActiveCourses(userID bson.ObjectID) ([]UserProgres
 }
 
 // GetHoursThisMonth retorna as horas estudadas no mês atual
-func (dao UserProgressDao) GetHoursThisMonth(userID bson.ObjectID) (float64, error) {
+func (dao UserProgressDao) GetHoursThisMonth(ctx context.Context, userID bson.ObjectID) (float64, error) {
 	collection := database.DB.Collection(studySessionCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	now := time.Now()
@@ -112,10 +131,10 @@ func (dao UserProgressDao) GetHoursThisMonth(userID bson.ObjectID) (float64, err
 }
 
 // GetAverageProgress retorna o progresso médio de todos os cursos do usuário
-func (dao UserProgressDao) GetAverageProgress(userID bson.ObjectID) (float64, error) {
+func (dao UserProgressDao) GetAverageProgress(ctx context.Context, userID bson.ObjectID) (float64, error) {
 	collection := database.DB.Collection(userProgressCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	pipeline := []bson.M{
@@ -154,11 +173,11 @@ func (dao UserProgressDao) GetAverageProgress(userID bson.ObjectID) (float64, er
 	return 0, nil
 }
 
-// GetRecentLessons retorna as aulas recentes do usuário
-func GetRecentLessons(userID bson.ObjectID, limit int) ([]StudySessionDao, error) {
+// GetRecentLessons retorna as sessões de estudo mais recentes do usuário
+func (dao UserProgressDao) GetRecentLessons(ctx context.Context, userID bson.ObjectID, limit int) ([]StudySessionDao, error) {
 	collection := database.DB.Collection(studySessionCollectionName)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := database.WithQueryTimeout(ctx)
 	defer cancel()
 
 	opts := options.Find().
@@ -180,3 +199,79 @@ func GetRecentLessons(userID bson.ObjectID, limit int) ([]StudySessionDao, error
 	return sessions, nil
 }
 
+// SaveProgress grava o progresso do usuário no curso, criando o registro se ainda não existir
+func (dao UserProgressDao) SaveProgress(ctx context.Context, progress UserProgressDao) (UserProgressDao, error) {
+	collection := database.DB.Collection(userProgressCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"user_id": progress.UserID, "course_id": progress.CourseID}
+	update := bson.M{
+		"$set": bson.M{
+			"progress":   progress.Progress,
+			"hours":      progress.Hours,
+			"last_study": progress.LastStudy,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved UserProgressDao
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&saved); err != nil {
+		return UserProgressDao{}, err
+	}
+
+	return saved, nil
+}
+
+// EnsureProgress cria o progresso zerado do usuário no curso se ele ainda não
+// existir e retorna o registro atual
+func (dao UserProgressDao) EnsureProgress(ctx context.Context, userID, courseID bson.ObjectID) (UserProgressDao, error) {
+	collection := database.DB.Collection(userProgressCollectionName)
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"user_id": userID, "course_id": courseID}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"progress":   0.0,
+			"hours":      0.0,
+			"last_study": time.Time{},
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var progress UserProgressDao
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&progress); err != nil {
+		return UserProgressDao{}, err
+	}
+
+	return progress, nil
+}
+
+// CreateStudySession registra uma sessão de estudo
+func (dao UserProgressDao) CreateStudySession(ctx context.Context, session StudySessionDao) (StudySessionDao, error) {
+	session.ID = bson.NewObjectID()
+	session.CreatedAt = time.Now()
+	if session.Date.IsZero() {
+		session.Date = session.CreatedAt
+	}
+
+	collection := database.DB.Collection(studySessionCollectionName)
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := collection.InsertOne(ctx, session)
+	if err != nil {
+		return StudySessionDao{}, err
+	}
+
+	return session, nil
+}
@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+// Registros trocados em /metabee/sync. IDs em hexadecimal; Seq é preenchida
+// pelo servidor e ignorada nos envios.
+
+type SyncStudySession struct {
+	ID       string    `json:"id"` // Gerado no dispositivo: reenviar a mesma sessão não a duplica
+	CourseID string    `json:"course_id"`
+	LessonID string    `json:"lesson_id,omitempty"`
+	Duration float64   `json:"duration"` // em horas
+	Date     time.Time `json:"date"`
+	Seq      int64     `json:"seq,omitempty"`
+}
+
+// SyncProgress é combinado campo a campo pelo maior valor
+type SyncProgress struct {
+	CourseID  string    `json:"course_id"`
+	Progress  float64   `json:"progress"` // 0-100
+	Hours     float64   `json:"hours"`
+	LastStudy time.Time `json:"last_study"`
+	Seq       int64     `json:"seq,omitempty"`
+}
+
+// SyncNote vence pela maior version; empate, pelo updated_at e depois pelo device_id
+type SyncNote struct {
+	ID        string    `json:"id"` // Gerado no dispositivo
+	CourseID  string    `json:"course_id"`
+	LessonID  string    `json:"lesson_id,omitempty"`
+	Content   string    `json:"content"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	Version   int64     `json:"version"` // Incrementada pelo dispositivo a cada edição
+	DeviceID  string    `json:"device_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Seq       int64     `json:"seq,omitempty"`
+}
+
+type SyncBatch struct {
+	StudySessions []SyncStudySession `json:"study_sessions"`
+	Progress      []SyncProgress     `json:"progress"`
+	Notes         []SyncNote         `json:"notes"`
+}
+
+// SyncChanges é a resposta de GET /metabee/sync/changes
+type SyncChanges struct {
+	SyncBatch
+	Next int64 `json:"next"` // Valor de since para a próxima página
+	More bool  `json:"more"`
+}
+
+// SyncPushResult é a resposta de POST /metabee/sync/push. Current traz a versão
+// vigente dos registros em que o servidor ficou com um valor diferente do enviado.
+type SyncPushResult struct {
+	Applied   int       `json:"applied"`
+	Unchanged int       `json:"unchanged"`
+	Current   SyncBatch `json:"current"`
+}
+
+// SyncRunResult é a resposta de POST /metabee/sync/run
+type SyncRunResult struct {
+	Pushed int   `json:"pushed"` // Registros locais enviados ao servidor central
+	Pulled int   `json:"pulled"` // Registros recebidos do servidor central
+	Seq    int64 `json:"seq"`    // Última seq do servidor central recebida
+}
@@ -0,0 +1,122 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type passwordResetStore struct {
+	resets collection[dao.PasswordResetDao]
+}
+
+func newPasswordResetStore(db *bbolt.DB) *passwordResetStore {
+	return &passwordResetStore{resets: newCollection(db, passwordResetBucket, func(reset dao.PasswordResetDao) bson.ObjectID { return reset.ID })}
+}
+
+func (s *passwordResetStore) CreatePasswordReset(ctx context.Context, reset dao.PasswordResetDao) (dao.PasswordResetDao, error) {
+	reset.ID = bson.NewObjectID()
+	reset.CreatedAt = time.Now()
+
+	if err := s.resets.insert(ctx, reset); err != nil {
+		return dao.PasswordResetDao{}, err
+	}
+	return reset, nil
+}
+
+func (s *passwordResetStore) FindByHash(ctx context.Context, tokenHash string) (dao.PasswordResetDao, error) {
+	return s.resets.findOne(ctx, func(reset dao.PasswordResetDao) bool { return reset.TokenHash == tokenHash })
+}
+
+func (s *passwordResetStore) MarkUsed(ctx context.Context, resetID bson.ObjectID) error {
+	updated, err := s.resets.updateID(ctx, resetID, func(reset *dao.PasswordResetDao) bool {
+		if !reset.UsedAt.IsZero() {
+			return false
+		}
+		reset.UsedAt = time.Now()
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return dao.ErrPasswordResetAlreadyUsed
+	}
+	return nil
+}
+
+func (s *passwordResetStore) Release(ctx context.Context, resetID bson.ObjectID) error {
+	_, err := s.resets.updateID(ctx, resetID, func(reset *dao.PasswordResetDao) bool {
+		reset.UsedAt = time.Time{}
+		return true
+	})
+	return err
+}
+
+func (s *passwordResetStore) InvalidateForUser(ctx context.Context, userID bson.ObjectID) error {
+	now := time.Now()
+	_, err := s.resets.updateWhere(ctx, func(reset *dao.PasswordResetDao) bool {
+		if reset.UserID != userID || !reset.UsedAt.IsZero() {
+			return false
+		}
+		reset.UsedAt = now
+		return true
+	})
+	return err
+}
+
+type emailVerificationStore struct {
+	verifications collection[dao.EmailVerificationDao]
+}
+
+func newEmailVerificationStore(db *bbolt.DB) *emailVerificationStore {
+	return &emailVerificationStore{verifications: newCollection(db, emailVerificationBucket, func(verification dao.EmailVerificationDao) bson.ObjectID { return verification.ID })}
+}
+
+func (s *emailVerificationStore) CreateEmailVerification(ctx context.Context, verification dao.EmailVerificationDao) (dao.EmailVerificationDao, error) {
+	verification.ID = bson.NewObjectID()
+	verification.CreatedAt = time.Now()
+
+	if err := s.verifications.insert(ctx, verification); err != nil {
+		return dao.EmailVerificationDao{}, err
+	}
+	return verification, nil
+}
+
+func (s *emailVerificationStore) FindByHash(ctx context.Context, tokenHash string) (dao.EmailVerificationDao, error) {
+	return s.verifications.findOne(ctx, func(verification dao.EmailVerificationDao) bool {
+		return verification.TokenHash == tokenHash
+	})
+}
+
+func (s *emailVerificationStore) MarkUsed(ctx context.Context, verificationID bson.ObjectID) error {
+	updated, err := s.verifications.updateID(ctx, verificationID, func(verification *dao.EmailVerificationDao) bool {
+		if !verification.UsedAt.IsZero() {
+			return false
+		}
+		verification.UsedAt = time.Now()
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return dao.ErrEmailVerificationAlreadyUsed
+	}
+	return nil
+}
+
+func (s *emailVerificationStore) InvalidateForUser(ctx context.Context, userID bson.ObjectID, purpose string) error {
+	now := time.Now()
+	_, err := s.verifications.updateWhere(ctx, func(verification *dao.EmailVerificationDao) bool {
+		if verification.UserID != userID || verification.EffectivePurpose() != purpose || !verification.UsedAt.IsZero() {
+			return false
+		}
+		verification.UsedAt = now
+		return true
+	})
+	return err
+}
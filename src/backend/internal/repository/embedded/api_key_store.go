@@ -0,0 +1,98 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type apiKeyStore struct {
+	keys collection[dao.APIKeyDao]
+}
+
+func newAPIKeyStore(db *bbolt.DB) *apiKeyStore {
+	return &apiKeyStore{keys: newCollection(db, apiKeyBucket, func(key dao.APIKeyDao) bson.ObjectID { return key.ID })}
+}
+
+func (s *apiKeyStore) CreateAPIKey(ctx context.Context, key dao.APIKeyDao) (dao.APIKeyDao, error) {
+	key.ID = bson.NewObjectID()
+	key.CreatedAt = time.Now()
+
+	if err := s.keys.insert(ctx, key); err != nil {
+		return dao.APIKeyDao{}, err
+	}
+	return key, nil
+}
+
+func (s *apiKeyStore) FindByHash(ctx context.Context, keyHash string) (dao.APIKeyDao, error) {
+	return s.keys.findOne(ctx, func(key dao.APIKeyDao) bool { return key.KeyHash == keyHash })
+}
+
+func (s *apiKeyStore) FindByUser(ctx context.Context, userID bson.ObjectID) ([]dao.APIKeyDao, error) {
+	keys, err := s.keys.find(ctx, func(key dao.APIKeyDao) bool {
+		return key.UserID == userID && key.RevokedAt.IsZero()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	for i := range keys {
+		keys[i].KeyHash = ""
+	}
+	return keys, nil
+}
+
+func (s *apiKeyStore) Revoke(ctx context.Context, keyID, userID bson.ObjectID) (bool, error) {
+	return s.keys.updateID(ctx, keyID, func(key *dao.APIKeyDao) bool {
+		if key.UserID != userID || !key.RevokedAt.IsZero() {
+			return false
+		}
+		key.RevokedAt = time.Now()
+		return true
+	})
+}
+
+func (s *apiKeyStore) RevokeAllForUser(ctx context.Context, userID bson.ObjectID) error {
+	now := time.Now()
+	_, err := s.keys.updateWhere(ctx, func(key *dao.APIKeyDao) bool {
+		if key.UserID != userID || !key.RevokedAt.IsZero() {
+			return false
+		}
+		key.RevokedAt = now
+		return true
+	})
+	return err
+}
+
+func (s *apiKeyStore) TouchIfIdle(ctx context.Context, keyID bson.ObjectID, ip string, idleSince time.Time) error {
+	_, err := s.keys.updateID(ctx, keyID, func(key *dao.APIKeyDao) bool {
+		if !key.LastUsedAt.IsZero() && !key.LastUsedAt.Before(idleSince) {
+			return false
+		}
+		key.LastUsedAt = time.Now()
+		key.LastUsedIP = ip
+		return true
+	})
+	return err
+}
+
+type auditLogStore struct {
+	entries collection[dao.AuditLogDao]
+}
+
+func newAuditLogStore(db *bbolt.DB) *auditLogStore {
+	return &auditLogStore{entries: newCollection(db, auditLogBucket, func(entry dao.AuditLogDao) bson.ObjectID { return entry.ID })}
+}
+
+func (s *auditLogStore) CreateAuditLog(ctx context.Context, entry dao.AuditLogDao) error {
+	entry.ID = bson.NewObjectID()
+	entry.CreatedAt = time.Now()
+	return s.entries.insert(ctx, entry)
+}
@@ -0,0 +1,282 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// collection guarda documentos do tipo T em um bucket, com o ObjectID como
+// chave e o documento em BSON como valor. Cada operação roda em uma única
+// transação do bbolt, então as escritas com filtro (o equivalente ao UpdateOne
+// com filtro do MongoDB) são atômicas.
+type collection[T any] struct {
+	db   *bbolt.DB
+	name []byte
+	id   func(T) bson.ObjectID
+	tx   *bbolt.Tx // Transação da unidade de trabalho (inTx); nil: uma transação por operação
+}
+
+func newCollection[T any](db *bbolt.DB, name string, id func(T) bson.ObjectID) collection[T] {
+	return collection[T]{db: db, name: []byte(name), id: id}
+}
+
+// inTx retorna a collection operando dentro de tx, que precisa ser de escrita
+func (c collection[T]) inTx(tx *bbolt.Tx) collection[T] {
+	c.tx = tx
+	return c
+}
+
+func (c collection[T]) view(ctx context.Context, fn func(bucket *bbolt.Bucket) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.tx != nil {
+		return fn(c.tx.Bucket(c.name))
+	}
+	return c.db.View(func(tx *bbolt.Tx) error {
+		return fn(tx.Bucket(c.name))
+	})
+}
+
+func (c collection[T]) update(ctx context.Context, fn func(bucket *bbolt.Bucket) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.tx != nil {
+		return fn(c.tx.Bucket(c.name))
+	}
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return fn(tx.Bucket(c.name))
+	})
+}
+
+// decode copia o valor antes de decodificar: os bytes do bbolt só valem durante a transação
+func (c collection[T]) decode(raw []byte) (T, error) {
+	var document T
+	err := bson.Unmarshal(append([]byte(nil), raw...), &document)
+	return document, err
+}
+
+func (c collection[T]) put(bucket *bbolt.Bucket, document T) error {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	id := c.id(document)
+	return bucket.Put(id[:], raw)
+}
+
+// errStopScan interrompe o ForEach de scan; não chega ao chamador
+var errStopScan = errors.New("busca interrompida")
+
+// scan decodifica os documentos na ordem das chaves (a ordem de criação dos ObjectIDs)
+// até visit retornar false
+func (c collection[T]) scan(bucket *bbolt.Bucket, visit func(document T) bool) error {
+	err := bucket.ForEach(func(_, raw []byte) error {
+		document, err := c.decode(raw)
+		if err != nil {
+			return err
+		}
+		if !visit(document) {
+			return errStopScan
+		}
+		return nil
+	})
+	if err == errStopScan {
+		return nil
+	}
+	return err
+}
+
+// get busca pelo ID; retorna mongo.ErrNoDocuments quando ele não existe
+func (c collection[T]) get(ctx context.Context, id bson.ObjectID) (T, error) {
+	var document T
+	err := c.view(ctx, func(bucket *bbolt.Bucket) error {
+		raw := bucket.Get(id[:])
+		if raw == nil {
+			return mongo.ErrNoDocuments
+		}
+		var err error
+		document, err = c.decode(raw)
+		return err
+	})
+	return document, err
+}
+
+// find retorna os documentos em que match é verdadeiro (nunca nil, como cursor.All)
+func (c collection[T]) find(ctx context.Context, match func(T) bool) ([]T, error) {
+	documents := []T{}
+	err := c.view(ctx, func(bucket *bbolt.Bucket) error {
+		return c.scan(bucket, func(document T) bool {
+			if match(document) {
+				documents = append(documents, document)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// findOne retorna o primeiro documento em que match é verdadeiro ou mongo.ErrNoDocuments
+func (c collection[T]) findOne(ctx context.Context, match func(T) bool) (T, error) {
+	var found T
+	err := c.view(ctx, func(bucket *bbolt.Bucket) error {
+		var matched bool
+		if err := c.scan(bucket, func(document T) bool {
+			if match(document) {
+				found, matched = document, true
+			}
+			return !matched
+		}); err != nil {
+			return err
+		}
+		if !matched {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (c collection[T]) exists(ctx context.Context, match func(T) bool) (bool, error) {
+	_, err := c.findOne(ctx, match)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c collection[T]) count(ctx context.Context) (int64, error) {
+	var total int64
+	err := c.view(ctx, func(bucket *bbolt.Bucket) error {
+		return bucket.ForEach(func(_, _ []byte) error {
+			total++
+			return nil
+		})
+	})
+	return total, err
+}
+
+func (c collection[T]) insert(ctx context.Context, document T) error {
+	return c.update(ctx, func(bucket *bbolt.Bucket) error {
+		return c.put(bucket, document)
+	})
+}
+
+// insertUnless insere o documento apenas se nenhum outro satisfizer conflicts,
+// como um índice único; retorna false quando há conflito
+func (c collection[T]) insertUnless(ctx context.Context, document T, conflicts func(T) bool) (bool, error) {
+	inserted := false
+	err := c.update(ctx, func(bucket *bbolt.Bucket) error {
+		conflict := false
+		if err := c.scan(bucket, func(existing T) bool {
+			conflict = conflicts(existing)
+			return !conflict
+		}); err != nil || conflict {
+			return err
+		}
+		inserted = true
+		return c.put(bucket, document)
+	})
+	return inserted && err == nil, err
+}
+
+// updateID aplica apply ao documento do ID; retorna false se ele não existir ou
+// apply retornar false (nada é gravado, como um filtro sem match)
+func (c collection[T]) updateID(ctx context.Context, id bson.ObjectID, apply func(document *T) bool) (bool, error) {
+	updated := false
+	err := c.update(ctx, func(bucket *bbolt.Bucket) error {
+		raw := bucket.Get(id[:])
+		if raw == nil {
+			return nil
+		}
+		document, err := c.decode(raw)
+		if err != nil {
+			return err
+		}
+		if !apply(&document) {
+			return nil
+		}
+		updated = true
+		return c.put(bucket, document)
+	})
+	return updated && err == nil, err
+}
+
+// updateWhere aplica apply a todos os documentos, como um UpdateMany; apply
+// retorna false para os que não casam com o filtro. Retorna quantos foram alterados.
+func (c collection[T]) updateWhere(ctx context.Context, apply func(document *T) bool) (int, error) {
+	var changed []T
+	err := c.update(ctx, func(bucket *bbolt.Bucket) error {
+		if err := c.scan(bucket, func(document T) bool {
+			if apply(&document) {
+				changed = append(changed, document)
+			}
+			return true
+		}); err != nil {
+			return err
+		}
+		// O bbolt não permite alterar o bucket durante o ForEach
+		for _, document := range changed {
+			if err := c.put(bucket, document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+// upsert aplica apply ao primeiro documento em que match é verdadeiro ou, sem
+// nenhum, a um documento novo (found false), que apply deve preencher com o ID.
+// Retorna o documento gravado.
+func (c collection[T]) upsert(ctx context.Context, match func(T) bool, apply func(document *T, found bool)) (T, error) {
+	var saved T
+	err := c.update(ctx, func(bucket *bbolt.Bucket) error {
+		found := false
+		if err := c.scan(bucket, func(document T) bool {
+			if match(document) {
+				saved, found = document, true
+			}
+			return !found
+		}); err != nil {
+			return err
+		}
+		apply(&saved, found)
+		return c.put(bucket, saved)
+	})
+	return saved, err
+}
+
+// remove apaga os documentos em que match é verdadeiro e os retorna
+func (c collection[T]) remove(ctx context.Context, match func(T) bool) ([]T, error) {
+	var removed []T
+	err := c.update(ctx, func(bucket *bbolt.Bucket) error {
+		if err := c.scan(bucket, func(document T) bool {
+			if match(document) {
+				removed = append(removed, document)
+			}
+			return true
+		}); err != nil {
+			return err
+		}
+		for _, document := range removed {
+			id := c.id(document)
+			if err := bucket.Delete(id[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,62 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type courseStore struct {
+	courses collection[dao.CourseDao]
+}
+
+func newCourseStore(db *bbolt.DB) *courseStore {
+	return &courseStore{courses: newCollection(db, courseBucket, func(course dao.CourseDao) bson.ObjectID { return course.ID })}
+}
+
+func (s *courseStore) GetAllCourses(ctx context.Context) ([]dao.CourseDao, error) {
+	return s.courses.find(ctx, func(dao.CourseDao) bool { return true })
+}
+
+func (s *courseStore) GetLastTwoCourses(ctx context.Context) ([]dao.CourseDao, error) {
+	courses, err := s.GetAllCourses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(courses, func(i, j int) bool {
+		return courses[i].CreatedAt.After(courses[j].CreatedAt)
+	})
+	if len(courses) > 2 {
+		courses = courses[:2]
+	}
+	return courses, nil
+}
+
+func (s *courseStore) FindByID(ctx context.Context, courseID bson.ObjectID) (dao.CourseDao, error) {
+	return s.courses.get(ctx, courseID)
+}
+
+func (s *courseStore) CreateCourse(ctx context.Context, course dao.CourseDao) (dao.CourseDao, error) {
+	course.ID = bson.NewObjectID()
+	course.CreatedAt = time.Now()
+	course.UpdatedAt = time.Now()
+
+	if err := s.courses.insert(ctx, course); err != nil {
+		return dao.CourseDao{}, err
+	}
+	return course, nil
+}
+
+func (s *courseStore) UpdateCourseImage(ctx context.Context, courseID bson.ObjectID, data []byte, contentType string) (bool, error) {
+	return s.courses.updateID(ctx, courseID, func(course *dao.CourseDao) bool {
+		course.ImageData = bson.Binary{Subtype: 0, Data: data}
+		course.ImageType = contentType
+		course.UpdatedAt = time.Now()
+		return true
+	})
+}
@@ -0,0 +1,187 @@
+// Package embedded implementa os repositórios em um único arquivo local
+// (bbolt), para o backend rodar junto do app desktop sem acesso ao MongoDB.
+// Cada collection vira um bucket com o mesmo nome. As buscas percorrem o bucket
+// inteiro, o que atende os volumes de uma instalação desktop; o comportamento
+// observável é o mesmo da implementação em MongoDB (ver repotest).
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"metabee/internal/repository"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Espera pelo lock do arquivo; outro processo com o mesmo arquivo aberto faz Open falhar
+const openTimeout = 5 * time.Second
+
+// Buckets com os mesmos nomes das collections do MongoDB
+const (
+	userBucket              = "user"
+	courseBucket            = "courses"
+	lessonBucket            = "lesson"
+	purchaseBucket          = "purchase"
+	newsBucket              = "news"
+	imageBucket             = "images"
+	userProgressBucket      = "user_progress"
+	studySessionBucket      = "study_session"
+	refreshTokenBucket      = "refresh_token"
+	sessionBucket           = "session"
+	revokedTokenBucket      = "revoked_token"
+	passwordResetBucket     = "password_reset"
+	emailVerificationBucket = "email_verification"
+	loginAttemptBucket      = "login_attempt"
+	oidcStateBucket         = "oidc_state"
+	apiKeyBucket            = "api_key"
+	auditLogBucket          = "audit_log"
+	noteBucket              = "note"
+	syncCounterBucket       = "sync_counter"
+	syncStateBucket         = "sync_state"
+	ledgerBucket            = "ledger"
+)
+
+var buckets = []string{
+	userBucket, courseBucket, lessonBucket, purchaseBucket, newsBucket, imageBucket,
+	userProgressBucket, studySessionBucket, refreshTokenBucket, sessionBucket,
+	revokedTokenBucket, passwordResetBucket, emailVerificationBucket, loginAttemptBucket,
+	oidcStateBucket, apiKeyBucket, auditLogBucket, noteBucket, syncCounterBucket, syncStateBucket,
+	ledgerBucket,
+}
+
+// Buckets que no MongoDB têm índice TTL em expires_at
+var expiringBuckets = []string{
+	refreshTokenBucket, sessionBucket, revokedTokenBucket, passwordResetBucket,
+	emailVerificationBucket, loginAttemptBucket, oidcStateBucket,
+}
+
+// Storage é o backend embedded (repository.Storage)
+type Storage struct {
+	db    *bbolt.DB
+	repos repository.Repositories
+}
+
+// Open abre o arquivo de dados, criando-o se não existir, e remove os registros
+// expirados (o equivalente aos índices TTL do MongoDB).
+func Open(path string) (*Storage, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return fmt.Errorf("bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao preparar %s: %w", path, err)
+	}
+
+	purged, err := purgeExpired(db, time.Now())
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao remover registros expirados de %s: %w", path, err)
+	}
+	if purged > 0 {
+		log.Printf("🧹 %d registro(s) expirado(s) removido(s)", purged)
+	}
+
+	return &Storage{db: db, repos: newRepositories(db)}, nil
+}
+
+func newRepositories(db *bbolt.DB) repository.Repositories {
+	purchases, progress, ledger := newPurchaseStore(db), newProgressStore(db), newLedgerStore(db)
+	return repository.Repositories{
+		Users:      newUserStore(db),
+		Courses:    newCourseStore(db),
+		Lessons:    newLessonStore(db),
+		Purchases:  purchases,
+		News:       newNewsStore(db),
+		Images:     newImageStore(db),
+		Progress:   progress,
+		Ledger:     ledger,
+		Sync:       newSyncStore(db),
+		UnitOfWork: &unitOfWork{db: db, purchases: purchases, progress: progress, ledger: ledger},
+		Auth: repository.AuthRepositories{
+			RefreshTokens:      newRefreshTokenStore(db),
+			Sessions:           newSessionStore(db),
+			RevokedTokens:      newRevokedTokenStore(db),
+			PasswordResets:     newPasswordResetStore(db),
+			EmailVerifications: newEmailVerificationStore(db),
+			LoginAttempts:      newLoginAttemptStore(db),
+			OIDCStates:         newOIDCStateStore(db),
+			APIKeys:            newAPIKeyStore(db),
+			AuditLogs:          newAuditLogStore(db),
+		},
+	}
+}
+
+func (s *Storage) Name() string { return "embedded" }
+
+func (s *Storage) Repositories() repository.Repositories { return s.repos }
+
+// Ping falha depois de Close
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.View(func(*bbolt.Tx) error { return nil })
+}
+
+// Close espera as transações em andamento até o fim de ctx
+func (s *Storage) Close(ctx context.Context) error {
+	closed := make(chan error, 1)
+	go func() { closed <- s.db.Close() }()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// purgeExpired remove os documentos com expires_at anterior a now. Só roda na
+// abertura: como no MongoDB, quem consulta confere a expiração.
+func purgeExpired(db *bbolt.DB, now time.Time) (int, error) {
+	purged := 0
+	err := db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range expiringBuckets {
+			bucket := tx.Bucket([]byte(name))
+
+			var expired [][]byte
+			err := bucket.ForEach(func(key, raw []byte) error {
+				var document struct {
+					ExpiresAt time.Time `bson:"expires_at"`
+				}
+				if err := bson.Unmarshal(append([]byte(nil), raw...), &document); err != nil {
+					return err
+				}
+				if !document.ExpiresAt.IsZero() && document.ExpiresAt.Before(now) {
+					expired = append(expired, append([]byte(nil), key...))
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("bucket %s: %w", name, err)
+			}
+
+			for _, key := range expired {
+				if err := bucket.Delete(key); err != nil {
+					return err
+				}
+			}
+			purged += len(expired)
+		}
+		return nil
+	})
+	return purged, err
+}
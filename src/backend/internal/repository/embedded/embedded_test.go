@@ -0,0 +1,30 @@
+package embedded_test
+
+import (
+	"context"
+	"metabee/internal/repository"
+	"metabee/internal/repository/embedded"
+	"metabee/internal/repository/repotest"
+	"path/filepath"
+	"testing"
+)
+
+// openStorage abre um arquivo novo em t.TempDir(), fechado ao fim do subteste
+func openStorage(t *testing.T) *embedded.Storage {
+	storage, err := embedded.Open(filepath.Join(t.TempDir(), "metabee.db"))
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco local: %v", err)
+	}
+	t.Cleanup(func() { storage.Close(context.Background()) })
+	return storage
+}
+
+func TestEmbeddedRepositories(t *testing.T) {
+	t.Parallel()
+	repotest.Run(t, func(t *testing.T) repository.Repositories { return openStorage(t).Repositories() })
+}
+
+func TestEmbeddedAuthRepositories(t *testing.T) {
+	t.Parallel()
+	repotest.RunAuth(t, func(t *testing.T) repository.AuthRepositories { return openStorage(t).Repositories().Auth })
+}
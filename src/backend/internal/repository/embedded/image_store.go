@@ -0,0 +1,58 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type imageStore struct {
+	images collection[dao.ImageDao]
+}
+
+func newImageStore(db *bbolt.DB) *imageStore {
+	return &imageStore{images: newCollection(db, imageBucket, func(image dao.ImageDao) bson.ObjectID { return image.ID })}
+}
+
+func (s *imageStore) CreateImage(ctx context.Context, name string, data []byte, mimeType string) (dao.ImageDao, error) {
+	image := dao.ImageDao{
+		ID:        bson.NewObjectID(),
+		Name:      name,
+		Data:      bson.Binary{Subtype: 0, Data: data},
+		MimeType:  mimeType,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.images.insert(ctx, image); err != nil {
+		return dao.ImageDao{}, err
+	}
+	return image, nil
+}
+
+func (s *imageStore) FindImageByName(ctx context.Context, name string) (dao.ImageDao, error) {
+	return s.images.findOne(ctx, func(image dao.ImageDao) bool { return image.Name == name })
+}
+
+func (s *imageStore) FindImageByID(ctx context.Context, id bson.ObjectID) (dao.ImageDao, error) {
+	return s.images.get(ctx, id)
+}
+
+func (s *imageStore) UpdateImage(ctx context.Context, id bson.ObjectID, name string, data []byte, mimeType string) error {
+	_, err := s.images.updateID(ctx, id, func(image *dao.ImageDao) bool {
+		image.Name = name
+		image.Data = bson.Binary{Subtype: 0, Data: data}
+		image.MimeType = mimeType
+		image.UpdatedAt = time.Now()
+		return true
+	})
+	return err
+}
+
+func (s *imageStore) DeleteImage(ctx context.Context, id bson.ObjectID) error {
+	_, err := s.images.remove(ctx, func(image dao.ImageDao) bool { return image.ID == id })
+	return err
+}
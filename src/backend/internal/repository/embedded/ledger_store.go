@@ -0,0 +1,45 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ledgerStore struct {
+	entries collection[dao.LedgerEntryDao]
+}
+
+func newLedgerStore(db *bbolt.DB) *ledgerStore {
+	return &ledgerStore{entries: newCollection(db, ledgerBucket, func(entry dao.LedgerEntryDao) bson.ObjectID { return entry.ID })}
+}
+
+func (s *ledgerStore) inTx(tx *bbolt.Tx) *ledgerStore {
+	return &ledgerStore{entries: s.entries.inTx(tx)}
+}
+
+// RecordEntry respeita a unicidade de compra/tipo, como o índice único do MongoDB
+func (s *ledgerStore) RecordEntry(ctx context.Context, entry dao.LedgerEntryDao) (dao.LedgerEntryDao, error) {
+	entry.ID = bson.NewObjectID()
+	entry.CreatedAt = time.Now()
+
+	inserted, err := s.entries.insertUnless(ctx, entry, func(existing dao.LedgerEntryDao) bool {
+		return existing.PurchaseID == entry.PurchaseID && existing.Type == entry.Type
+	})
+	if err != nil {
+		return dao.LedgerEntryDao{}, err
+	}
+	if !inserted {
+		return dao.LedgerEntryDao{}, dao.ErrLedgerEntryExists
+	}
+	return entry, nil
+}
+
+func (s *ledgerStore) FindByPurchase(ctx context.Context, purchaseID bson.ObjectID, entryType string) (dao.LedgerEntryDao, error) {
+	return s.entries.findOne(ctx, func(entry dao.LedgerEntryDao) bool {
+		return entry.PurchaseID == purchaseID && entry.Type == entryType
+	})
+}
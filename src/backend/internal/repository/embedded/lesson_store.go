@@ -0,0 +1,37 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type lessonStore struct {
+	lessons collection[dao.LessonDao]
+}
+
+func newLessonStore(db *bbolt.DB) *lessonStore {
+	return &lessonStore{lessons: newCollection(db, lessonBucket, func(lesson dao.LessonDao) bson.ObjectID { return lesson.ID })}
+}
+
+func (s *lessonStore) GetLessonsByIDs(ctx context.Context, lessonIDs []bson.ObjectID) ([]dao.LessonDao, error) {
+	wanted := make(map[bson.ObjectID]bool, len(lessonIDs))
+	for _, id := range lessonIDs {
+		wanted[id] = true
+	}
+	return s.lessons.find(ctx, func(lesson dao.LessonDao) bool { return wanted[lesson.ID] })
+}
+
+func (s *lessonStore) CreateLesson(ctx context.Context, lesson dao.LessonDao) (dao.LessonDao, error) {
+	lesson.ID = bson.NewObjectID()
+	lesson.CreatedAt = time.Now()
+	lesson.UpdatedAt = time.Now()
+
+	if err := s.lessons.insert(ctx, lesson); err != nil {
+		return dao.LessonDao{}, err
+	}
+	return lesson, nil
+}
@@ -0,0 +1,91 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type loginAttemptStore struct {
+	attempts collection[dao.LoginAttemptDao]
+}
+
+func newLoginAttemptStore(db *bbolt.DB) *loginAttemptStore {
+	return &loginAttemptStore{attempts: newCollection(db, loginAttemptBucket, func(attempt dao.LoginAttemptDao) bson.ObjectID { return attempt.ID })}
+}
+
+func (s *loginAttemptStore) FindByKey(ctx context.Context, key string) (dao.LoginAttemptDao, error) {
+	attempt, err := s.attempts.findOne(ctx, func(attempt dao.LoginAttemptDao) bool { return attempt.Key == key })
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dao.LoginAttemptDao{Key: key}, nil
+	}
+	return attempt, err
+}
+
+// RegisterFailure zera a contagem quando a última falha saiu da janela e soma a
+// nova falha, na mesma transação
+func (s *loginAttemptStore) RegisterFailure(ctx context.Context, key string, window time.Duration, retention time.Duration) (dao.LoginAttemptDao, error) {
+	now := time.Now()
+	return s.attempts.upsert(ctx,
+		func(attempt dao.LoginAttemptDao) bool { return attempt.Key == key },
+		func(attempt *dao.LoginAttemptDao, found bool) {
+			if !found {
+				attempt.ID = bson.NewObjectID()
+				attempt.Key = key
+			} else if attempt.LastFailureAt.Before(now.Add(-window)) {
+				attempt.Failures = 0
+			}
+			attempt.Failures++
+			attempt.LastFailureAt = now
+			attempt.ExpiresAt = now.Add(retention)
+		})
+}
+
+func (s *loginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := s.attempts.updateWhere(ctx, func(attempt *dao.LoginAttemptDao) bool {
+		if attempt.Key != key {
+			return false
+		}
+		attempt.LockedUntil = until
+		if until.After(attempt.ExpiresAt) {
+			attempt.ExpiresAt = until
+		}
+		return true
+	})
+	return err
+}
+
+func (s *loginAttemptStore) Reset(ctx context.Context, key string) error {
+	_, err := s.attempts.remove(ctx, func(attempt dao.LoginAttemptDao) bool { return attempt.Key == key })
+	return err
+}
+
+type oidcStateStore struct {
+	states collection[dao.OIDCStateDao]
+}
+
+func newOIDCStateStore(db *bbolt.DB) *oidcStateStore {
+	return &oidcStateStore{states: newCollection(db, oidcStateBucket, func(state dao.OIDCStateDao) bson.ObjectID { return state.ID })}
+}
+
+func (s *oidcStateStore) CreateOIDCState(ctx context.Context, state dao.OIDCStateDao) error {
+	state.ID = bson.NewObjectID()
+	state.CreatedAt = time.Now()
+	return s.states.insert(ctx, state)
+}
+
+func (s *oidcStateStore) ConsumeByHash(ctx context.Context, stateHash string) (dao.OIDCStateDao, error) {
+	removed, err := s.states.remove(ctx, func(state dao.OIDCStateDao) bool { return state.StateHash == stateHash })
+	if err != nil {
+		return dao.OIDCStateDao{}, err
+	}
+	if len(removed) == 0 {
+		return dao.OIDCStateDao{}, mongo.ErrNoDocuments
+	}
+	return removed[0], nil
+}
@@ -0,0 +1,57 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type newsStore struct {
+	news collection[dao.NewsDao]
+}
+
+func newNewsStore(db *bbolt.DB) *newsStore {
+	return &newsStore{news: newCollection(db, newsBucket, func(news dao.NewsDao) bson.ObjectID { return news.ID })}
+}
+
+func (s *newsStore) GetAllNews(ctx context.Context) ([]dao.NewsDao, error) {
+	return s.GetLastNews(ctx, 0)
+}
+
+// GetLastNews com limit <= 0 retorna todas, como SetLimit(0) no MongoDB
+func (s *newsStore) GetLastNews(ctx context.Context, limit int) ([]dao.NewsDao, error) {
+	news, err := s.news.find(ctx, func(dao.NewsDao) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(news, func(i, j int) bool {
+		return news[i].Date.After(news[j].Date)
+	})
+	if limit > 0 && len(news) > limit {
+		news = news[:limit]
+	}
+	return news, nil
+}
+
+func (s *newsStore) FindByID(ctx context.Context, newsID bson.ObjectID) (dao.NewsDao, error) {
+	return s.news.get(ctx, newsID)
+}
+
+func (s *newsStore) CreateNews(ctx context.Context, news dao.NewsDao) (dao.NewsDao, error) {
+	news.ID = bson.NewObjectID()
+	if news.Date.IsZero() {
+		news.Date = time.Now()
+	}
+	news.CreatedAt = time.Now()
+	news.UpdatedAt = time.Now()
+
+	if err := s.news.insert(ctx, news); err != nil {
+		return dao.NewsDao{}, err
+	}
+	return news, nil
+}
@@ -0,0 +1,132 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type progressStore struct {
+	progresses collection[dao.UserProgressDao]
+	sessions   collection[dao.StudySessionDao]
+}
+
+func newProgressStore(db *bbolt.DB) *progressStore {
+	return &progressStore{
+		progresses: newCollection(db, userProgressBucket, func(progress dao.UserProgressDao) bson.ObjectID { return progress.ID }),
+		sessions:   newCollection(db, studySessionBucket, func(session dao.StudySessionDao) bson.ObjectID { return session.ID }),
+	}
+}
+
+func (s *progressStore) inTx(tx *bbolt.Tx) *progressStore {
+	return &progressStore{progresses: s.progresses.inTx(tx), sessions: s.sessions.inTx(tx)}
+}
+
+func (s *progressStore) GetActiveCourses(ctx context.Context, userID bson.ObjectID) ([]dao.UserProgressDao, error) {
+	return s.progresses.find(ctx, func(progress dao.UserProgressDao) bool {
+		return progress.UserID == userID && progress.Progress > 0 && progress.Progress < 100
+	})
+}
+
+func (s *progressStore) GetHoursThisMonth(ctx context.Context, userID bson.ObjectID) (float64, error) {
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	sessions, err := s.sessions.find(ctx, func(session dao.StudySessionDao) bool {
+		return session.UserID == userID && !session.Date.Before(startOfMonth)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, session := range sessions {
+		total += session.Duration
+	}
+	return total, nil
+}
+
+func (s *progressStore) GetAverageProgress(ctx context.Context, userID bson.ObjectID) (float64, error) {
+	progresses, err := s.progresses.find(ctx, func(progress dao.UserProgressDao) bool { return progress.UserID == userID })
+	if err != nil || len(progresses) == 0 {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, progress := range progresses {
+		total += progress.Progress
+	}
+	return total / float64(len(progresses)), nil
+}
+
+func (s *progressStore) GetRecentLessons(ctx context.Context, userID bson.ObjectID, limit int) ([]dao.StudySessionDao, error) {
+	sessions, err := s.sessions.find(ctx, func(session dao.StudySessionDao) bool { return session.UserID == userID })
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Date.After(sessions[j].Date)
+	})
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (s *progressStore) SaveProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, error) {
+	now := time.Now()
+	return s.progresses.upsert(ctx,
+		func(existing dao.UserProgressDao) bool {
+			return existing.UserID == progress.UserID && existing.CourseID == progress.CourseID
+		},
+		func(saved *dao.UserProgressDao, found bool) {
+			if !found {
+				saved.ID = bson.NewObjectID()
+				saved.UserID = progress.UserID
+				saved.CourseID = progress.CourseID
+				saved.CreatedAt = now
+			}
+			saved.Progress = progress.Progress
+			saved.Hours = progress.Hours
+			saved.LastStudy = progress.LastStudy
+			saved.UpdatedAt = now
+		})
+}
+
+func (s *progressStore) EnsureProgress(ctx context.Context, userID, courseID bson.ObjectID) (dao.UserProgressDao, error) {
+	var current dao.UserProgressDao
+	now := time.Now()
+	created := dao.UserProgressDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, CreatedAt: now, UpdatedAt: now}
+	inserted, err := s.progresses.insertUnless(ctx, created, func(existing dao.UserProgressDao) bool {
+		if existing.UserID == userID && existing.CourseID == courseID {
+			current = existing
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return dao.UserProgressDao{}, err
+	}
+	if inserted {
+		return created, nil
+	}
+	return current, nil
+}
+
+func (s *progressStore) CreateStudySession(ctx context.Context, session dao.StudySessionDao) (dao.StudySessionDao, error) {
+	session.ID = bson.NewObjectID()
+	session.CreatedAt = time.Now()
+	if session.Date.IsZero() {
+		session.Date = session.CreatedAt
+	}
+
+	if err := s.sessions.insert(ctx, session); err != nil {
+		return dao.StudySessionDao{}, err
+	}
+	return session, nil
+}
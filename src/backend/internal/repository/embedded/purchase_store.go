@@ -0,0 +1,68 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type purchaseStore struct {
+	purchases collection[dao.PurchaseDao]
+}
+
+func newPurchaseStore(db *bbolt.DB) *purchaseStore {
+	return &purchaseStore{purchases: newCollection(db, purchaseBucket, func(purchase dao.PurchaseDao) bson.ObjectID { return purchase.ID })}
+}
+
+func (s *purchaseStore) inTx(tx *bbolt.Tx) *purchaseStore {
+	return &purchaseStore{purchases: s.purchases.inTx(tx)}
+}
+
+// CreatePurchase respeita a unicidade de usuário/curso, como o índice único do MongoDB
+func (s *purchaseStore) CreatePurchase(ctx context.Context, userID, courseID bson.ObjectID, driveLink string) (dao.PurchaseDao, error) {
+	purchase := dao.PurchaseDao{
+		ID:        bson.NewObjectID(),
+		UserID:    userID,
+		CourseID:  courseID,
+		Status:    "pending",
+		DriveLink: driveLink,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	inserted, err := s.purchases.insertUnless(ctx, purchase, func(existing dao.PurchaseDao) bool {
+		return existing.UserID == userID && existing.CourseID == courseID
+	})
+	if err != nil {
+		return dao.PurchaseDao{}, err
+	}
+	if !inserted {
+		return dao.PurchaseDao{}, dao.ErrPurchaseExists
+	}
+	return purchase, nil
+}
+
+func (s *purchaseStore) GetPurchasesByUser(ctx context.Context, userID bson.ObjectID) ([]dao.PurchaseDao, error) {
+	return s.purchases.find(ctx, func(purchase dao.PurchaseDao) bool { return purchase.UserID == userID })
+}
+
+func (s *purchaseStore) GetPurchaseByUserAndCourse(ctx context.Context, userID, courseID bson.ObjectID) (dao.PurchaseDao, error) {
+	return s.purchases.findOne(ctx, func(purchase dao.PurchaseDao) bool {
+		return purchase.UserID == userID && purchase.CourseID == courseID
+	})
+}
+
+func (s *purchaseStore) UpdatePurchaseStatus(ctx context.Context, purchaseID bson.ObjectID, status string, localPath string) error {
+	_, err := s.purchases.updateID(ctx, purchaseID, func(purchase *dao.PurchaseDao) bool {
+		purchase.Status = status
+		purchase.UpdatedAt = time.Now()
+		if localPath != "" {
+			purchase.LocalPath = localPath
+		}
+		return true
+	})
+	return err
+}
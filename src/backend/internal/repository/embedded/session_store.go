@@ -0,0 +1,114 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type sessionStore struct {
+	sessions collection[dao.SessionDao]
+}
+
+func newSessionStore(db *bbolt.DB) *sessionStore {
+	return &sessionStore{sessions: newCollection(db, sessionBucket, func(session dao.SessionDao) bson.ObjectID { return session.ID })}
+}
+
+// CreateSession usa o ID recebido (o da família de refresh tokens)
+func (s *sessionStore) CreateSession(ctx context.Context, session dao.SessionDao) (dao.SessionDao, error) {
+	if session.ID.IsZero() {
+		session.ID = bson.NewObjectID()
+	}
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	if err := s.sessions.insert(ctx, session); err != nil {
+		return dao.SessionDao{}, err
+	}
+	return session, nil
+}
+
+func (s *sessionStore) FindActiveByUser(ctx context.Context, userID bson.ObjectID) ([]dao.SessionDao, error) {
+	now := time.Now()
+	sessions, err := s.sessions.find(ctx, func(session dao.SessionDao) bool {
+		return session.UserID == userID && session.RevokedAt.IsZero() && session.ExpiresAt.After(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Empate no mesmo milissegundo: a mais nova primeiro, como o sort por _id no MongoDB
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeenAt.Equal(sessions[j].LastSeenAt) {
+			return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+		}
+		return sessions[i].ID.Hex() > sessions[j].ID.Hex()
+	})
+	return sessions, nil
+}
+
+func (s *sessionStore) FindByIDForUser(ctx context.Context, sessionID, userID bson.ObjectID) (dao.SessionDao, error) {
+	session, err := s.sessions.get(ctx, sessionID)
+	if err != nil {
+		return dao.SessionDao{}, err
+	}
+	if session.UserID != userID {
+		return dao.SessionDao{}, mongo.ErrNoDocuments
+	}
+	return session, nil
+}
+
+func (s *sessionStore) Touch(ctx context.Context, sessionID bson.ObjectID, ip string, expiresAt time.Time) error {
+	_, err := s.sessions.updateID(ctx, sessionID, func(session *dao.SessionDao) bool {
+		session.LastSeenAt = time.Now()
+		if ip != "" {
+			session.IP = ip
+		}
+		if !expiresAt.IsZero() {
+			session.ExpiresAt = expiresAt
+		}
+		return true
+	})
+	return err
+}
+
+func (s *sessionStore) TouchIfIdle(ctx context.Context, sessionID bson.ObjectID, ip string, idleSince time.Time) error {
+	_, err := s.sessions.updateID(ctx, sessionID, func(session *dao.SessionDao) bool {
+		if !session.LastSeenAt.Before(idleSince) {
+			return false
+		}
+		session.LastSeenAt = time.Now()
+		session.IP = ip
+		return true
+	})
+	return err
+}
+
+func (s *sessionStore) Revoke(ctx context.Context, sessionID bson.ObjectID) error {
+	_, err := s.sessions.updateID(ctx, sessionID, func(session *dao.SessionDao) bool {
+		if !session.RevokedAt.IsZero() {
+			return false
+		}
+		session.RevokedAt = time.Now()
+		return true
+	})
+	return err
+}
+
+func (s *sessionStore) RevokeAllForUserExcept(ctx context.Context, userID, keepSessionID bson.ObjectID) error {
+	now := time.Now()
+	_, err := s.sessions.updateWhere(ctx, func(session *dao.SessionDao) bool {
+		if session.UserID != userID || session.ID == keepSessionID || !session.RevokedAt.IsZero() {
+			return false
+		}
+		session.RevokedAt = now
+		return true
+	})
+	return err
+}
@@ -0,0 +1,263 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type syncCounter struct {
+	UserID bson.ObjectID `bson:"_id"`
+	Seq    int64         `bson:"seq"`
+}
+
+// syncStore grava o contador do usuário e o registro na mesma transação, então
+// a sequência não tem lacunas
+type syncStore struct {
+	db       *bbolt.DB
+	sessions collection[dao.StudySessionDao]
+	progress collection[dao.UserProgressDao]
+	notes    collection[dao.NoteDao]
+	counters collection[syncCounter]
+	states   collection[dao.SyncStateDao]
+}
+
+func newSyncStore(db *bbolt.DB) *syncStore {
+	return &syncStore{
+		db:       db,
+		sessions: newCollection(db, studySessionBucket, func(session dao.StudySessionDao) bson.ObjectID { return session.ID }),
+		progress: newCollection(db, userProgressBucket, func(progress dao.UserProgressDao) bson.ObjectID { return progress.ID }),
+		notes:    newCollection(db, noteBucket, func(note dao.NoteDao) bson.ObjectID { return note.ID }),
+		counters: newCollection(db, syncCounterBucket, func(counter syncCounter) bson.ObjectID { return counter.UserID }),
+		states:   newCollection(db, syncStateBucket, func(state dao.SyncStateDao) bson.ObjectID { return state.UserID }),
+	}
+}
+
+func (s *syncStore) update(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.Update(fn)
+}
+
+// nextSeq incrementa o contador do usuário dentro da transação
+func (s *syncStore) nextSeq(tx *bbolt.Tx, userID bson.ObjectID) (int64, error) {
+	bucket := tx.Bucket(s.counters.name)
+	counter := syncCounter{UserID: userID}
+	if raw := bucket.Get(userID[:]); raw != nil {
+		var err error
+		if counter, err = s.counters.decode(raw); err != nil {
+			return 0, err
+		}
+	}
+	counter.Seq++
+	return counter.Seq, s.counters.put(bucket, counter)
+}
+
+func (s *syncStore) ApplyStudySession(ctx context.Context, session dao.StudySessionDao) (bool, error) {
+	inserted := false
+	err := s.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(s.sessions.name)
+		if bucket.Get(session.ID[:]) != nil {
+			return nil
+		}
+		seq, err := s.nextSeq(tx, session.UserID)
+		if err != nil {
+			return err
+		}
+		session.Seq = seq
+		session.CreatedAt = time.Now()
+		inserted = true
+		if err := s.sessions.put(bucket, session); err != nil {
+			return err
+		}
+		return s.syncHours(tx, session.UserID, session.CourseID)
+	})
+	return inserted && err == nil, err
+}
+
+// findProgress retorna o progresso do par usuário/curso, se existir
+func (s *syncStore) findProgress(bucket *bbolt.Bucket, userID, courseID bson.ObjectID) (dao.UserProgressDao, bool, error) {
+	var found dao.UserProgressDao
+	ok := false
+	err := s.progress.scan(bucket, func(existing dao.UserProgressDao) bool {
+		if existing.UserID == userID && existing.CourseID == courseID {
+			found, ok = existing, true
+		}
+		return !ok
+	})
+	return found, ok, err
+}
+
+// syncHours grava nas horas do progresso a soma das sessões de estudo do curso,
+// criando o progresso se preciso
+func (s *syncStore) syncHours(tx *bbolt.Tx, userID, courseID bson.ObjectID) error {
+	hours := 0.0
+	if err := s.sessions.scan(tx.Bucket(s.sessions.name), func(session dao.StudySessionDao) bool {
+		if session.UserID == userID && session.CourseID == courseID {
+			hours += session.Duration
+		}
+		return true
+	}); err != nil {
+		return err
+	}
+
+	bucket := tx.Bucket(s.progress.name)
+	saved, found, err := s.findProgress(bucket, userID, courseID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if !found {
+		saved = dao.UserProgressDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, CreatedAt: now}
+	} else if saved.Hours >= hours {
+		return nil
+	}
+
+	seq, err := s.nextSeq(tx, userID)
+	if err != nil {
+		return err
+	}
+	saved.Hours = hours
+	saved.Seq = seq
+	saved.UpdatedAt = now
+	return s.progress.put(bucket, saved)
+}
+
+func (s *syncStore) ApplyProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, bool, error) {
+	var saved dao.UserProgressDao
+	changed := false
+	err := s.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(s.progress.name)
+		var found bool
+		var err error
+		if saved, found, err = s.findProgress(bucket, progress.UserID, progress.CourseID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if found {
+			if saved, changed = saved.Merge(progress); !changed {
+				return nil
+			}
+		} else {
+			saved = dao.UserProgressDao{
+				ID:        bson.NewObjectID(),
+				UserID:    progress.UserID,
+				CourseID:  progress.CourseID,
+				Progress:  progress.Progress,
+				LastStudy: progress.LastStudy,
+				CreatedAt: now,
+			}
+			changed = true
+		}
+
+		seq, err := s.nextSeq(tx, progress.UserID)
+		if err != nil {
+			return err
+		}
+		saved.Seq = seq
+		saved.UpdatedAt = now
+		return s.progress.put(bucket, saved)
+	})
+	if err != nil {
+		return dao.UserProgressDao{}, false, err
+	}
+	return saved, changed, nil
+}
+
+func (s *syncStore) ApplyNote(ctx context.Context, note dao.NoteDao) (dao.NoteDao, bool, error) {
+	current := note
+	changed := false
+	err := s.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(s.notes.name)
+		if raw := bucket.Get(note.ID[:]); raw != nil {
+			existing, err := s.notes.decode(raw)
+			if err != nil {
+				return err
+			}
+			if existing.UserID != note.UserID {
+				return mongo.ErrNoDocuments
+			}
+			if !note.Supersedes(existing) {
+				current = existing
+				return nil
+			}
+		}
+
+		seq, err := s.nextSeq(tx, note.UserID)
+		if err != nil {
+			return err
+		}
+		current.Seq = seq
+		changed = true
+		return s.notes.put(bucket, current)
+	})
+	if err != nil {
+		return dao.NoteDao{}, false, err
+	}
+	return current, changed, nil
+}
+
+// Changes lê as três listas na mesma transação: em transações separadas, uma
+// gravação entre as leituras deixaria uma sessão de fora com seq menor que Next
+func (s *syncStore) Changes(ctx context.Context, userID bson.ObjectID, since int64, limit int) (dao.SyncChanges, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.SyncChanges{}, err
+	}
+
+	var changes dao.SyncChanges
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if err := s.sessions.scan(tx.Bucket(s.sessions.name), func(session dao.StudySessionDao) bool {
+			if session.UserID == userID && session.Seq > since {
+				changes.StudySessions = append(changes.StudySessions, session)
+			}
+			return true
+		}); err != nil {
+			return err
+		}
+		if err := s.progress.scan(tx.Bucket(s.progress.name), func(progress dao.UserProgressDao) bool {
+			if progress.UserID == userID && progress.Seq > since {
+				changes.Progress = append(changes.Progress, progress)
+			}
+			return true
+		}); err != nil {
+			return err
+		}
+		return s.notes.scan(tx.Bucket(s.notes.name), func(note dao.NoteDao) bool {
+			if note.UserID == userID && note.Seq > since {
+				changes.Notes = append(changes.Notes, note)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return dao.SyncChanges{}, err
+	}
+	return changes.Page(since, limit), nil
+}
+
+func (s *syncStore) LatestSeq(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	counter, err := s.counters.get(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return counter.Seq, err
+}
+
+func (s *syncStore) GetSyncState(ctx context.Context, userID bson.ObjectID) (dao.SyncStateDao, error) {
+	state, err := s.states.get(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dao.SyncStateDao{UserID: userID}, nil
+	}
+	return state, err
+}
+
+func (s *syncStore) SaveSyncState(ctx context.Context, state dao.SyncStateDao) error {
+	return s.states.insert(ctx, state)
+}
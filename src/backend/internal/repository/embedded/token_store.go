@@ -0,0 +1,115 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type refreshTokenStore struct {
+	tokens collection[dao.RefreshTokenDao]
+}
+
+func newRefreshTokenStore(db *bbolt.DB) *refreshTokenStore {
+	return &refreshTokenStore{tokens: newCollection(db, refreshTokenBucket, func(token dao.RefreshTokenDao) bson.ObjectID { return token.ID })}
+}
+
+func (s *refreshTokenStore) CreateRefreshToken(ctx context.Context, token dao.RefreshTokenDao) (dao.RefreshTokenDao, error) {
+	if token.ID.IsZero() {
+		token.ID = bson.NewObjectID()
+	}
+	token.CreatedAt = time.Now()
+
+	if err := s.tokens.insert(ctx, token); err != nil {
+		return dao.RefreshTokenDao{}, err
+	}
+	return token, nil
+}
+
+func (s *refreshTokenStore) FindByHash(ctx context.Context, tokenHash string) (dao.RefreshTokenDao, error) {
+	return s.tokens.findOne(ctx, func(token dao.RefreshTokenDao) bool { return token.TokenHash == tokenHash })
+}
+
+func (s *refreshTokenStore) MarkUsed(ctx context.Context, tokenID, replacedBy bson.ObjectID) error {
+	updated, err := s.tokens.updateID(ctx, tokenID, func(token *dao.RefreshTokenDao) bool {
+		if !token.UsedAt.IsZero() || !token.RevokedAt.IsZero() {
+			return false
+		}
+		token.UsedAt = time.Now()
+		token.ReplacedBy = replacedBy
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return dao.ErrRefreshTokenAlreadyUsed
+	}
+	return nil
+}
+
+func (s *refreshTokenStore) RevokeFamily(ctx context.Context, familyID bson.ObjectID) error {
+	return s.revokeWhere(ctx, func(token dao.RefreshTokenDao) bool { return token.FamilyID == familyID })
+}
+
+func (s *refreshTokenStore) RevokeAllForUser(ctx context.Context, userID bson.ObjectID) error {
+	return s.revokeWhere(ctx, func(token dao.RefreshTokenDao) bool { return token.UserID == userID })
+}
+
+func (s *refreshTokenStore) RevokeAllForUserExcept(ctx context.Context, userID, keepFamilyID bson.ObjectID) error {
+	return s.revokeWhere(ctx, func(token dao.RefreshTokenDao) bool {
+		return token.UserID == userID && token.FamilyID != keepFamilyID
+	})
+}
+
+// revokeWhere revoga os tokens ainda ativos em que match é verdadeiro
+func (s *refreshTokenStore) revokeWhere(ctx context.Context, match func(dao.RefreshTokenDao) bool) error {
+	now := time.Now()
+	_, err := s.tokens.updateWhere(ctx, func(token *dao.RefreshTokenDao) bool {
+		if !match(*token) || !token.RevokedAt.IsZero() {
+			return false
+		}
+		token.RevokedAt = now
+		return true
+	})
+	return err
+}
+
+func (s *refreshTokenStore) IsFamilyRevoked(ctx context.Context, familyID bson.ObjectID) (bool, error) {
+	return s.tokens.exists(ctx, func(token dao.RefreshTokenDao) bool {
+		return token.FamilyID == familyID && !token.RevokedAt.IsZero()
+	})
+}
+
+type revokedTokenStore struct {
+	tokens collection[dao.RevokedTokenDao]
+}
+
+func newRevokedTokenStore(db *bbolt.DB) *revokedTokenStore {
+	return &revokedTokenStore{tokens: newCollection(db, revokedTokenBucket, func(token dao.RevokedTokenDao) bson.ObjectID { return token.ID })}
+}
+
+// RevokeToken não altera um jti já revogado, como o $setOnInsert do MongoDB
+func (s *revokedTokenStore) RevokeToken(ctx context.Context, jti string, userID bson.ObjectID, expiresAt time.Time) error {
+	_, err := s.tokens.upsert(ctx,
+		func(token dao.RevokedTokenDao) bool { return token.JTI == jti },
+		func(token *dao.RevokedTokenDao, found bool) {
+			if !found {
+				*token = dao.RevokedTokenDao{
+					ID:        bson.NewObjectID(),
+					JTI:       jti,
+					UserID:    userID,
+					ExpiresAt: expiresAt,
+					CreatedAt: time.Now(),
+				}
+			}
+		})
+	return err
+}
+
+func (s *revokedTokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.tokens.exists(ctx, func(token dao.RevokedTokenDao) bool { return token.JTI == jti })
+}
@@ -0,0 +1,30 @@
+package embedded
+
+import (
+	"context"
+	"metabee/internal/repository"
+
+	"go.etcd.io/bbolt"
+)
+
+// unitOfWork roda a unidade em uma única transação de escrita do bbolt: se fn
+// falhar, nada é gravado. Outras escritas esperam a unidade terminar.
+type unitOfWork struct {
+	db        *bbolt.DB
+	purchases *purchaseStore
+	progress  *progressStore
+	ledger    *ledgerStore
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx repository.TxRepositories) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return u.db.Update(func(tx *bbolt.Tx) error {
+		return fn(ctx, repository.TxRepositories{
+			Purchases: u.purchases.inTx(tx),
+			Progress:  u.progress.inTx(tx),
+			Ledger:    u.ledger.inTx(tx),
+		})
+	})
+}
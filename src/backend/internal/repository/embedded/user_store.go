@@ -0,0 +1,207 @@
+package embedded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type userStore struct {
+	users collection[dao.UserDao]
+}
+
+func newUserStore(db *bbolt.DB) *userStore {
+	return &userStore{users: newCollection(db, userBucket, func(user dao.UserDao) bson.ObjectID { return user.ID })}
+}
+
+// withoutAvatar imita a projeção usada pelo MongoDB nas buscas de usuário
+func withoutAvatar(user dao.UserDao) dao.UserDao {
+	user.Avatar = bson.Binary{}
+	return user
+}
+
+func (s *userStore) FindUserByEmail(ctx context.Context, email string) (dao.UserDao, error) {
+	normalizedEmail := dao.NormalizeEmail(email)
+	user, err := s.users.findOne(ctx, func(user dao.UserDao) bool { return user.Email == normalizedEmail })
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dao.UserDao{}, fmt.Errorf("usuário com email %s não encontrado", email)
+	}
+	if err != nil {
+		return dao.UserDao{}, fmt.Errorf("erro ao buscar usuário: %v", err)
+	}
+	return withoutAvatar(user), nil
+}
+
+func (s *userStore) FindUserByID(ctx context.Context, id string) (dao.UserDao, error) {
+	objID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return dao.UserDao{}, errors.New("id de usuário inválido")
+	}
+
+	user, err := s.find(ctx, objID)
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+	return withoutAvatar(user), nil
+}
+
+func (s *userStore) find(ctx context.Context, userID bson.ObjectID) (dao.UserDao, error) {
+	user, err := s.users.get(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dao.UserDao{}, errors.New("usuário não encontrado")
+	}
+	if err != nil {
+		return dao.UserDao{}, fmt.Errorf("erro ao buscar usuário: %v", err)
+	}
+	return user, nil
+}
+
+// CreateUser garante o email único na mesma transação da inserção
+func (s *userStore) CreateUser(ctx context.Context, user dao.UserDao) (dao.UserDao, error) {
+	newUser := dao.UserDao{
+		ID:              bson.NewObjectID(),
+		Name:            user.Name,
+		Email:           dao.NormalizeEmail(user.Email),
+		Password:        user.Password,
+		EmailUnverified: true,
+		CreatedAt:       time.Now(),
+	}
+
+	inserted, err := s.users.insertUnless(ctx, newUser, func(existing dao.UserDao) bool {
+		return existing.Email == newUser.Email
+	})
+	if err != nil {
+		return dao.UserDao{}, fmt.Errorf("falha ao inserir usuário: %v", err)
+	}
+	if !inserted {
+		return dao.UserDao{}, errors.New("usuário com este email já existe")
+	}
+	return newUser, nil
+}
+
+func (s *userStore) GetUserProfile(ctx context.Context, userID bson.ObjectID) (dao.UserDao, error) {
+	user, err := s.find(ctx, userID)
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+
+	profile := withoutAvatar(user)
+	profile.Password = ""
+	if len(user.Avatar.Data) > 0 {
+		profile.Avatar = bson.Binary{Subtype: 0, Data: []byte{}}
+	}
+	return profile, nil
+}
+
+func (s *userStore) HasAvatar(ctx context.Context, userID bson.ObjectID) bool {
+	user, err := s.users.get(ctx, userID)
+	return err == nil && len(user.Avatar.Data) > 0
+}
+
+func (s *userStore) GetUserAvatar(ctx context.Context, userID bson.ObjectID) (bson.Binary, string, error) {
+	user, err := s.find(ctx, userID)
+	if err != nil {
+		return bson.Binary{}, "", err
+	}
+	return user.Avatar, user.AvatarMimeType, nil
+}
+
+// UpdateUserProfile aplica os campos pelo nome bson, como o $set do MongoDB
+func (s *userStore) UpdateUserProfile(ctx context.Context, userID bson.ObjectID, updates bson.M) error {
+	var setErr error
+	updated, err := s.users.updateID(ctx, userID, func(user *dao.UserDao) bool {
+		*user, setErr = setFields(*user, updates)
+		return setErr == nil
+	})
+	if setErr != nil {
+		return setErr
+	}
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errors.New("usuário não encontrado")
+	}
+	return nil
+}
+
+// setFields faz o caminho documento -> bson.M -> documento para aplicar os campos pelo nome bson
+func setFields(user dao.UserDao, updates bson.M) (dao.UserDao, error) {
+	raw, err := bson.Marshal(user)
+	if err != nil {
+		return user, err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return user, err
+	}
+
+	for key, value := range updates {
+		document[key] = value
+	}
+	document["updated_at"] = time.Now()
+
+	raw, err = bson.Marshal(document)
+	if err != nil {
+		return user, err
+	}
+	var updated dao.UserDao
+	if err := bson.Unmarshal(raw, &updated); err != nil {
+		return user, err
+	}
+	return updated, nil
+}
+
+func (s *userStore) CountUsers(ctx context.Context) (int64, error) {
+	return s.users.count(ctx)
+}
+
+func (s *userStore) FindUserByGoogleSubject(ctx context.Context, subject string) (dao.UserDao, error) {
+	user, err := s.users.findOne(ctx, func(user dao.UserDao) bool {
+		return user.GoogleSubject != "" && user.GoogleSubject == subject
+	})
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+	return withoutAvatar(user), nil
+}
+
+func (s *userStore) ReplacePasswordHash(ctx context.Context, userID bson.ObjectID, currentHash, newHash string) (bool, error) {
+	return s.users.updateID(ctx, userID, func(user *dao.UserDao) bool {
+		if user.Password != currentHash {
+			return false
+		}
+		user.Password = newHash
+		user.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (s *userStore) RecordMFAStep(ctx context.Context, userID bson.ObjectID, step int64) (bool, error) {
+	return s.users.updateID(ctx, userID, func(user *dao.UserDao) bool {
+		if user.MFALastStep >= step && user.MFALastStep != 0 {
+			return false
+		}
+		user.MFALastStep = step
+		return true
+	})
+}
+
+func (s *userStore) ConsumeRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) (bool, error) {
+	return s.users.updateID(ctx, userID, func(user *dao.UserDao) bool {
+		for i, code := range user.MFARecoveryCodes {
+			if code == codeHash {
+				user.MFARecoveryCodes = append(user.MFARecoveryCodes[:i:i], user.MFARecoveryCodes[i+1:]...)
+				user.UpdatedAt = time.Now()
+				return true
+			}
+		}
+		return false
+	})
+}
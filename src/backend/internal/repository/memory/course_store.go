@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type courseStore struct {
+	mu      sync.RWMutex
+	courses []dao.CourseDao // Ordem de inserção, como a ordem natural da collection
+}
+
+func newCourseStore() *courseStore {
+	return &courseStore{}
+}
+
+func (s *courseStore) GetAllCourses(ctx context.Context) ([]dao.CourseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneCourses(s.courses), nil
+}
+
+func (s *courseStore) GetLastTwoCourses(ctx context.Context) ([]dao.CourseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	courses := cloneCourses(s.courses)
+	s.mu.RUnlock()
+
+	sort.SliceStable(courses, func(i, j int) bool {
+		return courses[i].CreatedAt.After(courses[j].CreatedAt)
+	})
+	if len(courses) > 2 {
+		courses = courses[:2]
+	}
+	return courses, nil
+}
+
+func (s *courseStore) FindByID(ctx context.Context, courseID bson.ObjectID) (dao.CourseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.CourseDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, course := range s.courses {
+		if course.ID == courseID {
+			return cloneCourse(course), nil
+		}
+	}
+	return dao.CourseDao{}, mongo.ErrNoDocuments
+}
+
+func (s *courseStore) CreateCourse(ctx context.Context, course dao.CourseDao) (dao.CourseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.CourseDao{}, err
+	}
+	course.ID = bson.NewObjectID()
+	course.CreatedAt = time.Now()
+	course.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.courses = append(s.courses, cloneCourse(course))
+	return course, nil
+}
+
+func (s *courseStore) UpdateCourseImage(ctx context.Context, courseID bson.ObjectID, data []byte, contentType string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.courses {
+		if s.courses[i].ID == courseID {
+			s.courses[i].ImageData = cloneBinary(bson.Binary{Subtype: 0, Data: data})
+			s.courses[i].ImageType = contentType
+			s.courses[i].UpdatedAt = time.Now()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func cloneCourse(course dao.CourseDao) dao.CourseDao {
+	course.ImageData = cloneBinary(course.ImageData)
+	return course
+}
+
+func cloneCourses(courses []dao.CourseDao) []dao.CourseDao {
+	cloned := make([]dao.CourseDao, 0, len(courses))
+	for _, course := range courses {
+		cloned = append(cloned, cloneCourse(course))
+	}
+	return cloned
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type imageStore struct {
+	mu     sync.RWMutex
+	images []dao.ImageDao
+}
+
+func newImageStore() *imageStore {
+	return &imageStore{}
+}
+
+func (s *imageStore) CreateImage(ctx context.Context, name string, data []byte, mimeType string) (dao.ImageDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.ImageDao{}, err
+	}
+	image := dao.ImageDao{
+		ID:        bson.NewObjectID(),
+		Name:      name,
+		Data:      cloneBinary(bson.Binary{Subtype: 0, Data: data}),
+		MimeType:  mimeType,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.images = append(s.images, image)
+	return cloneImage(image), nil
+}
+
+func (s *imageStore) FindImageByName(ctx context.Context, name string) (dao.ImageDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.ImageDao{}, err
+	}
+	return s.find(func(image dao.ImageDao) bool { return image.Name == name })
+}
+
+func (s *imageStore) FindImageByID(ctx context.Context, id bson.ObjectID) (dao.ImageDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.ImageDao{}, err
+	}
+	return s.find(func(image dao.ImageDao) bool { return image.ID == id })
+}
+
+func (s *imageStore) find(match func(dao.ImageDao) bool) (dao.ImageDao, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, image := range s.images {
+		if match(image) {
+			return cloneImage(image), nil
+		}
+	}
+	return dao.ImageDao{}, mongo.ErrNoDocuments
+}
+
+func (s *imageStore) UpdateImage(ctx context.Context, id bson.ObjectID, name string, data []byte, mimeType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.images {
+		if s.images[i].ID == id {
+			s.images[i].Name = name
+			s.images[i].Data = cloneBinary(bson.Binary{Subtype: 0, Data: data})
+			s.images[i].MimeType = mimeType
+			s.images[i].UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (s *imageStore) DeleteImage(ctx context.Context, id bson.ObjectID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.images {
+		if s.images[i].ID == id {
+			s.images = append(s.images[:i], s.images[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func cloneImage(image dao.ImageDao) dao.ImageDao {
+	image.Data = cloneBinary(image.Data)
+	return image
+}
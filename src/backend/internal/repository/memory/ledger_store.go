@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ledgerStore struct {
+	mu      sync.RWMutex
+	entries []dao.LedgerEntryDao
+}
+
+func newLedgerStore() *ledgerStore {
+	return &ledgerStore{}
+}
+
+func (s *ledgerStore) RecordEntry(ctx context.Context, entry dao.LedgerEntryDao) (dao.LedgerEntryDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.LedgerEntryDao{}, err
+	}
+	entry.ID = bson.NewObjectID()
+	entry.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.entries {
+		if existing.PurchaseID == entry.PurchaseID && existing.Type == entry.Type {
+			return dao.LedgerEntryDao{}, dao.ErrLedgerEntryExists
+		}
+	}
+	s.entries = append(s.entries, entry)
+	return entry, nil
+}
+
+func (s *ledgerStore) FindByPurchase(ctx context.Context, purchaseID bson.ObjectID, entryType string) (dao.LedgerEntryDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.LedgerEntryDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, entry := range s.entries {
+		if entry.PurchaseID == purchaseID && entry.Type == entryType {
+			return entry, nil
+		}
+	}
+	return dao.LedgerEntryDao{}, mongo.ErrNoDocuments
+}
+
+// remove é usado pela unidade de trabalho para desfazer escritas
+func (s *ledgerStore) remove(entryID bson.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, entry := range s.entries {
+		if entry.ID == entryID {
+			s.entries = append(s.entries[:i:i], s.entries[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type lessonStore struct {
+	mu      sync.RWMutex
+	lessons []dao.LessonDao
+}
+
+func newLessonStore() *lessonStore {
+	return &lessonStore{}
+}
+
+func (s *lessonStore) GetLessonsByIDs(ctx context.Context, lessonIDs []bson.ObjectID) ([]dao.LessonDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	wanted := make(map[bson.ObjectID]bool, len(lessonIDs))
+	for _, id := range lessonIDs {
+		wanted[id] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var lessons []dao.LessonDao
+	for _, lesson := range s.lessons {
+		if wanted[lesson.ID] {
+			lessons = append(lessons, lesson)
+		}
+	}
+	return lessons, nil
+}
+
+func (s *lessonStore) CreateLesson(ctx context.Context, lesson dao.LessonDao) (dao.LessonDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.LessonDao{}, err
+	}
+	lesson.ID = bson.NewObjectID()
+	lesson.CreatedAt = time.Now()
+	lesson.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lessons = append(s.lessons, lesson)
+	return lesson, nil
+}
@@ -0,0 +1,36 @@
+// Package memory implementa os repositórios em memória, com o mesmo
+// comportamento observável da implementação em MongoDB (ver repotest).
+// Cada chamada a New começa com os dados vazios.
+package memory
+
+import (
+	"metabee/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// New retorna um conjunto de repositórios vazio. Auth não é preenchido: o
+// estado de autenticação só existe nos backends mongodb e embedded.
+func New() repository.Repositories {
+	purchases, progress, ledger := newPurchaseStore(), newProgressStore(), newLedgerStore()
+	return repository.Repositories{
+		Users:      newUserStore(),
+		Courses:    newCourseStore(),
+		Lessons:    newLessonStore(),
+		Purchases:  purchases,
+		News:       newNewsStore(),
+		Images:     newImageStore(),
+		Progress:   progress,
+		Ledger:     ledger,
+		Sync:       newSyncStore(progress),
+		UnitOfWork: newUnitOfWork(purchases, progress, ledger),
+	}
+}
+
+// cloneBinary evita que o chamador altere os bytes guardados na memória
+func cloneBinary(binary bson.Binary) bson.Binary {
+	if binary.Data == nil {
+		return binary
+	}
+	return bson.Binary{Subtype: binary.Subtype, Data: append([]byte(nil), binary.Data...)}
+}
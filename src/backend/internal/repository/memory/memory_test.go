@@ -0,0 +1,13 @@
+package memory_test
+
+import (
+	"metabee/internal/repository"
+	"metabee/internal/repository/memory"
+	"metabee/internal/repository/repotest"
+	"testing"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	t.Parallel()
+	repotest.Run(t, func(t *testing.T) repository.Repositories { return memory.New() })
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type newsStore struct {
+	mu   sync.RWMutex
+	news []dao.NewsDao
+}
+
+func newNewsStore() *newsStore {
+	return &newsStore{}
+}
+
+func (s *newsStore) GetAllNews(ctx context.Context) ([]dao.NewsDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.GetLastNews(ctx, 0)
+}
+
+// GetLastNews com limit <= 0 retorna todas, como SetLimit(0) no MongoDB
+func (s *newsStore) GetLastNews(ctx context.Context, limit int) ([]dao.NewsDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	news := append([]dao.NewsDao(nil), s.news...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(news, func(i, j int) bool {
+		return news[i].Date.After(news[j].Date)
+	})
+	if limit > 0 && len(news) > limit {
+		news = news[:limit]
+	}
+	return news, nil
+}
+
+func (s *newsStore) FindByID(ctx context.Context, newsID bson.ObjectID) (dao.NewsDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.NewsDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, news := range s.news {
+		if news.ID == newsID {
+			return news, nil
+		}
+	}
+	return dao.NewsDao{}, mongo.ErrNoDocuments
+}
+
+func (s *newsStore) CreateNews(ctx context.Context, news dao.NewsDao) (dao.NewsDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.NewsDao{}, err
+	}
+	news.ID = bson.NewObjectID()
+	if news.Date.IsZero() {
+		news.Date = time.Now()
+	}
+	news.CreatedAt = time.Now()
+	news.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.news = append(s.news, news)
+	return news, nil
+}
@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type progressStore struct {
+	mu         sync.RWMutex
+	progresses []dao.UserProgressDao
+	sessions   []dao.StudySessionDao
+}
+
+func newProgressStore() *progressStore {
+	return &progressStore{}
+}
+
+func (s *progressStore) GetActiveCourses(ctx context.Context, userID bson.ObjectID) ([]dao.UserProgressDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var active []dao.UserProgressDao
+	for _, progress := range s.progresses {
+		if progress.UserID == userID && progress.Progress > 0 && progress.Progress < 100 {
+			active = append(active, progress)
+		}
+	}
+	return active, nil
+}
+
+func (s *progressStore) GetHoursThisMonth(ctx context.Context, userID bson.ObjectID) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0.0
+	for _, session := range s.sessions {
+		if session.UserID == userID && !session.Date.Before(startOfMonth) {
+			total += session.Duration
+		}
+	}
+	return total, nil
+}
+
+func (s *progressStore) GetAverageProgress(ctx context.Context, userID bson.ObjectID) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total, count := 0.0, 0
+	for _, progress := range s.progresses {
+		if progress.UserID == userID {
+			total += progress.Progress
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return total / float64(count), nil
+}
+
+func (s *progressStore) GetRecentLessons(ctx context.Context, userID bson.ObjectID, limit int) ([]dao.StudySessionDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	var sessions []dao.StudySessionDao
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Date.After(sessions[j].Date)
+	})
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (s *progressStore) SaveProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserProgressDao{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.progresses {
+		existing := &s.progresses[i]
+		if existing.UserID == progress.UserID && existing.CourseID == progress.CourseID {
+			existing.Progress = progress.Progress
+			existing.Hours = progress.Hours
+			existing.LastStudy = progress.LastStudy
+			existing.UpdatedAt = now
+			return *existing, nil
+		}
+	}
+
+	saved := dao.UserProgressDao{
+		ID:        bson.NewObjectID(),
+		UserID:    progress.UserID,
+		CourseID:  progress.CourseID,
+		Progress:  progress.Progress,
+		Hours:     progress.Hours,
+		LastStudy: progress.LastStudy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.progresses = append(s.progresses, saved)
+	return saved, nil
+}
+
+func (s *progressStore) EnsureProgress(ctx context.Context, userID, courseID bson.ObjectID) (dao.UserProgressDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserProgressDao{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, found := s.findProgress(userID, courseID); found {
+		return existing, nil
+	}
+	now := time.Now()
+	created := dao.UserProgressDao{
+		ID:        bson.NewObjectID(),
+		UserID:    userID,
+		CourseID:  courseID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.progresses = append(s.progresses, created)
+	return created, nil
+}
+
+// findProgress deve ser chamado com o lock
+func (s *progressStore) findProgress(userID, courseID bson.ObjectID) (dao.UserProgressDao, bool) {
+	for _, progress := range s.progresses {
+		if progress.UserID == userID && progress.CourseID == courseID {
+			return progress, true
+		}
+	}
+	return dao.UserProgressDao{}, false
+}
+
+func (s *progressStore) CreateStudySession(ctx context.Context, session dao.StudySessionDao) (dao.StudySessionDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.StudySessionDao{}, err
+	}
+	session.ID = bson.NewObjectID()
+	session.CreatedAt = time.Now()
+	if session.Date.IsZero() {
+		session.Date = session.CreatedAt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = append(s.sessions, session)
+	return session, nil
+}
+
+// Usados pela unidade de trabalho para desfazer escritas
+
+func (s *progressStore) lookupProgress(userID, courseID bson.ObjectID) (dao.UserProgressDao, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findProgress(userID, courseID)
+}
+
+func (s *progressStore) removeProgress(progressID bson.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, progress := range s.progresses {
+		if progress.ID == progressID {
+			s.progresses = append(s.progresses[:i:i], s.progresses[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *progressStore) restoreProgress(previous dao.UserProgressDao) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.progresses {
+		if s.progresses[i].ID == previous.ID {
+			s.progresses[i] = previous
+			return
+		}
+	}
+}
+
+func (s *progressStore) removeSession(sessionID bson.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, session := range s.sessions {
+		if session.ID == sessionID {
+			s.sessions = append(s.sessions[:i:i], s.sessions[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type purchaseStore struct {
+	mu        sync.RWMutex
+	purchases []dao.PurchaseDao
+}
+
+func newPurchaseStore() *purchaseStore {
+	return &purchaseStore{}
+}
+
+func (s *purchaseStore) CreatePurchase(ctx context.Context, userID, courseID bson.ObjectID, driveLink string) (dao.PurchaseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.PurchaseDao{}, err
+	}
+	purchase := dao.PurchaseDao{
+		ID:        bson.NewObjectID(),
+		UserID:    userID,
+		CourseID:  courseID,
+		Status:    "pending",
+		DriveLink: driveLink,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.purchases {
+		if existing.UserID == userID && existing.CourseID == courseID {
+			return dao.PurchaseDao{}, dao.ErrPurchaseExists
+		}
+	}
+	s.purchases = append(s.purchases, purchase)
+	return purchase, nil
+}
+
+func (s *purchaseStore) GetPurchasesByUser(ctx context.Context, userID bson.ObjectID) ([]dao.PurchaseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var purchases []dao.PurchaseDao
+	for _, purchase := range s.purchases {
+		if purchase.UserID == userID {
+			purchases = append(purchases, purchase)
+		}
+	}
+	return purchases, nil
+}
+
+func (s *purchaseStore) GetPurchaseByUserAndCourse(ctx context.Context, userID, courseID bson.ObjectID) (dao.PurchaseDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.PurchaseDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, purchase := range s.purchases {
+		if purchase.UserID == userID && purchase.CourseID == courseID {
+			return purchase, nil
+		}
+	}
+	return dao.PurchaseDao{}, mongo.ErrNoDocuments
+}
+
+func (s *purchaseStore) UpdatePurchaseStatus(ctx context.Context, purchaseID bson.ObjectID, status string, localPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.purchases {
+		if s.purchases[i].ID == purchaseID {
+			s.purchases[i].Status = status
+			s.purchases[i].UpdatedAt = time.Now()
+			if localPath != "" {
+				s.purchases[i].LocalPath = localPath
+			}
+		}
+	}
+	return nil
+}
+
+// findByID e os métodos abaixo são usados pela unidade de trabalho para desfazer escritas
+
+func (s *purchaseStore) findByID(purchaseID bson.ObjectID) (dao.PurchaseDao, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, purchase := range s.purchases {
+		if purchase.ID == purchaseID {
+			return purchase, true
+		}
+	}
+	return dao.PurchaseDao{}, false
+}
+
+func (s *purchaseStore) remove(purchaseID bson.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, purchase := range s.purchases {
+		if purchase.ID == purchaseID {
+			s.purchases = append(s.purchases[:i:i], s.purchases[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *purchaseStore) restore(previous dao.PurchaseDao) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.purchases {
+		if s.purchases[i].ID == previous.ID {
+			s.purchases[i] = previous
+			return
+		}
+	}
+}
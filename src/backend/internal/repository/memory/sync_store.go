@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// syncStore grava nos mesmos dados de progressStore, sob o mesmo lock
+type syncStore struct {
+	progress *progressStore
+	notes    []dao.NoteDao
+	counters map[bson.ObjectID]int64
+	states   map[bson.ObjectID]dao.SyncStateDao
+}
+
+func newSyncStore(progress *progressStore) *syncStore {
+	return &syncStore{
+		progress: progress,
+		counters: map[bson.ObjectID]int64{},
+		states:   map[bson.ObjectID]dao.SyncStateDao{},
+	}
+}
+
+// nextSeq deve ser chamado com o lock de escrita
+func (s *syncStore) nextSeq(userID bson.ObjectID) int64 {
+	s.counters[userID]++
+	return s.counters[userID]
+}
+
+func (s *syncStore) ApplyStudySession(ctx context.Context, session dao.StudySessionDao) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+
+	for _, existing := range s.progress.sessions {
+		if existing.ID == session.ID {
+			return false, nil
+		}
+	}
+	session.Seq = s.nextSeq(session.UserID)
+	session.CreatedAt = time.Now()
+	s.progress.sessions = append(s.progress.sessions, session)
+	s.syncHours(session.UserID, session.CourseID)
+	return true, nil
+}
+
+// syncHours grava nas horas do progresso a soma das sessões de estudo do curso,
+// criando o progresso se preciso. Deve ser chamado com o lock de escrita.
+func (s *syncStore) syncHours(userID, courseID bson.ObjectID) {
+	hours := 0.0
+	for _, session := range s.progress.sessions {
+		if session.UserID == userID && session.CourseID == courseID {
+			hours += session.Duration
+		}
+	}
+
+	now := time.Now()
+	for i := range s.progress.progresses {
+		existing := &s.progress.progresses[i]
+		if existing.UserID == userID && existing.CourseID == courseID {
+			if existing.Hours < hours {
+				existing.Hours = hours
+				existing.Seq = s.nextSeq(userID)
+				existing.UpdatedAt = now
+			}
+			return
+		}
+	}
+	s.progress.progresses = append(s.progress.progresses, dao.UserProgressDao{
+		ID:        bson.NewObjectID(),
+		UserID:    userID,
+		CourseID:  courseID,
+		Hours:     hours,
+		Seq:       s.nextSeq(userID),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *syncStore) ApplyProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserProgressDao{}, false, err
+	}
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.progress.progresses {
+		existing := &s.progress.progresses[i]
+		if existing.UserID == progress.UserID && existing.CourseID == progress.CourseID {
+			merged, changed := existing.Merge(progress)
+			if !changed {
+				return *existing, false, nil
+			}
+			merged.Seq = s.nextSeq(progress.UserID)
+			merged.UpdatedAt = now
+			*existing = merged
+			return merged, true, nil
+		}
+	}
+
+	saved := dao.UserProgressDao{
+		ID:        bson.NewObjectID(),
+		UserID:    progress.UserID,
+		CourseID:  progress.CourseID,
+		Progress:  progress.Progress,
+		LastStudy: progress.LastStudy,
+		Seq:       s.nextSeq(progress.UserID),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.progress.progresses = append(s.progress.progresses, saved)
+	return saved, true, nil
+}
+
+func (s *syncStore) ApplyNote(ctx context.Context, note dao.NoteDao) (dao.NoteDao, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.NoteDao{}, false, err
+	}
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+
+	for i := range s.notes {
+		existing := &s.notes[i]
+		if existing.ID != note.ID {
+			continue
+		}
+		if existing.UserID != note.UserID {
+			return dao.NoteDao{}, false, mongo.ErrNoDocuments
+		}
+		if !note.Supersedes(*existing) {
+			return *existing, false, nil
+		}
+		note.Seq = s.nextSeq(note.UserID)
+		*existing = note
+		return note, true, nil
+	}
+
+	note.Seq = s.nextSeq(note.UserID)
+	s.notes = append(s.notes, note)
+	return note, true, nil
+}
+
+func (s *syncStore) Changes(ctx context.Context, userID bson.ObjectID, since int64, limit int) (dao.SyncChanges, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.SyncChanges{}, err
+	}
+	s.progress.mu.RLock()
+	defer s.progress.mu.RUnlock()
+
+	var changes dao.SyncChanges
+	for _, session := range s.progress.sessions {
+		if session.UserID == userID && session.Seq > since {
+			changes.StudySessions = append(changes.StudySessions, session)
+		}
+	}
+	for _, progress := range s.progress.progresses {
+		if progress.UserID == userID && progress.Seq > since {
+			changes.Progress = append(changes.Progress, progress)
+		}
+	}
+	for _, note := range s.notes {
+		if note.UserID == userID && note.Seq > since {
+			changes.Notes = append(changes.Notes, note)
+		}
+	}
+	return changes.Page(since, limit), nil
+}
+
+func (s *syncStore) LatestSeq(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.progress.mu.RLock()
+	defer s.progress.mu.RUnlock()
+	return s.counters[userID], nil
+}
+
+func (s *syncStore) GetSyncState(ctx context.Context, userID bson.ObjectID) (dao.SyncStateDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.SyncStateDao{}, err
+	}
+	s.progress.mu.RLock()
+	defer s.progress.mu.RUnlock()
+
+	state, ok := s.states[userID]
+	if !ok {
+		return dao.SyncStateDao{UserID: userID}, nil
+	}
+	return state, nil
+}
+
+func (s *syncStore) SaveSyncState(ctx context.Context, state dao.SyncStateDao) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.progress.mu.Lock()
+	defer s.progress.mu.Unlock()
+	s.states[state.UserID] = state
+	return nil
+}
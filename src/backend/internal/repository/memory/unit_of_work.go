@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// unitOfWork executa uma unidade por vez e, se fn falhar, desfaz na ordem
+// inversa o que os repositórios da unidade gravaram. As escritas ficam visíveis
+// para as demais chamadas antes do fim da unidade.
+type unitOfWork struct {
+	running   chan struct{} // Semáforo: Do respeita o cancelamento de ctx enquanto espera
+	purchases *purchaseStore
+	progress  *progressStore
+	ledger    *ledgerStore
+}
+
+func newUnitOfWork(purchases *purchaseStore, progress *progressStore, ledger *ledgerStore) *unitOfWork {
+	return &unitOfWork{running: make(chan struct{}, 1), purchases: purchases, progress: progress, ledger: ledger}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx repository.TxRepositories) error) error {
+	select {
+	case u.running <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-u.running }()
+
+	undo := &undoLog{}
+	tx := repository.TxRepositories{
+		Purchases: &txPurchaseStore{purchaseStore: u.purchases, undo: undo},
+		Progress:  &txProgressStore{progressStore: u.progress, undo: undo},
+		Ledger:    &txLedgerStore{ledgerStore: u.ledger, undo: undo},
+	}
+	if err := fn(ctx, tx); err != nil {
+		undo.rollback()
+		return err
+	}
+	return nil
+}
+
+type undoLog struct {
+	steps []func()
+}
+
+func (l *undoLog) add(step func()) {
+	l.steps = append(l.steps, step)
+}
+
+func (l *undoLog) rollback() {
+	for i := len(l.steps) - 1; i >= 0; i-- {
+		l.steps[i]()
+	}
+}
+
+// Repositórios da unidade: registram como desfazer cada escrita bem-sucedida
+
+type txPurchaseStore struct {
+	*purchaseStore
+	undo *undoLog
+}
+
+func (s *txPurchaseStore) CreatePurchase(ctx context.Context, userID, courseID bson.ObjectID, driveLink string) (dao.PurchaseDao, error) {
+	purchase, err := s.purchaseStore.CreatePurchase(ctx, userID, courseID, driveLink)
+	if err == nil {
+		s.undo.add(func() { s.purchaseStore.remove(purchase.ID) })
+	}
+	return purchase, err
+}
+
+func (s *txPurchaseStore) UpdatePurchaseStatus(ctx context.Context, purchaseID bson.ObjectID, status string, localPath string) error {
+	previous, found := s.purchaseStore.findByID(purchaseID)
+	err := s.purchaseStore.UpdatePurchaseStatus(ctx, purchaseID, status, localPath)
+	if err == nil && found {
+		s.undo.add(func() { s.purchaseStore.restore(previous) })
+	}
+	return err
+}
+
+type txProgressStore struct {
+	*progressStore
+	undo *undoLog
+}
+
+// undoProgress desfaz a gravação do par usuário/curso: restaura previous ou,
+// se ele não existia, remove o registro criado
+func (s *txProgressStore) undoProgress(previous dao.UserProgressDao, existed bool, saved dao.UserProgressDao) {
+	if existed {
+		s.undo.add(func() { s.progressStore.restoreProgress(previous) })
+	} else {
+		s.undo.add(func() { s.progressStore.removeProgress(saved.ID) })
+	}
+}
+
+func (s *txProgressStore) SaveProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, error) {
+	previous, existed := s.progressStore.lookupProgress(progress.UserID, progress.CourseID)
+	saved, err := s.progressStore.SaveProgress(ctx, progress)
+	if err == nil {
+		s.undoProgress(previous, existed, saved)
+	}
+	return saved, err
+}
+
+func (s *txProgressStore) EnsureProgress(ctx context.Context, userID, courseID bson.ObjectID) (dao.UserProgressDao, error) {
+	previous, existed := s.progressStore.lookupProgress(userID, courseID)
+	saved, err := s.progressStore.EnsureProgress(ctx, userID, courseID)
+	if err == nil {
+		s.undoProgress(previous, existed, saved)
+	}
+	return saved, err
+}
+
+func (s *txProgressStore) CreateStudySession(ctx context.Context, session dao.StudySessionDao) (dao.StudySessionDao, error) {
+	created, err := s.progressStore.CreateStudySession(ctx, session)
+	if err == nil {
+		s.undo.add(func() { s.progressStore.removeSession(created.ID) })
+	}
+	return created, err
+}
+
+type txLedgerStore struct {
+	*ledgerStore
+	undo *undoLog
+}
+
+func (s *txLedgerStore) RecordEntry(ctx context.Context, entry dao.LedgerEntryDao) (dao.LedgerEntryDao, error) {
+	recorded, err := s.ledgerStore.RecordEntry(ctx, entry)
+	if err == nil {
+		s.undo.add(func() { s.ledgerStore.remove(recorded.ID) })
+	}
+	return recorded, err
+}
@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"metabee/internal/model/dao"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type userStore struct {
+	mu    sync.RWMutex
+	users map[bson.ObjectID]dao.UserDao
+}
+
+func newUserStore() *userStore {
+	return &userStore{users: make(map[bson.ObjectID]dao.UserDao)}
+}
+
+// withoutAvatar imita a projeção usada pelo MongoDB nas buscas de usuário
+func withoutAvatar(user dao.UserDao) dao.UserDao {
+	user.Avatar = bson.Binary{}
+	user.MFARecoveryCodes = append([]string(nil), user.MFARecoveryCodes...)
+	return user
+}
+
+func (s *userStore) FindUserByEmail(ctx context.Context, email string) (dao.UserDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	normalizedEmail := dao.NormalizeEmail(email)
+	for _, user := range s.users {
+		if user.Email == normalizedEmail {
+			return withoutAvatar(user), nil
+		}
+	}
+	return dao.UserDao{}, fmt.Errorf("usuário com email %s não encontrado", email)
+}
+
+func (s *userStore) FindUserByID(ctx context.Context, id string) (dao.UserDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserDao{}, err
+	}
+	objID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return dao.UserDao{}, errors.New("id de usuário inválido")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, found := s.users[objID]
+	if !found {
+		return dao.UserDao{}, errors.New("usuário não encontrado")
+	}
+	return withoutAvatar(user), nil
+}
+
+func (s *userStore) CreateUser(ctx context.Context, user dao.UserDao) (dao.UserDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserDao{}, err
+	}
+	if _, err := s.FindUserByEmail(ctx, user.Email); err == nil {
+		return dao.UserDao{}, errors.New("usuário com este email já existe")
+	}
+
+	newUser := dao.UserDao{
+		ID:              bson.NewObjectID(),
+		Name:            user.Name,
+		Email:           dao.NormalizeEmail(user.Email),
+		Password:        user.Password,
+		EmailUnverified: true,
+		CreatedAt:       time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[newUser.ID] = newUser
+	return newUser, nil
+}
+
+func (s *userStore) GetUserProfile(ctx context.Context, userID bson.ObjectID) (dao.UserDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, found := s.users[userID]
+	if !found {
+		return dao.UserDao{}, errors.New("usuário não encontrado")
+	}
+
+	profile := withoutAvatar(user)
+	profile.Password = ""
+	if len(user.Avatar.Data) > 0 {
+		profile.Avatar = bson.Binary{Subtype: 0, Data: []byte{}}
+	}
+	return profile, nil
+}
+
+func (s *userStore) HasAvatar(ctx context.Context, userID bson.ObjectID) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, found := s.users[userID]
+	return found && len(user.Avatar.Data) > 0
+}
+
+func (s *userStore) GetUserAvatar(ctx context.Context, userID bson.ObjectID) (bson.Binary, string, error) {
+	if err := ctx.Err(); err != nil {
+		return bson.Binary{}, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, found := s.users[userID]
+	if !found {
+		return bson.Binary{}, "", errors.New("usuário não encontrado")
+	}
+	return cloneBinary(user.Avatar), user.AvatarMimeType, nil
+}
+
+// UpdateUserProfile aplica os campos pelo nome bson, como o $set do MongoDB
+func (s *userStore) UpdateUserProfile(ctx context.Context, userID bson.ObjectID, updates bson.M) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, found := s.users[userID]
+	if !found {
+		return errors.New("usuário não encontrado")
+	}
+
+	raw, err := bson.Marshal(user)
+	if err != nil {
+		return err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return err
+	}
+
+	for key, value := range updates {
+		document[key] = value
+	}
+	document["updated_at"] = time.Now()
+
+	raw, err = bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	var updated dao.UserDao
+	if err := bson.Unmarshal(raw, &updated); err != nil {
+		return err
+	}
+
+	s.users[userID] = updated
+	return nil
+}
+
+func (s *userStore) CountUsers(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.users)), nil
+}
+
+func (s *userStore) FindUserByGoogleSubject(ctx context.Context, subject string) (dao.UserDao, error) {
+	if err := ctx.Err(); err != nil {
+		return dao.UserDao{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.GoogleSubject != "" && user.GoogleSubject == subject {
+			return withoutAvatar(user), nil
+		}
+	}
+	return dao.UserDao{}, mongo.ErrNoDocuments
+}
+
+func (s *userStore) ReplacePasswordHash(ctx context.Context, userID bson.ObjectID, currentHash, newHash string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return s.update(userID, func(user *dao.UserDao) bool {
+		if user.Password != currentHash {
+			return false
+		}
+		user.Password = newHash
+		user.UpdatedAt = time.Now()
+		return true
+	}), nil
+}
+
+func (s *userStore) RecordMFAStep(ctx context.Context, userID bson.ObjectID, step int64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return s.update(userID, func(user *dao.UserDao) bool {
+		if user.MFALastStep >= step && user.MFALastStep != 0 {
+			return false
+		}
+		user.MFALastStep = step
+		return true
+	}), nil
+}
+
+func (s *userStore) ConsumeRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return s.update(userID, func(user *dao.UserDao) bool {
+		for i, code := range user.MFARecoveryCodes {
+			if code == codeHash {
+				user.MFARecoveryCodes = append(user.MFARecoveryCodes[:i:i], user.MFARecoveryCodes[i+1:]...)
+				user.UpdatedAt = time.Now()
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// update aplica apply ao usuário sob o lock de escrita; retorna false se o
+// usuário não existir ou apply não alterar nada (como um filtro sem match)
+func (s *userStore) update(userID bson.ObjectID, apply func(user *dao.UserDao) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, found := s.users[userID]
+	if !found || !apply(&user) {
+		return false
+	}
+	s.users[userID] = user
+	return true
+}
@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"context"
+	"metabee/internal/database"
+	"metabee/internal/migration"
+	"metabee/internal/repository"
+	"metabee/internal/repository/repotest"
+	"os"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Sem METABEE_TEST_MONGODB_URI os testes do MongoDB são pulados. Cada subteste
+// usa um banco descartável no servidor indicado; o de UnitOfWork precisa de um
+// replica set (um único nó basta: mongod --replSet rs0).
+const testMongoURIEnv = "METABEE_TEST_MONGODB_URI"
+
+// connectTestMongo conecta database.MongoClient ao servidor de teste. Os DAOs
+// usam database.DB, então estes testes não rodam em paralelo.
+func connectTestMongo(t *testing.T) {
+	uri := os.Getenv(testMongoURIEnv)
+	if uri == "" {
+		t.Skipf("%s não definida", testMongoURIEnv)
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("erro ao conectar no MongoDB: %v", err)
+	}
+	if err := client.Ping(context.Background(), nil); err != nil {
+		t.Fatalf("erro ao fazer ping no MongoDB: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	database.MongoClient = client
+}
+
+// useTestDatabase aponta database.DB para um banco novo, com as migrações
+// aplicadas (os índices únicos fazem parte do contrato), e o apaga no fim
+func useTestDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := database.MongoClient.Database("metabee_test_" + bson.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+	database.DB = db
+
+	if _, err := migration.Run(ctx); err != nil {
+		t.Fatalf("erro ao aplicar as migrações: %v", err)
+	}
+}
+
+func TestMongoRepositories(t *testing.T) {
+	connectTestMongo(t)
+	transactions, err := database.SupportsTransactions(context.Background())
+	if err != nil {
+		t.Fatalf("erro ao consultar o servidor: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repository.Repositories {
+		if strings.HasSuffix(t.Name(), "/UnitOfWork") && !transactions {
+			t.Skip("o servidor de teste não é um replica set")
+		}
+		useTestDatabase(t)
+		return repository.NewMongo()
+	})
+}
+
+func TestMongoAuthRepositories(t *testing.T) {
+	connectTestMongo(t)
+	repotest.RunAuth(t, func(t *testing.T) repository.AuthRepositories {
+		useTestDatabase(t)
+		return repository.NewMongo().Auth
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"metabee/internal/database"
+	"metabee/internal/model/dao"
+)
+
+// mongoUnitOfWork roda a unidade em uma transação. Os DAOs não mudam: o ctx
+// recebido por fn carrega a sessão, e toda operação feita com ele entra na
+// transação. Sem replica set não há transação; as escritas ficam protegidas
+// apenas pelos índices únicos (migrações 4 e 7), e fn deve ser segura para
+// repetir depois de uma falha no meio.
+type mongoUnitOfWork struct{}
+
+func (mongoUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx TxRepositories) error) error {
+	tx := TxRepositories{
+		Purchases: dao.PurchaseDao{},
+		Progress:  dao.UserProgressDao{},
+		Ledger:    dao.LedgerEntryDao{},
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	supported, err := database.SupportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fn(ctx, tx)
+	}
+
+	session, err := database.MongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx, tx)
+	})
+	return err
+}
@@ -0,0 +1,312 @@
+// Package repository define as interfaces de acesso aos dados usadas pelos
+// controllers e serviços. A implementação em MongoDB são os próprios tipos de
+// dao; o pacote embedded guarda tudo em um arquivo local (app desktop sem
+// rede), o pacote memory traz uma implementação em memória para testes, e
+// repotest o conjunto de testes de contrato que todas precisam passar.
+//
+// Todo método recebe o contexto da requisição: se o cliente desconectar, a
+// operação é cancelada. Cada operação ainda tem o limite de
+// [database].queryTimeoutSeconds (database.WithQueryTimeout).
+package repository
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/database"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type UserRepository interface {
+	// FindUserByEmail ignora maiúsculas/minúsculas. O avatar não é carregado.
+	FindUserByEmail(ctx context.Context, email string) (dao.UserDao, error)
+	// FindUserByID recebe o ID em hexadecimal. O avatar não é carregado.
+	FindUserByID(ctx context.Context, id string) (dao.UserDao, error)
+	// CreateUser grava apenas nome, email (normalizado) e hash da senha; a conta nasce com email não verificado
+	CreateUser(ctx context.Context, user dao.UserDao) (dao.UserDao, error)
+	// GetUserProfile retorna o usuário sem a senha e com Avatar vazio (não nil) quando há foto
+	GetUserProfile(ctx context.Context, userID bson.ObjectID) (dao.UserDao, error)
+	HasAvatar(ctx context.Context, userID bson.ObjectID) bool
+	GetUserAvatar(ctx context.Context, userID bson.ObjectID) (bson.Binary, string, error)
+	// UpdateUserProfile aplica os campos (nomes bson) e atualiza updated_at
+	UpdateUserProfile(ctx context.Context, userID bson.ObjectID, updates bson.M) error
+	CountUsers(ctx context.Context) (int64, error)
+	// FindUserByGoogleSubject retorna mongo.ErrNoDocuments quando nenhuma conta está vinculada
+	FindUserByGoogleSubject(ctx context.Context, subject string) (dao.UserDao, error)
+	// ReplacePasswordHash só troca o hash se ele ainda for currentHash
+	ReplacePasswordHash(ctx context.Context, userID bson.ObjectID, currentHash, newHash string) (bool, error)
+	// RecordMFAStep retorna false se o passo (ou um posterior) já tiver sido usado
+	RecordMFAStep(ctx context.Context, userID bson.ObjectID, step int64) (bool, error)
+	// ConsumeRecoveryCode remove o hash do código; retorna false se ele não existir
+	ConsumeRecoveryCode(ctx context.Context, userID bson.ObjectID, codeHash string) (bool, error)
+}
+
+type CourseRepository interface {
+	GetAllCourses(ctx context.Context) ([]dao.CourseDao, error)
+	GetLastTwoCourses(ctx context.Context) ([]dao.CourseDao, error)
+	// FindByID retorna mongo.ErrNoDocuments quando o curso não existe
+	FindByID(ctx context.Context, courseID bson.ObjectID) (dao.CourseDao, error)
+	CreateCourse(ctx context.Context, course dao.CourseDao) (dao.CourseDao, error)
+	UpdateCourseImage(ctx context.Context, courseID bson.ObjectID, data []byte, contentType string) (bool, error)
+}
+
+type LessonRepository interface {
+	GetLessonsByIDs(ctx context.Context, lessonIDs []bson.ObjectID) ([]dao.LessonDao, error)
+	CreateLesson(ctx context.Context, lesson dao.LessonDao) (dao.LessonDao, error)
+}
+
+type PurchaseRepository interface {
+	// CreatePurchase retorna dao.ErrPurchaseExists se o usuário já tiver comprado o curso
+	CreatePurchase(ctx context.Context, userID, courseID bson.ObjectID, driveLink string) (dao.PurchaseDao, error)
+	GetPurchasesByUser(ctx context.Context, userID bson.ObjectID) ([]dao.PurchaseDao, error)
+	// GetPurchaseByUserAndCourse retorna mongo.ErrNoDocuments quando não há compra
+	GetPurchaseByUserAndCourse(ctx context.Context, userID, courseID bson.ObjectID) (dao.PurchaseDao, error)
+	// UpdatePurchaseStatus não falha se a compra não existir; localPath vazio mantém o atual
+	UpdatePurchaseStatus(ctx context.Context, purchaseID bson.ObjectID, status string, localPath string) error
+}
+
+type NewsRepository interface {
+	// GetAllNews e GetLastNews ordenam da notícia mais recente (date) para a mais antiga
+	GetAllNews(ctx context.Context) ([]dao.NewsDao, error)
+	GetLastNews(ctx context.Context, limit int) ([]dao.NewsDao, error)
+	// FindByID retorna mongo.ErrNoDocuments quando a notícia não existe
+	FindByID(ctx context.Context, newsID bson.ObjectID) (dao.NewsDao, error)
+	CreateNews(ctx context.Context, news dao.NewsDao) (dao.NewsDao, error)
+}
+
+type ImageRepository interface {
+	CreateImage(ctx context.Context, name string, data []byte, mimeType string) (dao.ImageDao, error)
+	// FindImageByName e FindImageByID retornam mongo.ErrNoDocuments quando a imagem não existe
+	FindImageByName(ctx context.Context, name string) (dao.ImageDao, error)
+	FindImageByID(ctx context.Context, id bson.ObjectID) (dao.ImageDao, error)
+	UpdateImage(ctx context.Context, id bson.ObjectID, name string, data []byte, mimeType string) error
+	DeleteImage(ctx context.Context, id bson.ObjectID) error
+}
+
+type ProgressRepository interface {
+	// GetActiveCourses retorna os cursos com progresso entre 0 e 100 (exclusivo)
+	GetActiveCourses(ctx context.Context, userID bson.ObjectID) ([]dao.UserProgressDao, error)
+	GetHoursThisMonth(ctx context.Context, userID bson.ObjectID) (float64, error)
+	GetAverageProgress(ctx context.Context, userID bson.ObjectID) (float64, error)
+	// GetRecentLessons retorna as sessões de estudo da mais recente para a mais antiga
+	GetRecentLessons(ctx context.Context, userID bson.ObjectID, limit int) ([]dao.StudySessionDao, error)
+	// SaveProgress cria ou atualiza o progresso do par usuário/curso
+	SaveProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, error)
+	// EnsureProgress cria o progresso zerado do par usuário/curso se ainda não existir e retorna o atual
+	EnsureProgress(ctx context.Context, userID, courseID bson.ObjectID) (dao.UserProgressDao, error)
+	CreateStudySession(ctx context.Context, session dao.StudySessionDao) (dao.StudySessionDao, error)
+}
+
+type LedgerRepository interface {
+	// RecordEntry retorna dao.ErrLedgerEntryExists se a compra já tiver um lançamento do mesmo tipo
+	RecordEntry(ctx context.Context, entry dao.LedgerEntryDao) (dao.LedgerEntryDao, error)
+	// FindByPurchase retorna mongo.ErrNoDocuments quando não há lançamento
+	FindByPurchase(ctx context.Context, purchaseID bson.ObjectID, entryType string) (dao.LedgerEntryDao, error)
+}
+
+// SyncRepository grava os registros trocados entre o app desktop e o servidor
+// central. Cada gravação que altera um registro recebe uma seq nova, maior que
+// todas as anteriores do usuário; Changes lista os registros por seq e nunca
+// pula um registro que ainda esteja sendo gravado com seq menor.
+type SyncRepository interface {
+	// ApplyStudySession insere a sessão com o ID do dispositivo e grava nas horas do progresso
+	// do curso a soma das sessões; retorna false se ela já existir
+	ApplyStudySession(ctx context.Context, session dao.StudySessionDao) (bool, error)
+	// ApplyProgress combina com o progresso atual (dao.UserProgressDao.Merge, que ignora Hours)
+	// e retorna o resultado e se ele mudou
+	ApplyProgress(ctx context.Context, progress dao.UserProgressDao) (dao.UserProgressDao, bool, error)
+	// ApplyNote grava a nota se ela substituir a atual (dao.NoteDao.Supersedes) e retorna a vigente e se ela mudou.
+	// Retorna mongo.ErrNoDocuments se o ID for de uma nota de outro usuário.
+	ApplyNote(ctx context.Context, note dao.NoteDao) (dao.NoteDao, bool, error)
+	// Changes retorna até limit registros do usuário com seq maior que since (dao.SyncChanges.Page)
+	Changes(ctx context.Context, userID bson.ObjectID, since int64, limit int) (dao.SyncChanges, error)
+	// LatestSeq retorna a maior seq até a qual todas as gravações terminaram; 0 se o usuário nunca gravou nada
+	LatestSeq(ctx context.Context, userID bson.ObjectID) (int64, error)
+	// GetSyncState retorna o estado zerado quando o usuário nunca sincronizou
+	GetSyncState(ctx context.Context, userID bson.ObjectID) (dao.SyncStateDao, error)
+	SaveSyncState(ctx context.Context, state dao.SyncStateDao) error
+}
+
+// Estado de autenticação, usado pelos serviços. Os registros com expires_at
+// podem continuar visíveis depois de expirar (o MongoDB remove pelo índice TTL
+// com atraso), então quem consulta confere a expiração.
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token dao.RefreshTokenDao) (dao.RefreshTokenDao, error)
+	// FindByHash retorna mongo.ErrNoDocuments quando o token não existe
+	FindByHash(ctx context.Context, tokenHash string) (dao.RefreshTokenDao, error)
+	// MarkUsed retorna dao.ErrRefreshTokenAlreadyUsed se o token já foi usado ou revogado
+	MarkUsed(ctx context.Context, tokenID, replacedBy bson.ObjectID) error
+	RevokeFamily(ctx context.Context, familyID bson.ObjectID) error
+	RevokeAllForUser(ctx context.Context, userID bson.ObjectID) error
+	RevokeAllForUserExcept(ctx context.Context, userID, keepFamilyID bson.ObjectID) error
+	IsFamilyRevoked(ctx context.Context, familyID bson.ObjectID) (bool, error)
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session dao.SessionDao) (dao.SessionDao, error)
+	// FindActiveByUser lista as sessões não revogadas e não expiradas, da mais recente para a mais antiga
+	FindActiveByUser(ctx context.Context, userID bson.ObjectID) ([]dao.SessionDao, error)
+	// FindByIDForUser retorna mongo.ErrNoDocuments se a sessão não existir ou for de outro usuário
+	FindByIDForUser(ctx context.Context, sessionID, userID bson.ObjectID) (dao.SessionDao, error)
+	Touch(ctx context.Context, sessionID bson.ObjectID, ip string, expiresAt time.Time) error
+	TouchIfIdle(ctx context.Context, sessionID bson.ObjectID, ip string, idleSince time.Time) error
+	Revoke(ctx context.Context, sessionID bson.ObjectID) error
+	RevokeAllForUserExcept(ctx context.Context, userID, keepSessionID bson.ObjectID) error
+}
+
+type RevokedTokenRepository interface {
+	RevokeToken(ctx context.Context, jti string, userID bson.ObjectID, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type PasswordResetRepository interface {
+	CreatePasswordReset(ctx context.Context, reset dao.PasswordResetDao) (dao.PasswordResetDao, error)
+	FindByHash(ctx context.Context, tokenHash string) (dao.PasswordResetDao, error)
+	// MarkUsed retorna dao.ErrPasswordResetAlreadyUsed se o pedido já foi usado
+	MarkUsed(ctx context.Context, resetID bson.ObjectID) error
+	// Release desfaz o MarkUsed quando a troca de senha falha
+	Release(ctx context.Context, resetID bson.ObjectID) error
+	InvalidateForUser(ctx context.Context, userID bson.ObjectID) error
+}
+
+type EmailVerificationRepository interface {
+	CreateEmailVerification(ctx context.Context, verification dao.EmailVerificationDao) (dao.EmailVerificationDao, error)
+	FindByHash(ctx context.Context, tokenHash string) (dao.EmailVerificationDao, error)
+	// MarkUsed retorna dao.ErrEmailVerificationAlreadyUsed se o link já foi usado
+	MarkUsed(ctx context.Context, verificationID bson.ObjectID) error
+	// InvalidateForUser só afeta os links com a finalidade informada (dao.EmailVerificationPurpose*)
+	InvalidateForUser(ctx context.Context, userID bson.ObjectID, purpose string) error
+}
+
+type LoginAttemptRepository interface {
+	// FindByKey retorna um registro vazio (só com Key) quando não há falhas
+	FindByKey(ctx context.Context, key string) (dao.LoginAttemptDao, error)
+	RegisterFailure(ctx context.Context, key string, window time.Duration, retention time.Duration) (dao.LoginAttemptDao, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type OIDCStateRepository interface {
+	CreateOIDCState(ctx context.Context, state dao.OIDCStateDao) error
+	// ConsumeByHash busca e remove o fluxo; retorna mongo.ErrNoDocuments se ele não existir
+	ConsumeByHash(ctx context.Context, stateHash string) (dao.OIDCStateDao, error)
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key dao.APIKeyDao) (dao.APIKeyDao, error)
+	FindByHash(ctx context.Context, keyHash string) (dao.APIKeyDao, error)
+	// FindByUser lista as chaves não revogadas, da mais recente para a mais antiga, sem o hash
+	FindByUser(ctx context.Context, userID bson.ObjectID) ([]dao.APIKeyDao, error)
+	Revoke(ctx context.Context, keyID, userID bson.ObjectID) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID bson.ObjectID) error
+	TouchIfIdle(ctx context.Context, keyID bson.ObjectID, ip string, idleSince time.Time) error
+}
+
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, entry dao.AuditLogDao) error
+}
+
+// AuthRepositories agrupa o estado de autenticação injetado nos serviços
+type AuthRepositories struct {
+	RefreshTokens      RefreshTokenRepository
+	Sessions           SessionRepository
+	RevokedTokens      RevokedTokenRepository
+	PasswordResets     PasswordResetRepository
+	EmailVerifications EmailVerificationRepository
+	LoginAttempts      LoginAttemptRepository
+	OIDCStates         OIDCStateRepository
+	APIKeys            APIKeyRepository
+	AuditLogs          AuditLogRepository
+}
+
+// TxRepositories são os repositórios usados dentro de uma unidade de trabalho
+type TxRepositories struct {
+	Purchases PurchaseRepository
+	Progress  ProgressRepository
+	Ledger    LedgerRepository
+}
+
+// UnitOfWork grava em várias collections como uma operação só
+type UnitOfWork interface {
+	// Do executa fn com os repositórios da unidade, que devem receber o ctx
+	// passado a fn. Se fn retornar erro, nada do que ela gravou permanece e o
+	// erro é retornado. fn pode ser executada mais de uma vez (o MongoDB repete
+	// transações em conflito), então não deve ter outros efeitos.
+	Do(ctx context.Context, fn func(ctx context.Context, tx TxRepositories) error) error
+}
+
+// Repositories agrupa os repositórios injetados nos controllers e serviços
+type Repositories struct {
+	Users      UserRepository
+	Courses    CourseRepository
+	Lessons    LessonRepository
+	Purchases  PurchaseRepository
+	News       NewsRepository
+	Images     ImageRepository
+	Progress   ProgressRepository
+	Ledger     LedgerRepository
+	Sync       SyncRepository
+	UnitOfWork UnitOfWork
+	Auth       AuthRepositories // Vazio no pacote memory
+}
+
+// Storage é o backend de armazenamento selecionado em [database].backend
+type Storage interface {
+	Name() string // Nome exibido no /readyz (ex.: "mongodb")
+	Repositories() Repositories
+	Ping(ctx context.Context) error
+	// Close encerra o backend, esperando as operações em andamento até o fim de ctx
+	Close(ctx context.Context) error
+}
+
+// NewMongo retorna os repositórios que usam o MongoDB conectado em database.DB
+func NewMongo() Repositories {
+	return Repositories{
+		Users:      &dao.UserDao{},
+		Courses:    dao.CourseDao{},
+		Lessons:    dao.LessonDao{},
+		Purchases:  dao.PurchaseDao{},
+		News:       dao.NewsDao{},
+		Images:     dao.ImageDao{},
+		Progress:   dao.UserProgressDao{},
+		Ledger:     dao.LedgerEntryDao{},
+		Sync:       dao.SyncDao{},
+		UnitOfWork: mongoUnitOfWork{},
+		Auth: AuthRepositories{
+			RefreshTokens:      dao.RefreshTokenDao{},
+			Sessions:           dao.SessionDao{},
+			RevokedTokens:      dao.RevokedTokenDao{},
+			PasswordResets:     dao.PasswordResetDao{},
+			EmailVerifications: dao.EmailVerificationDao{},
+			LoginAttempts:      dao.LoginAttemptDao{},
+			OIDCStates:         dao.OIDCStateDao{},
+			APIKeys:            dao.APIKeyDao{},
+			AuditLogs:          dao.AuditLogDao{},
+		},
+	}
+}
+
+type mongoStorage struct{}
+
+// NewMongoStorage usa a conexão aberta por database.ConnectMongoDB
+func NewMongoStorage() Storage {
+	return mongoStorage{}
+}
+
+func (mongoStorage) Name() string { return "mongodb" }
+
+func (mongoStorage) Repositories() Repositories { return NewMongo() }
+
+func (mongoStorage) Ping(ctx context.Context) error {
+	if database.MongoClient == nil {
+		return errors.New("não conectado")
+	}
+	return database.MongoClient.Ping(ctx, nil)
+}
+
+func (mongoStorage) Close(ctx context.Context) error {
+	return database.DisconnectMongoDB(ctx)
+}
@@ -0,0 +1,300 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RunAuth executa o contrato do estado de autenticação (repository.AuthRepositories).
+// newAuth é chamado em cada subteste e deve retornar repositórios vazios.
+func RunAuth(t *testing.T, newAuth func(t *testing.T) repository.AuthRepositories) {
+	t.Run("RefreshTokens", func(t *testing.T) { testRefreshTokens(t, newAuth(t).RefreshTokens) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, newAuth(t).Sessions) })
+	t.Run("RevokedTokens", func(t *testing.T) { testRevokedTokens(t, newAuth(t).RevokedTokens) })
+	t.Run("PasswordResets", func(t *testing.T) { testPasswordResets(t, newAuth(t).PasswordResets) })
+	t.Run("EmailVerifications", func(t *testing.T) { testEmailVerifications(t, newAuth(t).EmailVerifications) })
+	t.Run("LoginAttempts", func(t *testing.T) { testLoginAttempts(t, newAuth(t).LoginAttempts) })
+	t.Run("OIDCStates", func(t *testing.T) { testOIDCStates(t, newAuth(t).OIDCStates) })
+	t.Run("APIKeys", func(t *testing.T) { testAPIKeys(t, newAuth(t).APIKeys) })
+}
+
+func testRefreshTokens(t *testing.T, tokens repository.RefreshTokenRepository) {
+	ctx := context.Background()
+	userID, familyID := bson.NewObjectID(), bson.NewObjectID()
+
+	first, err := tokens.CreateRefreshToken(ctx, dao.RefreshTokenDao{
+		UserID: userID, FamilyID: familyID, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	mustDo(t, err)
+	if first.ID.IsZero() || first.CreatedAt.IsZero() {
+		t.Fatalf("CreateRefreshToken deve preencher ID e CreatedAt: %+v", first)
+	}
+
+	found, err := tokens.FindByHash(ctx, "hash-1")
+	mustDo(t, err)
+	if found.ID != first.ID {
+		t.Fatalf("FindByHash retornou %+v", found)
+	}
+	_, err = tokens.FindByHash(ctx, "inexistente")
+	expectNoDocuments(t, err)
+
+	replacement := bson.NewObjectID()
+	mustDo(t, tokens.MarkUsed(ctx, first.ID, replacement))
+	if err := tokens.MarkUsed(ctx, first.ID, bson.NewObjectID()); !errors.Is(err, dao.ErrRefreshTokenAlreadyUsed) {
+		t.Fatalf("o segundo MarkUsed deve retornar ErrRefreshTokenAlreadyUsed, retornou %v", err)
+	}
+	found, err = tokens.FindByHash(ctx, "hash-1")
+	mustDo(t, err)
+	if found.UsedAt.IsZero() || found.ReplacedBy != replacement {
+		t.Fatalf("MarkUsed deve gravar used_at e replaced_by: %+v", found)
+	}
+
+	otherFamily := bson.NewObjectID()
+	second, err := tokens.CreateRefreshToken(ctx, dao.RefreshTokenDao{
+		UserID: userID, FamilyID: otherFamily, TokenHash: "hash-2", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	mustDo(t, err)
+
+	mustDo(t, tokens.RevokeAllForUserExcept(ctx, userID, otherFamily))
+	revoked, err := tokens.IsFamilyRevoked(ctx, familyID)
+	mustDo(t, err)
+	kept, err := tokens.IsFamilyRevoked(ctx, otherFamily)
+	mustDo(t, err)
+	if !revoked || kept {
+		t.Fatalf("RevokeAllForUserExcept deve revogar só as outras famílias (revogada=%v, mantida=%v)", revoked, kept)
+	}
+
+	mustDo(t, tokens.RevokeFamily(ctx, otherFamily))
+	if err := tokens.MarkUsed(ctx, second.ID, bson.NewObjectID()); !errors.Is(err, dao.ErrRefreshTokenAlreadyUsed) {
+		t.Fatalf("MarkUsed deve recusar token revogado, retornou %v", err)
+	}
+}
+
+func testSessions(t *testing.T, sessions repository.SessionRepository) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+
+	older, err := sessions.CreateSession(ctx, dao.SessionDao{ID: bson.NewObjectID(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour)})
+	mustDo(t, err)
+	newer, err := sessions.CreateSession(ctx, dao.SessionDao{ID: bson.NewObjectID(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour)})
+	mustDo(t, err)
+	_, err = sessions.CreateSession(ctx, dao.SessionDao{ID: bson.NewObjectID(), UserID: userID, ExpiresAt: time.Now().Add(-time.Minute)})
+	mustDo(t, err)
+
+	mustDo(t, sessions.Touch(ctx, newer.ID, "10.0.0.1", time.Time{}))
+	active, err := sessions.FindActiveByUser(ctx, userID)
+	mustDo(t, err)
+	if len(active) != 2 || active[0].ID != newer.ID || active[0].IP != "10.0.0.1" {
+		t.Fatalf("FindActiveByUser deve listar as sessões não expiradas, a mais recente primeiro: %+v", active)
+	}
+
+	_, err = sessions.FindByIDForUser(ctx, older.ID, bson.NewObjectID())
+	expectNoDocuments(t, err)
+
+	mustDo(t, sessions.RevokeAllForUserExcept(ctx, userID, newer.ID))
+	active, err = sessions.FindActiveByUser(ctx, userID)
+	mustDo(t, err)
+	if len(active) != 1 || active[0].ID != newer.ID {
+		t.Fatalf("RevokeAllForUserExcept deve manter apenas a sessão informada: %+v", active)
+	}
+
+	mustDo(t, sessions.Revoke(ctx, newer.ID))
+	revoked, err := sessions.FindByIDForUser(ctx, newer.ID, userID)
+	mustDo(t, err)
+	if revoked.RevokedAt.IsZero() {
+		t.Fatalf("Revoke deve gravar revoked_at: %+v", revoked)
+	}
+}
+
+func testRevokedTokens(t *testing.T, revokedTokens repository.RevokedTokenRepository) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+
+	mustDo(t, revokedTokens.RevokeToken(ctx, "jti-1", userID, time.Now().Add(time.Hour)))
+	mustDo(t, revokedTokens.RevokeToken(ctx, "jti-1", userID, time.Now().Add(time.Hour)))
+
+	revoked, err := revokedTokens.IsRevoked(ctx, "jti-1")
+	mustDo(t, err)
+	other, err := revokedTokens.IsRevoked(ctx, "jti-2")
+	mustDo(t, err)
+	if !revoked || other {
+		t.Fatalf("IsRevoked retornou %v para o jti revogado e %v para outro", revoked, other)
+	}
+}
+
+func testPasswordResets(t *testing.T, resets repository.PasswordResetRepository) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+
+	reset, err := resets.CreatePasswordReset(ctx, dao.PasswordResetDao{UserID: userID, TokenHash: "reset-1", ExpiresAt: time.Now().Add(time.Hour)})
+	mustDo(t, err)
+	mustDo(t, resets.MarkUsed(ctx, reset.ID))
+	if err := resets.MarkUsed(ctx, reset.ID); !errors.Is(err, dao.ErrPasswordResetAlreadyUsed) {
+		t.Fatalf("o segundo MarkUsed deve retornar ErrPasswordResetAlreadyUsed, retornou %v", err)
+	}
+	mustDo(t, resets.Release(ctx, reset.ID))
+	mustDo(t, resets.MarkUsed(ctx, reset.ID))
+
+	pending, err := resets.CreatePasswordReset(ctx, dao.PasswordResetDao{UserID: userID, TokenHash: "reset-2", ExpiresAt: time.Now().Add(time.Hour)})
+	mustDo(t, err)
+	mustDo(t, resets.InvalidateForUser(ctx, userID))
+	found, err := resets.FindByHash(ctx, "reset-2")
+	mustDo(t, err)
+	if found.ID != pending.ID || found.UsedAt.IsZero() {
+		t.Fatalf("InvalidateForUser deve marcar os pedidos pendentes como usados: %+v", found)
+	}
+}
+
+func testEmailVerifications(t *testing.T, verifications repository.EmailVerificationRepository) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+
+	confirm, err := verifications.CreateEmailVerification(ctx, dao.EmailVerificationDao{
+		UserID: userID, Email: "ana@metabee.test", TokenHash: "verify-1", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	mustDo(t, err)
+	mustDo(t, verifications.MarkUsed(ctx, confirm.ID))
+	if err := verifications.MarkUsed(ctx, confirm.ID); !errors.Is(err, dao.ErrEmailVerificationAlreadyUsed) {
+		t.Fatalf("o segundo MarkUsed deve retornar ErrEmailVerificationAlreadyUsed, retornou %v", err)
+	}
+
+	pendingConfirm, err := verifications.CreateEmailVerification(ctx, dao.EmailVerificationDao{
+		UserID: userID, Email: "ana@metabee.test", TokenHash: "verify-2", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	mustDo(t, err)
+	pendingChange, err := verifications.CreateEmailVerification(ctx, dao.EmailVerificationDao{
+		UserID: userID, Email: "nova@metabee.test", Purpose: dao.EmailVerificationPurposeChange,
+		TokenHash: "verify-3", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	mustDo(t, err)
+
+	// Reenviar a confirmação do email atual não pode invalidar a troca pendente
+	mustDo(t, verifications.InvalidateForUser(ctx, userID, dao.EmailVerificationPurposeConfirm))
+	found, err := verifications.FindByHash(ctx, "verify-2")
+	mustDo(t, err)
+	if found.ID != pendingConfirm.ID || found.UsedAt.IsZero() {
+		t.Fatalf("InvalidateForUser deve invalidar o link de confirmação pendente: %+v", found)
+	}
+	found, err = verifications.FindByHash(ctx, "verify-3")
+	mustDo(t, err)
+	if found.ID != pendingChange.ID || !found.UsedAt.IsZero() {
+		t.Fatalf("InvalidateForUser(confirm) não deve invalidar a troca de email: %+v", found)
+	}
+
+	mustDo(t, verifications.InvalidateForUser(ctx, userID, dao.EmailVerificationPurposeChange))
+	found, err = verifications.FindByHash(ctx, "verify-3")
+	mustDo(t, err)
+	if found.UsedAt.IsZero() {
+		t.Fatalf("InvalidateForUser(change) deve invalidar a troca pendente: %+v", found)
+	}
+}
+
+func testLoginAttempts(t *testing.T, attempts repository.LoginAttemptRepository) {
+	ctx := context.Background()
+
+	empty, err := attempts.FindByKey(ctx, "ip:1")
+	mustDo(t, err)
+	if empty.Key != "ip:1" || empty.Failures != 0 {
+		t.Fatalf("FindByKey sem falhas deve retornar um registro vazio com a chave: %+v", empty)
+	}
+
+	_, err = attempts.RegisterFailure(ctx, "ip:1", time.Hour, time.Hour)
+	mustDo(t, err)
+	attempt, err := attempts.RegisterFailure(ctx, "ip:1", time.Hour, time.Hour)
+	mustDo(t, err)
+	if attempt.Failures != 2 || attempt.ID.IsZero() {
+		t.Fatalf("RegisterFailure deve somar as falhas dentro da janela: %+v", attempt)
+	}
+
+	// Janela negativa: a última falha sempre está fora dela
+	attempt, err = attempts.RegisterFailure(ctx, "ip:1", -time.Second, time.Hour)
+	mustDo(t, err)
+	if attempt.Failures != 1 {
+		t.Fatalf("RegisterFailure deve recomeçar a contagem fora da janela: %+v", attempt)
+	}
+
+	until := time.Now().Add(2 * time.Hour).Truncate(time.Millisecond)
+	mustDo(t, attempts.Lock(ctx, "ip:1", until))
+	locked, err := attempts.FindByKey(ctx, "ip:1")
+	mustDo(t, err)
+	if !locked.LockedUntil.Equal(until) || locked.ExpiresAt.Before(until) {
+		t.Fatalf("Lock deve gravar locked_until e estender expires_at: %+v", locked)
+	}
+
+	mustDo(t, attempts.Reset(ctx, "ip:1"))
+	reset, err := attempts.FindByKey(ctx, "ip:1")
+	mustDo(t, err)
+	if reset.Failures != 0 {
+		t.Fatalf("Reset deve apagar as falhas: %+v", reset)
+	}
+}
+
+func testOIDCStates(t *testing.T, states repository.OIDCStateRepository) {
+	ctx := context.Background()
+
+	mustDo(t, states.CreateOIDCState(ctx, dao.OIDCStateDao{StateHash: "state-1", Nonce: "n", ExpiresAt: time.Now().Add(time.Minute)}))
+	state, err := states.ConsumeByHash(ctx, "state-1")
+	mustDo(t, err)
+	if state.Nonce != "n" {
+		t.Fatalf("ConsumeByHash retornou %+v", state)
+	}
+	_, err = states.ConsumeByHash(ctx, "state-1")
+	expectNoDocuments(t, err)
+}
+
+func testAPIKeys(t *testing.T, keys repository.APIKeyRepository) {
+	ctx := context.Background()
+	userID := bson.NewObjectID()
+
+	key, err := keys.CreateAPIKey(ctx, dao.APIKeyDao{UserID: userID, Name: "ci", KeyHash: "key-1", Scopes: []string{dao.ScopeCoursesWrite}})
+	mustDo(t, err)
+
+	found, err := keys.FindByHash(ctx, "key-1")
+	mustDo(t, err)
+	if found.ID != key.ID || !found.HasScope(dao.ScopeCoursesWrite) {
+		t.Fatalf("FindByHash retornou %+v", found)
+	}
+
+	mustDo(t, keys.TouchIfIdle(ctx, key.ID, "10.0.0.2", time.Now()))
+	listed, err := keys.FindByUser(ctx, userID)
+	mustDo(t, err)
+	if len(listed) != 1 || listed[0].KeyHash != "" || listed[0].LastUsedIP != "10.0.0.2" {
+		t.Fatalf("FindByUser deve listar as chaves sem o hash: %+v", listed)
+	}
+
+	revoked, err := keys.Revoke(ctx, key.ID, bson.NewObjectID())
+	mustDo(t, err)
+	if revoked {
+		t.Fatal("Revoke não deve revogar chave de outro usuário")
+	}
+	revoked, err = keys.Revoke(ctx, key.ID, userID)
+	mustDo(t, err)
+	if !revoked {
+		t.Fatal("Revoke deve revogar a chave do usuário")
+	}
+	listed, err = keys.FindByUser(ctx, userID)
+	mustDo(t, err)
+	if len(listed) != 0 {
+		t.Fatalf("FindByUser não deve listar chaves revogadas: %+v", listed)
+	}
+
+	otherUserID := bson.NewObjectID()
+	_, err = keys.CreateAPIKey(ctx, dao.APIKeyDao{UserID: userID, Name: "deploy", KeyHash: "key-2", Scopes: []string{dao.ScopeCoursesWrite}})
+	mustDo(t, err)
+	_, err = keys.CreateAPIKey(ctx, dao.APIKeyDao{UserID: otherUserID, Name: "ci", KeyHash: "key-3", Scopes: []string{dao.ScopeCoursesWrite}})
+	mustDo(t, err)
+	mustDo(t, keys.RevokeAllForUser(ctx, userID))
+	listed, err = keys.FindByUser(ctx, userID)
+	mustDo(t, err)
+	others, err := keys.FindByUser(ctx, otherUserID)
+	mustDo(t, err)
+	if len(listed) != 0 || len(others) != 1 {
+		t.Fatalf("RevokeAllForUser deve revogar só as chaves do usuário: %+v, outro usuário: %+v", listed, others)
+	}
+}
@@ -0,0 +1,461 @@
+// Package repotest contém o contrato que toda implementação de
+// repository.Repositories precisa cumprir. Uso em um teste:
+//
+//	func TestMemoryRepositories(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repository.Repositories { return memory.New() })
+//	}
+//
+// Para o MongoDB, newRepos deve conectar database.DB a um banco descartável e
+// limpá-lo antes de retornar repository.NewMongo(); o teste de UnitOfWork
+// precisa de um replica set (pode ter um único nó). Para o embedded, basta
+// abrir um arquivo em t.TempDir(). RunAuth cobre repository.AuthRepositories,
+// que o pacote memory não implementa.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Run executa o contrato. newRepos é chamado em cada subteste e deve retornar repositórios vazios.
+func Run(t *testing.T, newRepos func(t *testing.T) repository.Repositories) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos(t).Users) })
+	t.Run("Courses", func(t *testing.T) { testCourses(t, newRepos(t).Courses) })
+	t.Run("Lessons", func(t *testing.T) { testLessons(t, newRepos(t).Lessons) })
+	t.Run("Purchases", func(t *testing.T) { testPurchases(t, newRepos(t).Purchases) })
+	t.Run("News", func(t *testing.T) { testNews(t, newRepos(t).News) })
+	t.Run("Images", func(t *testing.T) { testImages(t, newRepos(t).Images) })
+	t.Run("Progress", func(t *testing.T) { testProgress(t, newRepos(t).Progress) })
+	t.Run("Ledger", func(t *testing.T) { testLedger(t, newRepos(t).Ledger) })
+	t.Run("Sync", func(t *testing.T) { testSync(t, newRepos(t).Sync) })
+	t.Run("SyncHours", func(t *testing.T) { testSyncHours(t, newRepos(t).Sync) })
+	t.Run("SyncConcurrentWriters", func(t *testing.T) { testSyncConcurrentWriters(t, newRepos(t).Sync) })
+	t.Run("UnitOfWork", func(t *testing.T) { testUnitOfWork(t, newRepos(t)) })
+	t.Run("CanceledContext", func(t *testing.T) { testCanceledContext(t, newRepos(t)) })
+}
+
+func mustDo(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+}
+
+func expectNoDocuments(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Fatalf("esperado mongo.ErrNoDocuments, recebido %v", err)
+	}
+}
+
+func testUsers(t *testing.T, users repository.UserRepository) {
+	ctx := context.Background()
+	created, err := users.CreateUser(ctx, dao.UserDao{
+		Name:     "Ana",
+		Email:    "Ana@Example.com",
+		Password: "hash",
+		Role:     dao.RoleAdmin, // Ignorado: o papel não é definido no cadastro
+	})
+	mustDo(t, err)
+	if created.ID.IsZero() || created.CreatedAt.IsZero() {
+		t.Fatalf("CreateUser deve preencher ID e CreatedAt: %+v", created)
+	}
+	if created.Email != "ana@example.com" {
+		t.Fatalf("CreateUser deve gravar o email normalizado, gravou %q", created.Email)
+	}
+	if !created.EmailUnverified || created.Role != "" {
+		t.Fatalf("CreateUser deve criar conta não verificada e sem papel: %+v", created)
+	}
+
+	if _, err := users.CreateUser(ctx, dao.UserDao{Name: "Outra", Email: "ana@example.com"}); err == nil {
+		t.Fatal("CreateUser deve recusar email já cadastrado (sem diferenciar maiúsculas)")
+	}
+
+	found, err := users.FindUserByEmail(ctx, "  ANA@example.com ")
+	mustDo(t, err)
+	if found.ID != created.ID || found.Password != "hash" {
+		t.Fatalf("FindUserByEmail retornou %+v", found)
+	}
+	if _, err := users.FindUserByEmail(ctx, "ninguem@example.com"); err == nil {
+		t.Fatal("FindUserByEmail deve falhar para email inexistente")
+	}
+
+	byID, err := users.FindUserByID(ctx, created.ID.Hex())
+	mustDo(t, err)
+	if byID.Email != created.Email {
+		t.Fatalf("FindUserByID retornou %+v", byID)
+	}
+	if _, err := users.FindUserByID(ctx, "invalido"); err == nil {
+		t.Fatal("FindUserByID deve falhar para ID inválido")
+	}
+	if _, err := users.FindUserByID(ctx, bson.NewObjectID().Hex()); err == nil {
+		t.Fatal("FindUserByID deve falhar para usuário inexistente")
+	}
+
+	if users.HasAvatar(ctx, created.ID) {
+		t.Fatal("usuário novo não tem avatar")
+	}
+	mustDo(t, users.UpdateUserProfile(ctx, created.ID, bson.M{
+		"bio":              "Professora",
+		"role":             dao.RoleInstructor,
+		"avatar":           bson.Binary{Subtype: 0, Data: []byte{1, 2, 3}},
+		"avatar_mime_type": "image/png",
+	}))
+	if err := users.UpdateUserProfile(ctx, bson.NewObjectID(), bson.M{"bio": "x"}); err == nil {
+		t.Fatal("UpdateUserProfile deve falhar para usuário inexistente")
+	}
+
+	if !users.HasAvatar(ctx, created.ID) {
+		t.Fatal("HasAvatar deve ser true após gravar o avatar")
+	}
+	avatar, mimeType, err := users.GetUserAvatar(ctx, created.ID)
+	if err != nil || len(avatar.Data) != 3 || mimeType != "image/png" {
+		t.Fatalf("GetUserAvatar retornou %v %q %v", avatar.Data, mimeType, err)
+	}
+
+	updated, err := users.FindUserByID(ctx, created.ID.Hex())
+	mustDo(t, err)
+	if updated.Bio != "Professora" || updated.Role != dao.RoleInstructor || updated.UpdatedAt.IsZero() {
+		t.Fatalf("UpdateUserProfile não aplicou os campos: %+v", updated)
+	}
+	if len(updated.Avatar.Data) != 0 {
+		t.Fatal("FindUserByID não deve carregar o avatar")
+	}
+
+	profile, err := users.GetUserProfile(ctx, created.ID)
+	mustDo(t, err)
+	if profile.Password != "" || profile.Avatar.Data == nil || len(profile.Avatar.Data) != 0 {
+		t.Fatalf("GetUserProfile deve omitir a senha e marcar o avatar vazio: %+v", profile)
+	}
+
+	if _, err := users.CreateUser(ctx, dao.UserDao{Name: "Bruno", Email: "bruno@example.com"}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if count, err := users.CountUsers(ctx); err != nil || count != 2 {
+		t.Fatalf("CountUsers = %d, esperado 2", count)
+	}
+
+	testUserSecurity(t, users, created.ID)
+}
+
+// testUserSecurity cobre as operações condicionais usadas por senha, MFA e login Google
+func testUserSecurity(t *testing.T, users repository.UserRepository, userID bson.ObjectID) {
+	ctx := context.Background()
+
+	if replaced, err := users.ReplacePasswordHash(ctx, userID, "outro", "novo"); err != nil || replaced {
+		t.Fatal("ReplacePasswordHash não deve trocar quando o hash atual mudou")
+	}
+	if replaced, err := users.ReplacePasswordHash(ctx, userID, "hash", "novo"); err != nil || !replaced {
+		t.Fatal("ReplacePasswordHash deve trocar quando o hash atual confere")
+	}
+
+	if recorded, err := users.RecordMFAStep(ctx, userID, 10); err != nil || !recorded {
+		t.Fatal("RecordMFAStep deve aceitar o primeiro passo")
+	}
+	for _, step := range []int64{10, 9} {
+		if recorded, err := users.RecordMFAStep(ctx, userID, step); err != nil || recorded {
+			t.Fatalf("RecordMFAStep deve recusar o passo %d após o 10", step)
+		}
+	}
+
+	mustDo(t, users.UpdateUserProfile(ctx, userID, bson.M{
+		"mfa_recovery_codes": []string{"a", "b"},
+		"google_sub":         "google-123",
+	}))
+	if consumed, err := users.ConsumeRecoveryCode(ctx, userID, "a"); err != nil || !consumed {
+		t.Fatal("ConsumeRecoveryCode deve consumir um código existente")
+	}
+	if consumed, err := users.ConsumeRecoveryCode(ctx, userID, "a"); err != nil || consumed {
+		t.Fatal("ConsumeRecoveryCode não deve aceitar o mesmo código duas vezes")
+	}
+
+	linked, err := users.FindUserByGoogleSubject(ctx, "google-123")
+	mustDo(t, err)
+	if linked.ID != userID || linked.Password != "novo" || len(linked.MFARecoveryCodes) != 1 {
+		t.Fatalf("FindUserByGoogleSubject retornou %+v", linked)
+	}
+	_, err = users.FindUserByGoogleSubject(ctx, "google-999")
+	expectNoDocuments(t, err)
+}
+
+func testCourses(t *testing.T, courses repository.CourseRepository) {
+	ctx := context.Background()
+	if all, err := courses.GetAllCourses(ctx); err != nil || len(all) != 0 {
+		t.Fatalf("repositório vazio retornou %d cursos", len(all))
+	}
+
+	first, err := courses.CreateCourse(ctx, dao.CourseDao{Title: "Go", Price: 10})
+	mustDo(t, err)
+	time.Sleep(5 * time.Millisecond)
+	second, err := courses.CreateCourse(ctx, dao.CourseDao{Title: "Mongo"})
+	mustDo(t, err)
+	time.Sleep(5 * time.Millisecond)
+	third, err := courses.CreateCourse(ctx, dao.CourseDao{Title: "Gin"})
+	mustDo(t, err)
+	if first.ID.IsZero() || first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+		t.Fatalf("CreateCourse deve preencher ID e datas: %+v", first)
+	}
+
+	if all, err := courses.GetAllCourses(ctx); err != nil || len(all) != 3 {
+		t.Fatalf("GetAllCourses retornou %d cursos, esperado 3", len(all))
+	}
+
+	last, err := courses.GetLastTwoCourses(ctx)
+	mustDo(t, err)
+	if len(last) != 2 || last[0].ID != third.ID || last[1].ID != second.ID {
+		t.Fatalf("GetLastTwoCourses deve retornar os dois mais recentes, do mais novo ao mais antigo: %+v", last)
+	}
+
+	found, err := courses.FindByID(ctx, first.ID)
+	mustDo(t, err)
+	if found.Title != "Go" || found.Price != 10 {
+		t.Fatalf("FindByID retornou %+v", found)
+	}
+	_, err = courses.FindByID(ctx, bson.NewObjectID())
+	expectNoDocuments(t, err)
+
+	if updated, err := courses.UpdateCourseImage(ctx, first.ID, []byte{9, 9}, "image/png"); err != nil || !updated {
+		t.Fatal("UpdateCourseImage deve retornar true para curso existente")
+	}
+	withImage, err := courses.FindByID(ctx, first.ID)
+	mustDo(t, err)
+	if len(withImage.ImageData.Data) != 2 || withImage.ImageType != "image/png" {
+		t.Fatalf("UpdateCourseImage não gravou a imagem: %+v", withImage)
+	}
+	if updated, err := courses.UpdateCourseImage(ctx, bson.NewObjectID(), []byte{1}, "image/png"); err != nil || updated {
+		t.Fatal("UpdateCourseImage deve retornar false para curso inexistente")
+	}
+}
+
+func testLessons(t *testing.T, lessons repository.LessonRepository) {
+	ctx := context.Background()
+	courseID := bson.NewObjectID()
+	first, err := lessons.CreateLesson(ctx, dao.LessonDao{CourseID: courseID, Title: "Introdução", Order: 1})
+	mustDo(t, err)
+	if _, err := lessons.CreateLesson(ctx, dao.LessonDao{CourseID: courseID, Title: "Variáveis", Order: 2}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if first.ID.IsZero() || first.CreatedAt.IsZero() {
+		t.Fatalf("CreateLesson deve preencher ID e CreatedAt: %+v", first)
+	}
+
+	found, err := lessons.GetLessonsByIDs(ctx, []bson.ObjectID{first.ID, bson.NewObjectID()})
+	mustDo(t, err)
+	if len(found) != 1 || found[0].Title != "Introdução" {
+		t.Fatalf("GetLessonsByIDs deve retornar apenas as aulas existentes: %+v", found)
+	}
+	if none, err := lessons.GetLessonsByIDs(ctx, nil); err != nil || len(none) != 0 {
+		t.Fatalf("GetLessonsByIDs sem IDs retornou %+v", none)
+	}
+}
+
+func testPurchases(t *testing.T, purchases repository.PurchaseRepository) {
+	ctx := context.Background()
+	userID, courseID := bson.NewObjectID(), bson.NewObjectID()
+
+	purchase, err := purchases.CreatePurchase(ctx, userID, courseID, "https://drive/pasta")
+	mustDo(t, err)
+	if purchase.ID.IsZero() || purchase.Status != "pending" || purchase.DriveLink != "https://drive/pasta" {
+		t.Fatalf("CreatePurchase retornou %+v", purchase)
+	}
+	if _, err := purchases.CreatePurchase(ctx, bson.NewObjectID(), courseID, ""); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, err := purchases.CreatePurchase(ctx, userID, courseID, ""); !errors.Is(err, dao.ErrPurchaseExists) {
+		t.Fatalf("a segunda compra do mesmo curso deve retornar ErrPurchaseExists, retornou %v", err)
+	}
+
+	byUser, err := purchases.GetPurchasesByUser(ctx, userID)
+	mustDo(t, err)
+	if len(byUser) != 1 || byUser[0].ID != purchase.ID {
+		t.Fatalf("GetPurchasesByUser deve retornar apenas as compras do usuário: %+v", byUser)
+	}
+
+	_, err = purchases.GetPurchaseByUserAndCourse(ctx, userID, bson.NewObjectID())
+	expectNoDocuments(t, err)
+
+	mustDo(t, purchases.UpdatePurchaseStatus(ctx, purchase.ID, "downloaded", "/cursos/go"))
+	mustDo(t, purchases.UpdatePurchaseStatus(ctx, purchase.ID, "completed", ""))
+	mustDo(t, purchases.UpdatePurchaseStatus(ctx, bson.NewObjectID(), "completed", ""))
+
+	updated, err := purchases.GetPurchaseByUserAndCourse(ctx, userID, courseID)
+	mustDo(t, err)
+	if updated.Status != "completed" || updated.LocalPath != "/cursos/go" {
+		t.Fatalf("UpdatePurchaseStatus deve trocar o status e manter o caminho quando vazio: %+v", updated)
+	}
+}
+
+func testNews(t *testing.T, news repository.NewsRepository) {
+	ctx := context.Background()
+	now := time.Now()
+	older, err := news.CreateNews(ctx, dao.NewsDao{Title: "Antiga", Date: now.Add(-48 * time.Hour)})
+	mustDo(t, err)
+	newest, err := news.CreateNews(ctx, dao.NewsDao{Title: "Nova", Date: now.Add(time.Hour)})
+	mustDo(t, err)
+	middle, err := news.CreateNews(ctx, dao.NewsDao{Title: "Meio", Date: now.Add(-24 * time.Hour)})
+	mustDo(t, err)
+	undated, err := news.CreateNews(ctx, dao.NewsDao{Title: "Sem data"})
+	mustDo(t, err)
+	if undated.Date.IsZero() || undated.ID.IsZero() {
+		t.Fatalf("CreateNews deve preencher ID e data: %+v", undated)
+	}
+
+	all, err := news.GetAllNews(ctx)
+	mustDo(t, err)
+	if len(all) != 4 || all[len(all)-1].ID != older.ID {
+		t.Fatalf("GetAllNews deve ordenar da mais recente para a mais antiga: %+v", all)
+	}
+
+	last, err := news.GetLastNews(ctx, 2)
+	mustDo(t, err)
+	if len(last) != 2 || last[0].ID != newest.ID || last[1].ID != undated.ID {
+		t.Fatalf("GetLastNews(2) deve retornar as duas mais recentes: %+v", last)
+	}
+
+	found, err := news.FindByID(ctx, middle.ID)
+	mustDo(t, err)
+	if found.Title != "Meio" {
+		t.Fatalf("FindByID retornou %+v", found)
+	}
+	_, err = news.FindByID(ctx, bson.NewObjectID())
+	expectNoDocuments(t, err)
+}
+
+func testImages(t *testing.T, images repository.ImageRepository) {
+	ctx := context.Background()
+	image, err := images.CreateImage(ctx, "logo.png", []byte{1, 2, 3}, "image/png")
+	mustDo(t, err)
+	if image.ID.IsZero() || image.CreatedAt.IsZero() {
+		t.Fatalf("CreateImage deve preencher ID e CreatedAt: %+v", image)
+	}
+
+	byName, err := images.FindImageByName(ctx, "logo.png")
+	mustDo(t, err)
+	if byName.ID != image.ID || len(byName.Data.Data) != 3 || byName.MimeType != "image/png" {
+		t.Fatalf("FindImageByName retornou %+v", byName)
+	}
+	_, err = images.FindImageByName(ctx, "inexistente.png")
+	expectNoDocuments(t, err)
+
+	mustDo(t, images.UpdateImage(ctx, image.ID, "logo.webp", []byte{4}, "image/webp"))
+	mustDo(t, images.UpdateImage(ctx, bson.NewObjectID(), "x", nil, "image/png"))
+	updated, err := images.FindImageByID(ctx, image.ID)
+	mustDo(t, err)
+	if updated.Name != "logo.webp" || len(updated.Data.Data) != 1 || updated.MimeType != "image/webp" {
+		t.Fatalf("UpdateImage não aplicou os campos: %+v", updated)
+	}
+
+	mustDo(t, images.DeleteImage(ctx, image.ID))
+	mustDo(t, images.DeleteImage(ctx, image.ID))
+	_, err = images.FindImageByID(ctx, image.ID)
+	expectNoDocuments(t, err)
+}
+
+func testProgress(t *testing.T, progress repository.ProgressRepository) {
+	ctx := context.Background()
+	userID, otherUserID := bson.NewObjectID(), bson.NewObjectID()
+	courseA, courseB, courseC := bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID()
+
+	if avg, err := progress.GetAverageProgress(ctx, userID); err != nil || avg != 0 {
+		t.Fatalf("GetAverageProgress sem progresso = %v, esperado 0", avg)
+	}
+
+	first, err := progress.SaveProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseA, Progress: 10})
+	mustDo(t, err)
+	saved, err := progress.SaveProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseA, Progress: 40, Hours: 2})
+	mustDo(t, err)
+	if saved.ID != first.ID || saved.Progress != 40 || saved.Hours != 2 {
+		t.Fatalf("SaveProgress deve atualizar o registro existente: %+v / %+v", first, saved)
+	}
+	if _, err := progress.SaveProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseB, Progress: 100}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, err := progress.SaveProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseC, Progress: 0}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, err := progress.SaveProgress(ctx, dao.UserProgressDao{UserID: otherUserID, CourseID: courseA, Progress: 50}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	kept, err := progress.EnsureProgress(ctx, userID, courseA)
+	mustDo(t, err)
+	if kept.ID != first.ID || kept.Progress != 40 {
+		t.Fatalf("EnsureProgress deve manter o progresso existente: %+v", kept)
+	}
+
+	active, err := progress.GetActiveCourses(ctx, userID)
+	mustDo(t, err)
+	if len(active) != 1 || active[0].CourseID != courseA {
+		t.Fatalf("GetActiveCourses deve ignorar cursos com 0%% e 100%%: %+v", active)
+	}
+	if avg, err := progress.GetAverageProgress(ctx, userID); err != nil || avg < 46.66 || avg > 46.67 {
+		t.Fatalf("GetAverageProgress = %v, esperado ~46.67", avg)
+	}
+
+	now := time.Now()
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Add(-time.Hour)
+	if _, err := progress.CreateStudySession(ctx, dao.StudySessionDao{UserID: userID, CourseID: courseA, Duration: 1.5, Date: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	latest, err := progress.CreateStudySession(ctx, dao.StudySessionDao{UserID: userID, CourseID: courseA, Duration: 0.5})
+	mustDo(t, err)
+	if _, err := progress.CreateStudySession(ctx, dao.StudySessionDao{UserID: userID, CourseID: courseB, Duration: 3, Date: lastMonth}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, err := progress.CreateStudySession(ctx, dao.StudySessionDao{UserID: otherUserID, CourseID: courseA, Duration: 8}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if latest.ID.IsZero() || latest.Date.IsZero() {
+		t.Fatalf("CreateStudySession deve preencher ID e data: %+v", latest)
+	}
+
+	if hours, err := progress.GetHoursThisMonth(ctx, userID); err != nil || hours != 2 {
+		t.Fatalf("GetHoursThisMonth = %v, esperado 2", hours)
+	}
+
+	recent, err := progress.GetRecentLessons(ctx, userID, 2)
+	mustDo(t, err)
+	if len(recent) != 2 || recent[0].ID != latest.ID {
+		t.Fatalf("GetRecentLessons deve retornar as sessões mais recentes primeiro: %+v", recent)
+	}
+}
+
+// testCanceledContext garante que uma requisição encerrada não chega a consultar os dados
+func testCanceledContext(t *testing.T, repos repository.Repositories) {
+	user, err := repos.Users.CreateUser(context.Background(), dao.UserDao{Name: "Ana", Email: "ana@example.com"})
+	mustDo(t, err)
+	course, err := repos.Courses.CreateCourse(context.Background(), dao.CourseDao{Title: "Go"})
+	mustDo(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repos.Users.FindUserByID(ctx, user.ID.Hex()); err == nil {
+		t.Fatal("FindUserByID deve falhar com o contexto cancelado")
+	}
+	if _, err := repos.Courses.FindByID(ctx, course.ID); err == nil {
+		t.Fatal("FindByID deve falhar com o contexto cancelado")
+	}
+	if _, err := repos.Courses.GetAllCourses(ctx); err == nil {
+		t.Fatal("GetAllCourses deve falhar com o contexto cancelado")
+	}
+	if _, err := repos.News.GetAllNews(ctx); err == nil {
+		t.Fatal("GetAllNews deve falhar com o contexto cancelado")
+	}
+	if _, err := repos.Progress.GetActiveCourses(ctx, user.ID); err == nil {
+		t.Fatal("GetActiveCourses deve falhar com o contexto cancelado")
+	}
+	if _, err := repos.Purchases.CreatePurchase(ctx, user.ID, course.ID, ""); err == nil {
+		t.Fatal("CreatePurchase deve falhar com o contexto cancelado")
+	}
+	if purchases, err := repos.Purchases.GetPurchasesByUser(context.Background(), user.ID); err != nil || len(purchases) != 0 {
+		t.Fatalf("CreatePurchase com o contexto cancelado não deve gravar: %v %v", purchases, err)
+	}
+}
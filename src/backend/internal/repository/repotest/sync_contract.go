@@ -0,0 +1,194 @@
+package repotest
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func testSync(t *testing.T, records repository.SyncRepository) {
+	ctx := context.Background()
+	userID, otherUserID, courseID := bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	session := dao.StudySessionDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, Duration: 1, Date: now}
+	inserted, err := records.ApplyStudySession(ctx, session)
+	mustDo(t, err)
+	again, err := records.ApplyStudySession(ctx, session)
+	mustDo(t, err)
+	if !inserted || again {
+		t.Fatalf("ApplyStudySession deve inserir só na primeira vez (primeira=%v, segunda=%v)", inserted, again)
+	}
+
+	progress, changed, err := records.ApplyProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseID, Progress: 40, Hours: 1, LastStudy: now})
+	mustDo(t, err)
+	if !changed || progress.ID.IsZero() || progress.Seq == 0 {
+		t.Fatalf("ApplyProgress deve criar o registro com seq: %+v", progress)
+	}
+	if progress.Hours != 1 {
+		t.Fatalf("as horas do progresso devem ser a soma das sessões: %+v", progress)
+	}
+	later := now.Add(time.Hour)
+	merged, changed, err := records.ApplyProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseID, Progress: 30, Hours: 3, LastStudy: later})
+	mustDo(t, err)
+	if !changed || merged.Progress != 40 || merged.Hours != 1 || !merged.LastStudy.Equal(later) || merged.Seq <= progress.Seq {
+		t.Fatalf("ApplyProgress deve manter o maior valor de cada campo e ignorar as horas: %+v", merged)
+	}
+	current, changed, err := records.ApplyProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseID, Progress: 10, Hours: 5, LastStudy: now})
+	mustDo(t, err)
+	if changed || current.ID != progress.ID || current.Seq != merged.Seq {
+		t.Fatalf("ApplyProgress sem valores maiores não deve alterar o registro: %+v", current)
+	}
+
+	note := dao.NoteDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, Content: "v1", Version: 1, DeviceID: "a", UpdatedAt: now}
+	_, changed, err = records.ApplyNote(ctx, note)
+	mustDo(t, err)
+	if !changed {
+		t.Fatal("ApplyNote deve gravar a primeira versão")
+	}
+	edited := note
+	edited.Content, edited.Version = "v2", 2
+	_, changed, err = records.ApplyNote(ctx, edited)
+	mustDo(t, err)
+	if !changed {
+		t.Fatal("ApplyNote deve gravar a versão maior")
+	}
+	stale := note
+	stale.Content, stale.UpdatedAt = "v1 editada depois", now.Add(time.Hour)
+	kept, changed, err := records.ApplyNote(ctx, stale)
+	mustDo(t, err)
+	if changed || kept.Content != "v2" || kept.Version != 2 {
+		t.Fatalf("ApplyNote deve manter a versão maior e retorná-la: %+v", kept)
+	}
+	_, _, err = records.ApplyNote(ctx, dao.NoteDao{ID: note.ID, UserID: otherUserID, CourseID: courseID, Version: 9, DeviceID: "b", UpdatedAt: now})
+	expectNoDocuments(t, err)
+
+	_, err = records.ApplyStudySession(ctx, dao.StudySessionDao{ID: bson.NewObjectID(), UserID: otherUserID, CourseID: courseID, Date: now})
+	mustDo(t, err)
+
+	// A sequência pode ter lacunas, mas LatestSeq nunca fica atrás do último registro
+	latest, err := records.LatestSeq(ctx, userID)
+	mustDo(t, err)
+	all, err := records.Changes(ctx, userID, 0, 100)
+	mustDo(t, err)
+	if len(all.StudySessions) != 1 || len(all.Progress) != 1 || len(all.Notes) != 1 || all.More || all.Next != kept.Seq || latest < kept.Seq {
+		t.Fatalf("Changes deve listar a versão atual de cada registro do usuário (LatestSeq %d): %+v", latest, all)
+	}
+	if all.Progress[0].Progress != 40 || all.Notes[0].Content != "v2" {
+		t.Fatalf("Changes retornou versões antigas: %+v", all)
+	}
+
+	// Ordem de seq: sessão, progresso (última alteração) e nota
+	first, err := records.Changes(ctx, userID, 0, 2)
+	mustDo(t, err)
+	if !first.More || len(first.StudySessions) != 1 || len(first.Progress) != 1 || len(first.Notes) != 0 || first.Next != merged.Seq {
+		t.Fatalf("Changes deve paginar por seq: %+v", first)
+	}
+	rest, err := records.Changes(ctx, userID, first.Next, 2)
+	mustDo(t, err)
+	if rest.More || len(rest.Notes) != 1 || len(rest.StudySessions)+len(rest.Progress) != 0 || rest.Next != kept.Seq {
+		t.Fatalf("a segunda página deve trazer só a nota: %+v", rest)
+	}
+	empty, err := records.Changes(ctx, userID, latest, 2)
+	mustDo(t, err)
+	if empty.More || empty.Next != latest || len(empty.StudySessions)+len(empty.Progress)+len(empty.Notes) != 0 {
+		t.Fatalf("Changes sem alterações deve retornar since em Next: %+v", empty)
+	}
+
+	state, err := records.GetSyncState(ctx, userID)
+	mustDo(t, err)
+	if state.UserID != userID || state.PushedSeq != 0 || state.PulledSeq != 0 {
+		t.Fatalf("GetSyncState sem sincronização deve retornar o estado zerado: %+v", state)
+	}
+	state.PushedSeq, state.PulledSeq, state.LastSyncAt = latest, 7, now
+	mustDo(t, records.SaveSyncState(ctx, state))
+	saved, err := records.GetSyncState(ctx, userID)
+	mustDo(t, err)
+	if saved.PushedSeq != latest || saved.PulledSeq != 7 || !saved.LastSyncAt.Equal(now) {
+		t.Fatalf("SaveSyncState não gravou o estado: %+v", saved)
+	}
+}
+
+// testSyncHours: as horas estudadas offline em dispositivos diferentes se somam
+func testSyncHours(t *testing.T, records repository.SyncRepository) {
+	ctx := context.Background()
+	userID, courseID := bson.NewObjectID(), bson.NewObjectID()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	// 1h sincronizada antes; depois o dispositivo A estuda 2h e o B 3h, offline
+	base := dao.StudySessionDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, Duration: 1, Date: now}
+	deviceA := dao.StudySessionDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, Duration: 2, Date: now}
+	deviceB := dao.StudySessionDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, Duration: 3, Date: now}
+	for _, session := range []dao.StudySessionDao{base, deviceA, deviceB, deviceA} {
+		_, err := records.ApplyStudySession(ctx, session)
+		mustDo(t, err)
+	}
+	// Cada dispositivo informa só as horas que conhece
+	_, _, err := records.ApplyProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseID, Progress: 20, Hours: 3, LastStudy: now})
+	mustDo(t, err)
+	saved, _, err := records.ApplyProgress(ctx, dao.UserProgressDao{UserID: userID, CourseID: courseID, Progress: 10, Hours: 4, LastStudy: now})
+	mustDo(t, err)
+	if saved.Hours != 6 || saved.Progress != 20 {
+		t.Fatalf("as horas devem ser a soma das sessões (6), sem contar o reenvio: %+v", saved)
+	}
+}
+
+// testSyncConcurrentWriters: quem acompanha Changes enquanto outros gravam
+// recebe todos os registros, mesmo que as gravações terminem fora de ordem de seq
+func testSyncConcurrentWriters(t *testing.T, records repository.SyncRepository) {
+	ctx := context.Background()
+	userID, courseID := bson.NewObjectID(), bson.NewObjectID()
+	const writers, sessionsPerWriter = 2, 25
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < sessionsPerWriter; i++ {
+				session := dao.StudySessionDao{ID: bson.NewObjectID(), UserID: userID, CourseID: courseID, Duration: 0.5, Date: time.Now()}
+				if _, err := records.ApplyStudySession(ctx, session); err != nil {
+					t.Errorf("ApplyStudySession: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	seen := map[bson.ObjectID]bool{}
+	var since int64
+	for {
+		var finished bool
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+
+		page, err := records.Changes(ctx, userID, since, 10)
+		mustDo(t, err)
+		for _, session := range page.StudySessions {
+			if session.Seq <= since || seen[session.ID] {
+				t.Fatalf("Changes repetiu a sessão %s (seq %d, since %d)", session.ID.Hex(), session.Seq, since)
+			}
+			seen[session.ID] = true
+		}
+		since = page.Next
+		if finished && !page.More {
+			break
+		}
+	}
+	if len(seen) != writers*sessionsPerWriter {
+		t.Fatalf("Changes pulou sessões: recebeu %d de %d", len(seen), writers*sessionsPerWriter)
+	}
+}
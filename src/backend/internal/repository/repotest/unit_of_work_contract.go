@@ -0,0 +1,100 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func testLedger(t *testing.T, ledger repository.LedgerRepository) {
+	ctx := context.Background()
+	purchaseID := bson.NewObjectID()
+
+	entry, err := ledger.RecordEntry(ctx, dao.LedgerEntryDao{
+		UserID: bson.NewObjectID(), CourseID: bson.NewObjectID(), PurchaseID: purchaseID, Type: dao.LedgerEntryPurchase, Amount: 99.9,
+	})
+	mustDo(t, err)
+	if entry.ID.IsZero() || entry.CreatedAt.IsZero() {
+		t.Fatalf("RecordEntry deve preencher ID e CreatedAt: %+v", entry)
+	}
+	if _, err := ledger.RecordEntry(ctx, dao.LedgerEntryDao{PurchaseID: purchaseID, Type: dao.LedgerEntryPurchase}); !errors.Is(err, dao.ErrLedgerEntryExists) {
+		t.Fatalf("o segundo lançamento da compra deve retornar ErrLedgerEntryExists, retornou %v", err)
+	}
+
+	found, err := ledger.FindByPurchase(ctx, purchaseID, dao.LedgerEntryPurchase)
+	mustDo(t, err)
+	if found.ID != entry.ID || found.Amount != 99.9 {
+		t.Fatalf("FindByPurchase retornou %+v", found)
+	}
+	_, err = ledger.FindByPurchase(ctx, bson.NewObjectID(), dao.LedgerEntryPurchase)
+	expectNoDocuments(t, err)
+}
+
+func testUnitOfWork(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	userID, courseID := bson.NewObjectID(), bson.NewObjectID()
+
+	// Cria um registro em cada repositório da unidade
+	var progressID bson.ObjectID
+	enroll := func(ctx context.Context, tx repository.TxRepositories) (dao.PurchaseDao, error) {
+		purchase, err := tx.Purchases.CreatePurchase(ctx, userID, courseID, "")
+		if err != nil {
+			return dao.PurchaseDao{}, err
+		}
+		progress, err := tx.Progress.EnsureProgress(ctx, userID, courseID)
+		if err != nil {
+			return dao.PurchaseDao{}, err
+		}
+		progressID = progress.ID
+		_, err = tx.Ledger.RecordEntry(ctx, dao.LedgerEntryDao{UserID: userID, CourseID: courseID, PurchaseID: purchase.ID, Type: dao.LedgerEntryPurchase})
+		return purchase, err
+	}
+
+	failure := errors.New("falha depois das escritas")
+	var rolledBack dao.PurchaseDao
+	err := repos.UnitOfWork.Do(ctx, func(ctx context.Context, tx repository.TxRepositories) error {
+		var err error
+		if rolledBack, err = enroll(ctx, tx); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Do deve retornar o erro de fn, retornou %v", err)
+	}
+	_, err = repos.Purchases.GetPurchaseByUserAndCourse(ctx, userID, courseID)
+	expectNoDocuments(t, err)
+	_, err = repos.Ledger.FindByPurchase(ctx, rolledBack.ID, dao.LedgerEntryPurchase)
+	expectNoDocuments(t, err)
+	progress, err := repos.Progress.EnsureProgress(ctx, userID, courseID)
+	mustDo(t, err)
+	if progress.ID == progressID {
+		t.Fatalf("o progresso criado pela unidade que falhou não deve permanecer: %+v", progress)
+	}
+
+	var committed dao.PurchaseDao
+	mustDo(t, repos.UnitOfWork.Do(ctx, func(ctx context.Context, tx repository.TxRepositories) error {
+		var err error
+		committed, err = enroll(ctx, tx)
+		return err
+	}))
+	found, err := repos.Purchases.GetPurchaseByUserAndCourse(ctx, userID, courseID)
+	mustDo(t, err)
+	if found.ID != committed.ID {
+		t.Fatalf("a compra da unidade confirmada deve permanecer: %+v", found)
+	}
+	_, err = repos.Ledger.FindByPurchase(ctx, committed.ID, dao.LedgerEntryPurchase)
+	mustDo(t, err)
+
+	err = repos.UnitOfWork.Do(ctx, func(ctx context.Context, tx repository.TxRepositories) error {
+		_, err := enroll(ctx, tx)
+		return err
+	})
+	if !errors.Is(err, dao.ErrPurchaseExists) {
+		t.Fatalf("a unidade deve repassar ErrPurchaseExists, retornou %v", err)
+	}
+}
@@ -2,24 +2,33 @@ package router
 
 import (
 	"log"
+	"metabee/internal/config"
 	"metabee/internal/controller"
 	"metabee/internal/middleware"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"metabee/internal/service"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func configRouter() cors.Config {
+func configRouter(conf *config.ConfigEnv) cors.Config {
+	corsConf := conf.CORS
 
 	return cors.Config{
-		AllowOrigins:     []string{"http://10.154.48.38:5173", "http://localhost:5173"},
+		AllowOrigins:     corsConf.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Device-Name", "X-API-Key"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		MaxAge:           time.Duration(corsConf.MaxAgeHours) * time.Hour,
 		AllowOriginFunc: func(origin string) bool {
+			if !corsConf.AllowLoopback {
+				return false
+			}
 			// Permitir requisições sem Origin (como do Electron)
 			if origin == "" {
 				return true
@@ -33,9 +42,29 @@ func configRouter() cors.Config {
 	}
 }
 
-func SetupMainRouter() *gin.Engine {
+// reloadableCORS monta o middleware de CORS e o remonta a cada recarga do config
+func reloadableCORS() gin.HandlerFunc {
+	var handler atomic.Pointer[gin.HandlerFunc]
+	build := func(conf *config.ConfigEnv) {
+		corsHandler := cors.New(configRouter(conf))
+		handler.Store(&corsHandler)
+	}
+
+	build(config.Current())
+	config.OnReload(build)
+
+	return func(c *gin.Context) {
+		(*handler.Load())(c)
+	}
+}
+
+// SetupMainRouter monta as rotas usando o backend de armazenamento recebido
+func SetupMainRouter(storage repository.Storage) *gin.Engine {
+	services := service.New(storage)
+	handlers := controller.New(storage.Repositories(), services)
+	authMiddleware := middleware.AuthMiddleware(storage.Repositories().Users, services)
 
-	gin.SetMode(gin.DebugMode)
+	gin.SetMode(config.Current().Service.Mode)
 
 	route := gin.New()
 	
@@ -55,10 +84,17 @@ func SetupMainRouter() *gin.Engine {
 	
 	route.Use(gin.Recovery())
 	route.Use(gin.Logger())
-	route.Use(cors.New(configRouter()))
+	route.Use(reloadableCORS())
 	
+	// Chaves públicas para que outros serviços (ex.: n8n) validem nossos tokens
+	route.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
+	// Sondas do orquestrador: /livez só confirma o processo; /readyz verifica as dependências
+	route.GET("/livez", handlers.GetLiveness)
+	route.GET("/readyz", handlers.GetReadiness)
+
 	// Rota para servir imagens do banco de dados (images/nome-da-img.extensao)
-	route.GET("/images/:imageName", controller.GetImageByName)
+	route.GET("/images/:imageName", handlers.GetImageByName)
 	
 	// Servir arquivos estáticos de imagens (se existirem) - fallback
 	route.Static("/images-static", "./images")
@@ -66,73 +102,118 @@ func SetupMainRouter() *gin.Engine {
 	main := route.Group("/metabee")
 	{
 		// Rotas públicas (sem autenticação)
-		main.GET("/health", controller.GetHealth)
-		main.POST("/register", controller.Register)
-		main.POST("/login", controller.Login)
+		main.GET("/health", handlers.GetLiveness) // Continua só de liveness: a prontidão fica em /readyz
+		main.POST("/register", handlers.Register)
+		main.POST("/login", handlers.Login)
+		main.POST("/auth/refresh", handlers.RefreshToken)             // POST /metabee/auth/refresh
+		main.POST("/auth/forgot-password", handlers.ForgotPassword) // POST /metabee/auth/forgot-password
+		main.POST("/auth/reset-password", handlers.ResetPassword)   // POST /metabee/auth/reset-password
+		main.GET("/auth/password-policy", handlers.GetPasswordPolicy) // GET /metabee/auth/password-policy
+		main.GET("/auth/verify", handlers.VerifyEmail)               // GET /metabee/auth/verify?token=...
+		main.POST("/auth/mfa/verify", handlers.VerifyMFALogin)       // POST /metabee/auth/mfa/verify
+		main.POST("/auth/oidc/google/start", handlers.StartGoogleLogin)       // POST /metabee/auth/oidc/google/start
+		main.POST("/auth/oidc/google/callback", handlers.CompleteGoogleLogin) // POST /metabee/auth/oidc/google/callback
 
 		// ============================================
 		// MARKETPLACE - Rotas Públicas
 		// ============================================
 		marketplace := main.Group("/marketplace")
 		{
-			marketplace.GET("/courses", controller.GetAllCourses) // GET /metabee/marketplace/courses
-			marketplace.GET("/stats", controller.GetMarketplaceStats) // GET /metabee/marketplace/stats
-			marketplace.GET("/courses/:courseId/image", controller.GetCourseImage) // GET /metabee/marketplace/courses/:id/image
+			marketplace.GET("/courses", handlers.GetAllCourses) // GET /metabee/marketplace/courses
+			marketplace.GET("/stats", handlers.GetMarketplaceStats) // GET /metabee/marketplace/stats
+			marketplace.GET("/courses/:courseId/image", handlers.GetCourseImage) // GET /metabee/marketplace/courses/:id/image
 		}
 
 		// ============================================
 		// NEWS - Rotas Públicas
 		// ============================================
 		// IMPORTANTE: Rotas mais específicas primeiro para evitar conflitos
-		main.GET("/news/last", controller.GetLastNews)              // GET /metabee/news/last
-		main.GET("/news/:id/image", controller.GetNewsImage)       // GET /metabee/news/:id/image
-		main.GET("/news/:id", controller.GetNewsByID)              // GET /metabee/news/:id
-		main.GET("/news", controller.GetAllNews)                   // GET /metabee/news
+		main.GET("/news/last", handlers.GetLastNews)              // GET /metabee/news/last
+		main.GET("/news/:id/image", handlers.GetNewsImage)       // GET /metabee/news/:id/image
+		main.GET("/news/:id", handlers.GetNewsByID)              // GET /metabee/news/:id
+		main.GET("/news", handlers.GetAllNews)                   // GET /metabee/news
 
 		// ============================================
 		// Rotas Autenticadas
 		// ============================================
 		// Usuário
 		user := main.Group("/user")
-		user.Use(middleware.AuthMiddleware)
+		user.Use(authMiddleware)
 		{
-			user.GET("auth/validate", controller.ValidateToken)
-			user.GET("/profile", controller.GetProfile)              // GET /metabee/user/profile
-			user.GET("/profile/image", controller.GetProfileImage)   // GET /metabee/user/profile/image
-			user.PUT("/profile", controller.UpdateProfile)           // PUT /metabee/user/profile
-			user.POST("/profile/image", controller.UpdateProfileImage) // POST /metabee/user/profile/image
+			user.GET("auth/validate", handlers.ValidateToken)
+			user.POST("/auth/logout", handlers.Logout)        // POST /metabee/user/auth/logout
+			user.POST("/auth/logout-all", handlers.LogoutAll) // POST /metabee/user/auth/logout-all
+			user.GET("/sessions", handlers.GetSessions)              // GET /metabee/user/sessions
+			user.DELETE("/sessions/:id", handlers.DeleteSession)     // DELETE /metabee/user/sessions/:id
+			user.GET("/api-keys", handlers.GetAPIKeys)               // GET /metabee/user/api-keys
+			user.POST("/api-keys", handlers.CreateAPIKey)            // POST /metabee/user/api-keys
+			user.DELETE("/api-keys/:id", handlers.RevokeAPIKey)      // DELETE /metabee/user/api-keys/:id
+			user.POST("/auth/resend-verification", handlers.ResendVerification) // POST /metabee/user/auth/resend-verification
+			user.POST("/mfa/enroll", handlers.StartMFAEnrollment)               // POST /metabee/user/mfa/enroll
+			user.POST("/mfa/confirm", handlers.ConfirmMFAEnrollment)            // POST /metabee/user/mfa/confirm
+			user.POST("/mfa/disable", handlers.DisableMFA)                      // POST /metabee/user/mfa/disable
+			user.GET("/profile", handlers.GetProfile)              // GET /metabee/user/profile
+			user.GET("/profile/image", handlers.GetProfileImage)   // GET /metabee/user/profile/image
+			user.PUT("/profile", handlers.UpdateProfile)           // PUT /metabee/user/profile
+			user.POST("/password", handlers.ChangePassword)         // POST /metabee/user/password
+			user.POST("/email", handlers.ChangeEmail)               // POST /metabee/user/email
+			user.POST("/profile/image", handlers.UpdateProfileImage) // POST /metabee/user/profile/image
 		}
 
 		// Dashboard
 		dashboard := main.Group("/dashboard")
-		dashboard.Use(middleware.AuthMiddleware)
+		dashboard.Use(authMiddleware)
 		{
-			dashboard.GET("/main", controller.Dashboard)
+			dashboard.GET("/main", handlers.Dashboard)
 		}
 
 		// Compras
 		purchase := main.Group("/purchase")
-		purchase.Use(middleware.AuthMiddleware)
+		purchase.Use(authMiddleware)
 		{
-			purchase.POST("/course", controller.PurchaseCourse)
-			purchase.GET("/my-courses", controller.GetMyCourses)
-			purchase.PUT("/download-status", controller.UpdateDownloadStatus)
+			purchase.POST("/course", handlers.PurchaseCourse)
+			purchase.GET("/my-courses", handlers.GetMyCourses)
+			purchase.PUT("/download-status", handlers.UpdateDownloadStatus)
 		}
 
+		// Cursos - upload de imagem (equipe ou chave de API com courses:write)
+		main.POST("/courses/:courseId/image",
+			middleware.AllowAPIKey(dao.ScopeCoursesWrite),
+			authMiddleware,
+			middleware.RequireRole(dao.RoleAdmin, dao.RoleInstructor),
+			handlers.UpdateCourseImage) // POST /metabee/courses/:id/image
+
 		// Cursos (rotas autenticadas)
 		coursesAuth := main.Group("/courses")
-		coursesAuth.Use(middleware.AuthMiddleware)
+		coursesAuth.Use(authMiddleware)
+		{
+			coursesAuth.GET("/:courseId/lessons", handlers.GetCourseLessons)        // GET /metabee/courses/:id/lessons
+			coursesAuth.GET("/:courseId/lesson/:lessonFile", handlers.GetLessonVideo) // GET /metabee/courses/:id/lesson/:file
+		}
+
+		// Administração
+		admin := main.Group("/admin")
+		admin.Use(authMiddleware, middleware.RequireRole(dao.RoleAdmin))
+		{
+			admin.PUT("/users/:userId/role", handlers.UpdateUserRole) // PUT /metabee/admin/users/:id/role
+			admin.POST("/users/:userId/unlock", handlers.UnlockUser)  // POST /metabee/admin/users/:id/unlock
+			admin.DELETE("/login-blocks/ip/:ip", handlers.UnlockIP)   // DELETE /metabee/admin/login-blocks/ip/:ip
+		}
+
+		// Sincronização offline (app desktop <-> servidor central)
+		syncGroup := main.Group("/sync")
+		syncGroup.Use(authMiddleware)
 		{
-			coursesAuth.POST("/:courseId/image", controller.UpdateCourseImage)        // POST /metabee/courses/:id/image - Upload
-			coursesAuth.GET("/:courseId/lessons", controller.GetCourseLessons)        // GET /metabee/courses/:id/lessons
-			coursesAuth.GET("/:courseId/lesson/:lessonFile", controller.GetLessonVideo) // GET /metabee/courses/:id/lesson/:file
+			syncGroup.GET("/changes", handlers.GetSyncChanges) // GET /metabee/sync/changes?since=...&limit=...
+			syncGroup.POST("/push", handlers.PushSyncChanges)  // POST /metabee/sync/push
+			syncGroup.POST("/run", handlers.RunSync)           // POST /metabee/sync/run
 		}
 
 		// Chat IA
 		chatIa := main.Group("/chat")
-		chatIa.Use(middleware.AuthMiddleware)
+		chatIa.Use(authMiddleware)
 		{
-			chatIa.POST("/ia", controller.ChatIa)
+			chatIa.POST("/ia", handlers.ChatIa)
 		}
 	}
 
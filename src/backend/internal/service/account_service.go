@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"metabee/internal/mailer"
+	"metabee/internal/model/dao"
+	"net/mail"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrCurrentPasswordInvalid = errors.New("senha atual incorreta")
+	ErrEmailInvalid           = errors.New("email inválido")
+	ErrEmailUnchanged         = errors.New("o novo email é igual ao atual")
+	ErrEmailInUse             = errors.New("este email já está em uso")
+)
+
+// RecordAudit registra uma alteração sensível na conta. Falhas são apenas
+// registradas no log para não desfazer uma alteração já concluída. O registro é
+// gravado mesmo que o cliente desconecte, já que a alteração foi feita.
+func (s *Service) RecordAudit(ctx context.Context, userID bson.ObjectID, action, ip, userAgent string, details map[string]string) {
+	err := s.auth.AuditLogs.CreateAuditLog(context.WithoutCancel(ctx), dao.AuditLogDao{
+		UserID:    userID,
+		Action:    action,
+		IP:        ip,
+		UserAgent: userAgent,
+		Details:   details,
+	})
+	if err != nil {
+		log.Printf("❌ Erro ao registrar auditoria (%s) - UserID: %s: %v", action, userID.Hex(), err)
+	}
+}
+
+// ChangePassword troca a senha do usuário autenticado após conferir a senha atual.
+// A sessão atual é mantida e todas as outras são encerradas. Erros de senha atual
+// contam para o bloqueio de login, já que um token roubado permitiria testar senhas.
+func (s *Service) ChangePassword(ctx context.Context, user dao.UserDao, sessionID, currentPassword, newPassword, ip, userAgent string) error {
+	if err := s.verifyCurrentPassword(ctx, user, currentPassword, ip); err != nil {
+		return err
+	}
+
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	newHash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.users.ReplacePasswordHash(ctx, user.ID, user.Password, newHash)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// A senha foi trocada por outra requisição depois que o usuário foi carregado
+		return ErrCurrentPasswordInvalid
+	}
+
+	if err := s.RevokeOtherSessions(ctx, user.ID, sessionID); err != nil {
+		return err
+	}
+	if err := s.auth.PasswordResets.InvalidateForUser(ctx, user.ID); err != nil {
+		log.Printf("⚠️  Erro ao invalidar links de redefinição - UserID: %s: %v", user.ID.Hex(), err)
+	}
+
+	s.RecordAudit(ctx, user.ID, dao.AuditPasswordChanged, ip, userAgent, nil)
+	log.Printf("✅ Senha alterada - UserID: %s", user.ID.Hex())
+
+	notifyAccountChange(user.Email, user.Name, "Sua senha foi alterada",
+		"A senha da sua conta Metabee foi alterada e as outras sessões foram encerradas.")
+	return nil
+}
+
+// RequestEmailChange envia um link de confirmação para o novo endereço. O email
+// da conta só é trocado quando o link é aberto (ver VerifyEmail).
+func (s *Service) RequestEmailChange(ctx context.Context, user dao.UserDao, currentPassword, newEmail, ip, userAgent string) error {
+	newEmail = strings.TrimSpace(strings.ToLower(newEmail))
+	if address, err := mail.ParseAddress(newEmail); err != nil || address.Address != newEmail {
+		return ErrEmailInvalid
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrEmailUnchanged
+	}
+
+	if err := s.verifyCurrentPassword(ctx, user, currentPassword, ip); err != nil {
+		return err
+	}
+
+	if _, err := s.users.FindUserByEmail(ctx, newEmail); err == nil {
+		return ErrEmailInUse
+	}
+
+	if err := s.SendEmailVerification(ctx, user, newEmail); err != nil {
+		return err
+	}
+
+	s.RecordAudit(ctx, user.ID, dao.AuditEmailChangeRequested, ip, userAgent, map[string]string{
+		"new_email": newEmail,
+	})
+	return nil
+}
+
+func (s *Service) verifyCurrentPassword(ctx context.Context, user dao.UserDao, currentPassword, ip string) error {
+	if err := s.CheckLoginAllowed(ctx, user.Email, ip); err != nil {
+		return err
+	}
+
+	if ok, _ := VerifyPassword(currentPassword, user.Password); !ok {
+		s.RegisterFailedLogin(ctx, user.Email, ip)
+		return ErrCurrentPasswordInvalid
+	}
+
+	return nil
+}
+
+// notifyAccountChange avisa o titular sobre uma alteração na conta. Falhas no
+// envio apenas são registradas no log.
+func notifyAccountChange(email, name, subject, summary string) {
+	body := fmt.Sprintf("Olá, %s!\n\n"+
+		"%s\n\n"+
+		"Data: %s\n\n"+
+		"Se não foi você, redefina sua senha imediatamente e entre em contato com o suporte.\n",
+		name, summary, time.Now().Format("02/01/2006 15:04"))
+
+	err := mailer.Default().Send(mailer.Message{
+		To:      email,
+		Subject: subject + " - Metabee",
+		Body:    body,
+	})
+	if err != nil {
+		log.Printf("⚠️  Erro ao enviar aviso de alteração para %s: %v", email, err)
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKeyPrefix identifica chaves de API no header Authorization
+const APIKeyPrefix = "mbk_"
+
+const (
+	defaultAPIKeyTTL = 90 * 24 * time.Hour
+	maxAPIKeyTTL     = 365 * 24 * time.Hour
+)
+
+var (
+	ErrAPIKeyInvalid       = errors.New("chave de API inválida, expirada ou revogada")
+	ErrAPIKeyScopeMissing  = errors.New("a chave de API não tem o escopo necessário")
+	ErrAPIKeyScopeInvalid  = errors.New("escopo inválido")
+	ErrAPIKeyScopeDenied   = errors.New("seu papel não permite conceder este escopo")
+	ErrAPIKeyNotFound      = errors.New("chave de API não encontrada")
+	ErrAPIKeyExpiryInvalid = errors.New("validade deve ser entre 1 e 365 dias")
+)
+
+// apiKeyScopeRoles define quais papéis podem conceder cada escopo às suas chaves.
+// O papel também é verificado em cada uso, já que a chave age em nome do usuário.
+var apiKeyScopeRoles = map[string][]string{
+	dao.ScopeCoursesWrite: {dao.RoleAdmin, dao.RoleInstructor},
+}
+
+// CreateAPIKey gera uma nova chave para o usuário. O valor completo é retornado
+// apenas aqui; depois disso só o prefixo é exibido.
+func (s *Service) CreateAPIKey(ctx context.Context, user dao.UserDao, name string, scopes []string, expiresInDays int) (string, dao.APIKeyDao, error) {
+	if len(scopes) == 0 {
+		return "", dao.APIKeyDao{}, ErrAPIKeyScopeInvalid
+	}
+	for _, scope := range scopes {
+		roles, known := apiKeyScopeRoles[scope]
+		if !known {
+			return "", dao.APIKeyDao{}, ErrAPIKeyScopeInvalid
+		}
+		if !containsString(roles, user.EffectiveRole()) {
+			return "", dao.APIKeyDao{}, ErrAPIKeyScopeDenied
+		}
+	}
+
+	ttl := defaultAPIKeyTTL
+	if expiresInDays != 0 {
+		ttl = time.Duration(expiresInDays) * 24 * time.Hour
+		if ttl <= 0 || ttl > maxAPIKeyTTL {
+			return "", dao.APIKeyDao{}, ErrAPIKeyExpiryInvalid
+		}
+	}
+
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", dao.APIKeyDao{}, err
+	}
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		return "", dao.APIKeyDao{}, err
+	}
+
+	prefix := APIKeyPrefix + hex.EncodeToString(prefixBytes)
+	rawKey := prefix + "_" + secret
+
+	key, err := s.auth.APIKeys.CreateAPIKey(ctx, dao.APIKeyDao{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashOpaqueToken(rawKey),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", dao.APIKeyDao{}, err
+	}
+
+	log.Printf("🔑 Chave de API criada - UserID: %s, Prefixo: %s, Escopos: %v", user.ID.Hex(), prefix, scopes)
+	return rawKey, key, nil
+}
+
+// ListAPIKeys lista as chaves ativas do usuário
+func (s *Service) ListAPIKeys(ctx context.Context, userID bson.ObjectID) ([]dao.APIKeyDao, error) {
+	return s.auth.APIKeys.FindByUser(ctx, userID)
+}
+
+// RevokeAPIKey revoga uma chave do usuário
+func (s *Service) RevokeAPIKey(ctx context.Context, userID bson.ObjectID, keyID string) error {
+	id, err := bson.ObjectIDFromHex(keyID)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	revoked, err := s.auth.APIKeys.Revoke(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// IsAPIKey indica se a credencial recebida tem o formato de uma chave de API
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// AuthenticateAPIKey valida a chave e o escopo exigido pela rota e retorna a
+// chave e o usuário dono dela.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, rawKey, requiredScope, ip string) (dao.APIKeyDao, dao.UserDao, error) {
+	key, err := s.auth.APIKeys.FindByHash(ctx, hashOpaqueToken(rawKey))
+	if err != nil || !key.RevokedAt.IsZero() || key.ExpiresAt.Before(time.Now()) {
+		return dao.APIKeyDao{}, dao.UserDao{}, ErrAPIKeyInvalid
+	}
+
+	if !key.HasScope(requiredScope) {
+		return dao.APIKeyDao{}, dao.UserDao{}, ErrAPIKeyScopeMissing
+	}
+
+	user, err := s.users.FindUserByID(ctx, key.UserID.Hex())
+	if err != nil {
+		return dao.APIKeyDao{}, dao.UserDao{}, ErrAPIKeyInvalid
+	}
+	// RevokeAllSessions também revoga as chaves; isto cobre uma revogação que parou no meio
+	if key.CreatedAt.Before(user.TokensValidAfter) {
+		return dao.APIKeyDao{}, dao.UserDao{}, ErrAPIKeyInvalid
+	}
+
+	// Uma escrita por minuto no máximo
+	if err := s.auth.APIKeys.TouchIfIdle(ctx, key.ID, ip, time.Now().Add(-time.Minute)); err != nil {
+		log.Printf("⚠️  Erro ao registrar uso da chave %s: %v", key.Prefix, err)
+	}
+
+	return key, user, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Encerrar as sessões (logout geral, redefinição ou troca de senha) também
+// desativa as chaves de API: uma chave criada com a senha vazada não pode sobreviver
+func TestRevokingSessionsRevokesAPIKeys(t *testing.T) {
+	revokers := map[string]func(services *service.Service, userID bson.ObjectID) error{
+		"RevokeAllSessions": func(services *service.Service, userID bson.ObjectID) error {
+			return services.RevokeAllSessions(context.Background(), userID)
+		},
+		"RevokeOtherSessions": func(services *service.Service, userID bson.ObjectID) error {
+			return services.RevokeOtherSessions(context.Background(), userID, bson.NewObjectID().Hex())
+		},
+	}
+
+	for name, revoke := range revokers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := openTestStorage(t)
+			services := service.New(storage)
+			users := storage.Repositories().Users
+
+			user, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := users.UpdateUserProfile(ctx, user.ID, bson.M{"role": dao.RoleInstructor}); err != nil {
+				t.Fatal(err)
+			}
+			user.Role = dao.RoleInstructor
+
+			rawKey, _, err := services.CreateAPIKey(ctx, user, "importação", []string{dao.ScopeCoursesWrite}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := services.AuthenticateAPIKey(ctx, rawKey, dao.ScopeCoursesWrite, "10.0.0.1"); err != nil {
+				t.Fatalf("a chave nova deve autenticar: %v", err)
+			}
+
+			if err := revoke(services, user.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := services.AuthenticateAPIKey(ctx, rawKey, dao.ScopeCoursesWrite, "10.0.0.1"); !errors.Is(err, service.ErrAPIKeyInvalid) {
+				t.Fatalf("a chave deve ser recusada depois de %s, retornou %v", name, err)
+			}
+			keys, err := services.ListAPIKeys(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != 0 {
+				t.Fatalf("a chave revogada não deve aparecer na lista: %+v", keys)
+			}
+		})
+	}
+}
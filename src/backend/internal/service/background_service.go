@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundWorkers acompanha as goroutines de manutenção para que o
+// encerramento do servidor espere o trabalho em andamento terminar.
+var backgroundWorkers sync.WaitGroup
+
+func runInBackground(worker func()) {
+	backgroundWorkers.Add(1)
+	go func() {
+		defer backgroundWorkers.Done()
+		worker()
+	}()
+}
+
+// WaitBackgroundWorkers espera os workers iniciados pelo serviço terminarem,
+// até o fim de ctx. Os workers param quando o contexto recebido no Start é cancelado.
+func WaitBackgroundWorkers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		backgroundWorkers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/mailer"
+	"metabee/internal/model/dao"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrEmailVerificationInvalid = errors.New("link de verificação inválido")
+	ErrEmailVerificationExpired = errors.New("link de verificação expirado")
+)
+
+// EmailVerificationTTL retorna a validade configurada para links de verificação (padrão: 48 horas)
+func EmailVerificationTTL() time.Duration {
+	if config.Current().Mail.EmailVerificationTTLHours > 0 {
+		return time.Duration(config.Current().Mail.EmailVerificationTTLHours) * time.Hour
+	}
+	return 48 * time.Hour
+}
+
+// SendEmailVerification envia um link de confirmação para o email informado.
+// Links anteriores do mesmo usuário e com a mesma finalidade (confirmar o email
+// atual ou trocar de email) deixam de valer.
+func (s *Service) SendEmailVerification(ctx context.Context, user dao.UserDao, email string) error {
+	purpose := dao.EmailVerificationPurposeConfirm
+	if !strings.EqualFold(email, user.Email) {
+		purpose = dao.EmailVerificationPurposeChange
+	}
+
+	if err := s.auth.EmailVerifications.InvalidateForUser(ctx, user.ID, purpose); err != nil {
+		return err
+	}
+
+	rawToken, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := EmailVerificationTTL()
+	_, err = s.auth.EmailVerifications.CreateEmailVerification(ctx, dao.EmailVerificationDao{
+		UserID:    user.ID,
+		Email:     email,
+		Purpose:   purpose,
+		TokenHash: hashOpaqueToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	intro := "Confirme seu email para ativar todos os recursos da sua conta Metabee."
+	ignore := "Se você não criou esta conta, ignore este email."
+	subject := "Confirme seu email - Metabee"
+	if purpose == dao.EmailVerificationPurposeChange {
+		intro = "Confirme este endereço para que ele passe a ser o email da sua conta Metabee."
+		ignore = "Se você não pediu a troca de email, ignore esta mensagem."
+		subject = "Confirme seu novo email - Metabee"
+	}
+
+	body := fmt.Sprintf("Olá, %s!\n\n"+
+		"%s\n\n"+
+		"%s\n\n"+
+		"O link expira em %d horas.\n"+
+		"%s\n",
+		user.Name, intro, emailVerificationLink(rawToken), int(ttl.Hours()), ignore)
+
+	return mailer.Default().Send(mailer.Message{
+		To:      email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// VerifyEmail confirma o email associado ao token e retorna o ID do usuário
+func (s *Service) VerifyEmail(ctx context.Context, rawToken string) (bson.ObjectID, error) {
+	if rawToken == "" {
+		return bson.NilObjectID, ErrEmailVerificationInvalid
+	}
+
+	verification, err := s.auth.EmailVerifications.FindByHash(ctx, hashOpaqueToken(rawToken))
+	if err != nil || !verification.UsedAt.IsZero() {
+		return bson.NilObjectID, ErrEmailVerificationInvalid
+	}
+
+	if verification.ExpiresAt.Before(time.Now()) {
+		return bson.NilObjectID, ErrEmailVerificationExpired
+	}
+
+	user, err := s.users.FindUserByID(ctx, verification.UserID.Hex())
+	if err != nil {
+		return bson.NilObjectID, ErrEmailVerificationInvalid
+	}
+
+	// Troca de email: o endereço pode ter sido cadastrado por outra conta depois do pedido
+	emailChanged := !strings.EqualFold(verification.Email, user.Email)
+	if emailChanged {
+		if existing, err := s.users.FindUserByEmail(ctx, verification.Email); err == nil && existing.ID != user.ID {
+			return bson.NilObjectID, ErrEmailInUse
+		}
+	}
+
+	// O token só é consumido depois das verificações: um conflito de email não invalida o link
+	if err := s.auth.EmailVerifications.MarkUsed(ctx, verification.ID); err != nil {
+		if errors.Is(err, dao.ErrEmailVerificationAlreadyUsed) {
+			return bson.NilObjectID, ErrEmailVerificationInvalid
+		}
+		return bson.NilObjectID, err
+	}
+
+	err = s.users.UpdateUserProfile(ctx, verification.UserID, bson.M{
+		"email":             verification.Email,
+		"email_unverified":  false,
+		"email_verified_at": time.Now(),
+	})
+	if err != nil {
+		return bson.NilObjectID, err
+	}
+
+	log.Printf("✅ Email verificado - UserID: %s, Email: %s", verification.UserID.Hex(), verification.Email)
+
+	if emailChanged {
+		s.RecordAudit(ctx, user.ID, dao.AuditEmailChanged, "", "", map[string]string{
+			"old_email": user.Email,
+			"new_email": verification.Email,
+		})
+		notifyAccountChange(user.Email, user.Name, "O email da sua conta foi alterado",
+			"O email da sua conta Metabee foi alterado para "+verification.Email+".")
+	}
+
+	return verification.UserID, nil
+}
+
+func emailVerificationLink(rawToken string) string {
+	baseURL := config.Current().Mail.EmailVerificationURL
+	if baseURL == "" {
+		return "Código de verificação: " + rawToken
+	}
+	return "Acesse o link para confirmar: " + baseURL + "?token=" + url.QueryEscape(rawToken)
+}
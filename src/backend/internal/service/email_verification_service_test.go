@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/mailer"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"regexp"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var verificationCodePattern = regexp.MustCompile(`Código de verificação: (\S+)`)
+
+// Um conflito de email recusa a troca sem consumir o link: liberado o endereço, o mesmo link funciona
+func TestVerifyEmailKeepsTokenOnConflict(t *testing.T) {
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	services := service.New(storage)
+	users := storage.Repositories().Users
+
+	mail := &captureMailer{}
+	mailer.SetDefault(mail)
+	t.Cleanup(func() { mailer.SetDefault(nil) })
+
+	ana, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bia, err := users.CreateUser(ctx, dao.UserDao{Name: "Bia", Email: "novo@metabee.test", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := services.SendEmailVerification(ctx, ana, "novo@metabee.test"); err != nil {
+		t.Fatal(err)
+	}
+	match := verificationCodePattern.FindStringSubmatch(mail.last.Body)
+	if match == nil {
+		t.Fatalf("o email não tem o código de verificação: %q", mail.last.Body)
+	}
+
+	if _, err := services.VerifyEmail(ctx, match[1]); !errors.Is(err, service.ErrEmailInUse) {
+		t.Fatalf("o email de outra conta deve ser recusado, retornou %v", err)
+	}
+
+	if err := users.UpdateUserProfile(ctx, bia.ID, bson.M{"email": "bia@metabee.test"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := services.VerifyEmail(ctx, match[1]); err != nil {
+		t.Fatalf("o link deve continuar valendo depois do conflito: %v", err)
+	}
+	saved, err := users.FindUserByID(ctx, ana.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Email != "novo@metabee.test" {
+		t.Fatalf("o email não foi trocado: %s", saved.Email)
+	}
+	if _, err := services.VerifyEmail(ctx, match[1]); !errors.Is(err, service.ErrEmailVerificationInvalid) {
+		t.Fatalf("o link deve ser consumido depois da troca, retornou %v", err)
+	}
+}
+
+// Reenviar a confirmação do email atual não pode matar o link de uma troca de email pendente
+func TestResendVerificationKeepsPendingEmailChange(t *testing.T) {
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	services := service.New(storage)
+	users := storage.Repositories().Users
+
+	mail := &captureMailer{}
+	mailer.SetDefault(mail)
+	t.Cleanup(func() { mailer.SetDefault(nil) })
+
+	ana, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := services.SendEmailVerification(ctx, ana, "novo@metabee.test"); err != nil {
+		t.Fatal(err)
+	}
+	change := verificationCodePattern.FindStringSubmatch(mail.last.Body)
+	if change == nil {
+		t.Fatalf("o email não tem o código de verificação: %q", mail.last.Body)
+	}
+
+	if err := services.SendEmailVerification(ctx, ana, ana.Email); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := services.VerifyEmail(ctx, change[1]); err != nil {
+		t.Fatalf("o link da troca de email deve continuar valendo: %v", err)
+	}
+	saved, err := users.FindUserByID(ctx, ana.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Email != "novo@metabee.test" {
+		t.Fatalf("o email não foi trocado: %s", saved.Email)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"metabee/internal/config"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	storagePingTimeout = 2 * time.Second
+	aiCheckTimeout     = 3 * time.Second
+	aiCheckCacheMaxAge = 30 * time.Second // Evita bater no webhook a cada sonda do orquestrador
+)
+
+// DependencyStatus é o resultado da verificação de uma dependência
+type DependencyStatus struct {
+	Healthy   bool      `json:"healthy"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+var (
+	aiCheckMu     sync.Mutex
+	aiCheckCached DependencyStatus
+	aiCheckURL    string
+)
+
+// CheckDependencies verifica o armazenamento (mongodb ou embedded) e o backend de
+// IA. O resultado do backend de IA fica em cache por aiCheckCacheMaxAge.
+func (s *Service) CheckDependencies(ctx context.Context) map[string]DependencyStatus {
+	return map[string]DependencyStatus{
+		s.storage.Name(): s.checkStorage(ctx),
+		"ai_backend":     checkAIBackend(ctx),
+	}
+}
+
+func (s *Service) checkStorage(ctx context.Context) DependencyStatus {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, storagePingTimeout)
+	defer cancel()
+	return dependencyResult(started, s.storage.Ping(ctx))
+}
+
+// checkAIBackend confere se o webhook do n8n responde. Qualquer resposta abaixo de
+// 500 conta como alcançável: o webhook só aceita POST e não deve ser acionado aqui.
+func checkAIBackend(ctx context.Context) DependencyStatus {
+	webhookURL := config.GetN8nWebhookURL()
+
+	aiCheckMu.Lock()
+	cached, cachedURL := aiCheckCached, aiCheckURL
+	aiCheckMu.Unlock()
+
+	if cachedURL == webhookURL && time.Since(cached.CheckedAt) < aiCheckCacheMaxAge {
+		return cached
+	}
+
+	// A requisição fica fora do lock: um webhook lento não pode enfileirar as outras sondas
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, aiCheckTimeout)
+	defer cancel()
+
+	err := func() error {
+		request, err := http.NewRequestWithContext(ctx, http.MethodHead, webhookURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	}()
+
+	status := dependencyResult(started, err)
+
+	aiCheckMu.Lock()
+	aiCheckURL = webhookURL
+	aiCheckCached = status
+	aiCheckMu.Unlock()
+	return status
+}
+
+func dependencyResult(started time.Time, err error) DependencyStatus {
+	status := DependencyStatus{
+		Healthy:   err == nil,
+		LatencyMs: time.Since(started).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"metabee/internal/config"
+	"metabee/internal/model/dao"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,67 +13,200 @@ import (
 
 // JWTClaims define as claims customizadas usadas no token.
 type JWTClaims struct {
-	UserID string `json:"user_id"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`     // Família de refresh tokens que originou o token
+	Purpose   string `json:"purpose,omitempty"` // Vazio em access tokens; tokens com propósito específico não autenticam rotas
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT gera um token JWT com o ID do usuário e expiração padrão de 24h.
-func GenerateJWT(userID string) (string, error) {
-	if userID == "" {
+// Propósitos de tokens que não são access tokens
+const (
+	TokenPurposeMFAPending = "mfa_pending"
+)
+
+// mfaPendingTokenTTL é o tempo que o usuário tem para informar o código TOTP após a senha
+const mfaPendingTokenTTL = 5 * time.Minute
+
+// GenerateJWT gera um access token JWT de curta duração para o usuário e a sessão informados.
+func GenerateJWT(user dao.UserDao, sessionID string) (string, error) {
+	userID := user.ID.Hex()
+	if user.ID.IsZero() {
 		return "", errors.New("userID não pode ser vazio")
 	}
 
-	jwtSecret := config.Env.Jwt.JWTSECRET
-	if jwtSecret == "" {
-		return "", errors.New("JWT_SECRET não definido no .env")
+	jti, err := generateTokenID()
+	if err != nil {
+		return "", err
 	}
 
 	claims := JWTClaims{
-		UserID: userID,
+		UserID:    userID,
+		Role:      user.EffectiveRole(),
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(jwtSecret))
+	return signToken(claims)
+}
+
+// GenerateMFAPendingToken gera o token de curta duração entregue após a senha
+// quando o usuário tem 2FA ativo. Ele só pode ser trocado por um access token
+// junto com um código TOTP válido.
+func GenerateMFAPendingToken(user dao.UserDao) (string, error) {
+	jti, err := generateTokenID()
 	if err != nil {
 		return "", err
 	}
 
-	return signedToken, nil
+	claims := JWTClaims{
+		UserID:  user.ID.Hex(),
+		Purpose: TokenPurposeMFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaPendingTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signToken(claims)
+}
+
+// signToken assina as claims com a chave atual (header kid) ou, no modo legado, com jwtsecret
+func signToken(claims JWTClaims) (string, error) {
+	if !usesAsymmetricSigning() {
+		jwtSecret := config.Current().Jwt.JWTSECRET
+		if jwtSecret == "" {
+			return "", errors.New("JWT_SECRET não definido no .env")
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(jwtSecret))
+	}
+
+	key, err := currentSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.Private)
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case SigningAlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case SigningAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// AccessTokenTTL retorna a validade configurada para access tokens (padrão: 15 minutos)
+func AccessTokenTTL() time.Duration {
+	if config.Current().Jwt.AccessTokenTTLMinutes > 0 {
+		return time.Duration(config.Current().Jwt.AccessTokenTTLMinutes) * time.Minute
+	}
+	return 15 * time.Minute
 }
 
 // ValidateJWT valida o token recebido e retorna o userID presente nas claims.
 func ValidateJWT(tokenString string) (string, error) {
-	jwtSecret := config.Env.Jwt.JWTSECRET
-	if jwtSecret == "" {
-		return "", errors.New("JWT_SECRET não definido no .env")
+	claims, err := ParseJWT(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.UserID, nil
+}
+
+// ParseJWT valida a assinatura e a expiração do access token e retorna todas as claims.
+func ParseJWT(tokenString string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" {
+		return nil, errors.New("token não é um access token")
+	}
+
+	return claims, nil
+}
+
+// ParseMFAPendingToken valida um token emitido por GenerateMFAPendingToken
+func ParseMFAPendingToken(tokenString string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != TokenPurposeMFAPending {
+		return nil, errors.New("token não é um token de verificação em duas etapas")
+	}
+
+	return claims, nil
+}
+
+func parseToken(tokenString string) (*JWTClaims, error) {
+	validMethods := []string{}
+	if acceptsSharedSecret() {
+		validMethods = append(validMethods, jwt.SigningMethodHS256.Name)
+	}
+	if usesAsymmetricSigning() {
+		// Tokens assinados com o algoritmo anterior continuam válidos enquanto a chave estiver carregada
+		validMethods = append(validMethods, jwt.SigningMethodRS256.Name, jwt.SigningMethodEdDSA.Alg())
+	}
+	if len(validMethods) == 0 {
+		return nil, errors.New("JWT_SECRET não definido no .env")
 	}
 
 	claims := &JWTClaims{}
-	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+	parser := jwt.NewParser(jwt.WithValidMethods(validMethods))
 
 	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
+		algorithm := token.Method.Alg()
+		if algorithm == jwt.SigningMethodHS256.Name {
+			return []byte(config.Current().Jwt.JWTSECRET), nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("kid ausente no token")
+		}
+		return verificationKey(kid, algorithm)
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return "", errors.New("token inválido ou expirado")
+		return nil, errors.New("token inválido ou expirado")
 	}
 
 	// Verifica se expirou
 	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-		return "", errors.New("token expirado")
+		return nil, errors.New("token expirado")
 	}
 
 	if claims.UserID == "" {
-		return "", errors.New("user_id ausente no token")
+		return nil, errors.New("user_id ausente no token")
 	}
 
-	return claims.UserID, nil
+	return claims, nil
+}
+
+// generateTokenID gera um identificador aleatório para a claim jti
+func generateTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
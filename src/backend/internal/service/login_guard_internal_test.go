@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{7, 16 * time.Second},
+		{8, maxLoginDelay}, // 32s passaria do máximo
+		{50, maxLoginDelay},
+	}
+	for _, tc := range cases {
+		if got := loginDelay(tc.failures); got != tc.want {
+			t.Errorf("loginDelay(%d) = %s, esperava %s", tc.failures, got, tc.want)
+		}
+	}
+}
+
+// Variações de caixa e espaços no email não podem abrir uma nova contagem de falhas
+func TestAccountAttemptKeyNormalizesEmail(t *testing.T) {
+	if accountAttemptKey(" Ana@Metabee.test ") != accountAttemptKey("ana@metabee.test") {
+		t.Fatal("o email deve ser normalizado antes de montar a chave")
+	}
+	if accountAttemptKey("10.0.0.1") == ipAttemptKey("10.0.0.1") {
+		t.Fatal("chaves de conta e de IP não podem colidir")
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"metabee/internal/config"
+	"strings"
+	"time"
+)
+
+// Códigos de erro retornados ao cliente quando o login é recusado
+const (
+	LoginErrorAccountLocked   = "account_locked"
+	LoginErrorIPBlocked       = "ip_blocked"
+	LoginErrorTooManyAttempts = "too_many_attempts"
+)
+
+// A partir desta quantidade de falhas cada nova tentativa precisa esperar
+// 1s, 2s, 4s... (até maxLoginDelay) desde a última falha.
+const (
+	loginDelayAfterFailures = 3
+	maxLoginDelay           = 30 * time.Second
+)
+
+// LoginBlockedError indica que a tentativa de login foi recusada antes da verificação da senha.
+type LoginBlockedError struct {
+	Code       string
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return fmt.Sprintf("login bloqueado (%s), tente novamente em %s", e.Code, e.RetryAfter.Round(time.Second))
+}
+
+func loginGuardSettings() (maxPerAccount, maxPerIP int, window, lockout time.Duration) {
+	authConf := config.Current().Auth
+
+	maxPerAccount = authConf.MaxFailedLoginsPerAccount
+	if maxPerAccount <= 0 {
+		maxPerAccount = 5
+	}
+	maxPerIP = authConf.MaxFailedLoginsPerIP
+	if maxPerIP <= 0 {
+		maxPerIP = 20
+	}
+	window = time.Duration(authConf.FailedLoginWindowMinutes) * time.Minute
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	lockout = time.Duration(authConf.LockoutMinutes) * time.Minute
+	if lockout <= 0 {
+		lockout = 15 * time.Minute
+	}
+	return
+}
+
+func accountAttemptKey(email string) string {
+	return "email:" + strings.TrimSpace(strings.ToLower(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// CheckLoginAllowed verifica bloqueios e atrasos progressivos antes de validar a senha.
+// Retorna *LoginBlockedError quando a tentativa deve ser recusada.
+func (s *Service) CheckLoginAllowed(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	ipAttempt, err := s.auth.LoginAttempts.FindByKey(ctx, ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if ipAttempt.LockedUntil.After(now) {
+		return &LoginBlockedError{Code: LoginErrorIPBlocked, RetryAfter: ipAttempt.LockedUntil.Sub(now)}
+	}
+
+	accountAttempt, err := s.auth.LoginAttempts.FindByKey(ctx, accountAttemptKey(email))
+	if err != nil {
+		return err
+	}
+	if accountAttempt.LockedUntil.After(now) {
+		return &LoginBlockedError{Code: LoginErrorAccountLocked, RetryAfter: accountAttempt.LockedUntil.Sub(now)}
+	}
+
+	if accountAttempt.Failures >= loginDelayAfterFailures {
+		nextAllowed := accountAttempt.LastFailureAt.Add(loginDelay(accountAttempt.Failures))
+		if nextAllowed.After(now) {
+			return &LoginBlockedError{Code: LoginErrorTooManyAttempts, RetryAfter: nextAllowed.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// RegisterFailedLogin contabiliza a falha para a conta e para o IP, bloqueando-os ao atingir o limite
+func (s *Service) RegisterFailedLogin(ctx context.Context, email, ip string) {
+	// Desconectar logo após errar a senha não pode evitar a contagem
+	ctx = context.WithoutCancel(ctx)
+	maxPerAccount, maxPerIP, window, lockout := loginGuardSettings()
+	retention := window + lockout
+
+	accountKey := accountAttemptKey(email)
+	accountAttempt, err := s.auth.LoginAttempts.RegisterFailure(ctx, accountKey, window, retention)
+	if err != nil {
+		log.Printf("❌ Erro ao registrar falha de login para %s: %v", accountKey, err)
+	} else if accountAttempt.Failures >= maxPerAccount {
+		log.Printf("🔒 Conta bloqueada por %s após %d falhas: %s", lockout, accountAttempt.Failures, accountKey)
+		if err := s.auth.LoginAttempts.Lock(ctx, accountKey, time.Now().Add(lockout)); err != nil {
+			log.Printf("❌ Erro ao bloquear %s: %v", accountKey, err)
+		}
+	}
+
+	ipKey := ipAttemptKey(ip)
+	ipAttempt, err := s.auth.LoginAttempts.RegisterFailure(ctx, ipKey, window, retention)
+	if err != nil {
+		log.Printf("❌ Erro ao registrar falha de login para %s: %v", ipKey, err)
+	} else if ipAttempt.Failures >= maxPerIP {
+		log.Printf("🔒 IP bloqueado por %s após %d falhas: %s", lockout, ipAttempt.Failures, ipKey)
+		if err := s.auth.LoginAttempts.Lock(ctx, ipKey, time.Now().Add(lockout)); err != nil {
+			log.Printf("❌ Erro ao bloquear %s: %v", ipKey, err)
+		}
+	}
+}
+
+// RegisterSuccessfulLogin limpa o histórico de falhas da conta. O contador do IP
+// é mantido para que uma conta válida não sirva para "zerar" um ataque.
+func (s *Service) RegisterSuccessfulLogin(ctx context.Context, email string) {
+	if err := s.auth.LoginAttempts.Reset(ctx, accountAttemptKey(email)); err != nil {
+		log.Printf("⚠️  Erro ao limpar falhas de login de %s: %v", email, err)
+	}
+}
+
+// UnlockAccount remove o bloqueio e o histórico de falhas da conta (operação administrativa)
+func (s *Service) UnlockAccount(ctx context.Context, email string) error {
+	return s.auth.LoginAttempts.Reset(ctx, accountAttemptKey(email))
+}
+
+// UnlockIP remove o bloqueio e o histórico de falhas do IP (operação administrativa)
+func (s *Service) UnlockIP(ctx context.Context, ip string) error {
+	return s.auth.LoginAttempts.Reset(ctx, ipAttemptKey(ip))
+}
+
+func loginDelay(failures int) time.Duration {
+	exponent := failures - loginDelayAfterFailures
+	if exponent > 5 {
+		return maxLoginDelay
+	}
+	delay := time.Second << exponent
+	if delay > maxLoginDelay {
+		return maxLoginDelay
+	}
+	return delay
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"metabee/internal/service"
+	"testing"
+	"time"
+)
+
+func TestLoginGuard(t *testing.T) {
+	useTestConfig(t, `
+[auth]
+maxFailedLoginsPerAccount = 5
+maxFailedLoginsPerIP = 8
+lockoutMinutes = 15
+`)
+
+	ctx := context.Background()
+	services := service.New(openTestStorage(t))
+
+	// blocked devolve o código e a espera da recusa, ou "" quando o login é permitido
+	blocked := func(t *testing.T, email, ip string) (string, time.Duration) {
+		t.Helper()
+		err := services.CheckLoginAllowed(ctx, email, ip)
+		if err == nil {
+			return "", 0
+		}
+		var blockedErr *service.LoginBlockedError
+		if !errors.As(err, &blockedErr) {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		return blockedErr.Code, blockedErr.RetryAfter
+	}
+
+	t.Run("espera progressiva e bloqueio da conta", func(t *testing.T) {
+		const email, ip = "ana@metabee.test", "10.0.0.1"
+
+		for i := 1; i <= 2; i++ {
+			services.RegisterFailedLogin(ctx, email, ip)
+			if code, _ := blocked(t, email, ip); code != "" {
+				t.Fatalf("após %d falhas o login ainda deve ser permitido, recusado com %s", i, code)
+			}
+		}
+
+		// A partir da terceira falha cada tentativa espera 1s, 2s, 4s...
+		for i, maxWait := range []time.Duration{time.Second, 2 * time.Second} {
+			services.RegisterFailedLogin(ctx, email, ip)
+			code, retryAfter := blocked(t, email, ip)
+			if code != service.LoginErrorTooManyAttempts || retryAfter <= maxWait/2 || retryAfter > maxWait {
+				t.Fatalf("falha %d: esperava %s com espera até %s, recebeu %q %s", i+3, service.LoginErrorTooManyAttempts, maxWait, code, retryAfter)
+			}
+		}
+
+		// O email é normalizado: variações de caixa contam para a mesma conta
+		services.RegisterFailedLogin(ctx, " Ana@Metabee.test", ip)
+		code, retryAfter := blocked(t, email, ip)
+		if code != service.LoginErrorAccountLocked || retryAfter < 14*time.Minute {
+			t.Fatalf("esperava a conta bloqueada por 15 minutos, recebeu %q %s", code, retryAfter)
+		}
+
+		if err := services.UnlockAccount(ctx, email); err != nil {
+			t.Fatal(err)
+		}
+		if code, _ := blocked(t, email, ip); code != "" {
+			t.Fatalf("o desbloqueio deve liberar a conta, recusado com %s", code)
+		}
+	})
+
+	t.Run("bloqueio do IP e sucesso que não zera o IP", func(t *testing.T) {
+		const ip = "10.0.0.2"
+
+		// Sete falhas em contas diferentes e um login correto não liberam o IP
+		for _, email := range []string{"a@metabee.test", "b@metabee.test", "c@metabee.test", "d@metabee.test", "e@metabee.test", "f@metabee.test", "g@metabee.test"} {
+			services.RegisterFailedLogin(ctx, email, ip)
+		}
+		services.RegisterSuccessfulLogin(ctx, "g@metabee.test")
+		if code, _ := blocked(t, "h@metabee.test", ip); code != "" {
+			t.Fatalf("abaixo do limite o IP deve ser permitido, recusado com %s", code)
+		}
+
+		services.RegisterFailedLogin(ctx, "h@metabee.test", ip)
+		if code, _ := blocked(t, "outra@metabee.test", ip); code != service.LoginErrorIPBlocked {
+			t.Fatalf("esperava %s, recebeu %q", service.LoginErrorIPBlocked, code)
+		}
+		if code, _ := blocked(t, "outra@metabee.test", "10.0.0.3"); code != "" {
+			t.Fatalf("o bloqueio vale só para o IP, outro IP recusado com %s", code)
+		}
+
+		if err := services.UnlockIP(ctx, ip); err != nil {
+			t.Fatal(err)
+		}
+		if code, _ := blocked(t, "outra@metabee.test", ip); code != "" {
+			t.Fatalf("o desbloqueio deve liberar o IP, recusado com %s", code)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var recoveryCodeFormat = regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != mfaRecoveryCodeCount || len(hashes) != mfaRecoveryCodeCount {
+		t.Fatalf("esperava %d códigos e hashes, vieram %d e %d", mfaRecoveryCodeCount, len(codes), len(hashes))
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if !recoveryCodeFormat.MatchString(code) {
+			t.Errorf("código fora do formato xxxxx-xxxxx: %q", code)
+		}
+		if seen[code] {
+			t.Errorf("código repetido: %q", code)
+		}
+		seen[code] = true
+
+		// Só o hash é gravado: ele não pode conter o código
+		if hashes[i] == code || strings.Contains(hashes[i], strings.ReplaceAll(code, "-", "")) {
+			t.Errorf("hash expõe o código %q", code)
+		}
+		if hashes[i] != hashRecoveryCode(code) {
+			t.Errorf("hash gravado não confere com o código %q", code)
+		}
+	}
+}
+
+// O usuário pode digitar o código sem o hífen, com espaços ou em maiúsculas
+func TestHashRecoveryCodeNormalizesInput(t *testing.T) {
+	want := hashRecoveryCode("abcde-fghij")
+	for _, typed := range []string{"abcdefghij", "ABCDE-FGHIJ", "abcde fghij"} {
+		if hashRecoveryCode(typed) != want {
+			t.Errorf("%q deveria corresponder a abcde-fghij", typed)
+		}
+	}
+	if hashRecoveryCode("abcde-fghik") == want {
+		t.Error("códigos diferentes não podem ter o mesmo hash")
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/model/dao"
+	"metabee/internal/util"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrMFAInvalidCode         = errors.New("código de verificação inválido")
+	ErrMFANotEnabled          = errors.New("verificação em duas etapas não está ativa")
+	ErrMFAAlreadyEnabled      = errors.New("verificação em duas etapas já está ativa")
+	ErrMFANoPendingEnrollment = errors.New("nenhum cadastro de verificação em duas etapas pendente")
+	ErrMFAPendingTokenInvalid = errors.New("token de verificação em duas etapas inválido ou expirado")
+)
+
+const mfaRecoveryCodeCount = 10
+
+// MFAEnrollment contém os dados exibidos ao usuário para cadastrar o aplicativo autenticador
+type MFAEnrollment struct {
+	Secret     string
+	OTPAuthURI string
+}
+
+func mfaIssuer() string {
+	if config.Current().Auth.MFAIssuer != "" {
+		return config.Current().Auth.MFAIssuer
+	}
+	return "Metabee"
+}
+
+// MFAEnrollmentRequired indica se a conta é de equipe e precisa cadastrar 2FA
+// antes de usar as rotas de gerenciamento de conteúdo.
+func MFAEnrollmentRequired(user dao.UserDao) bool {
+	return config.Current().Auth.RequireMFAForStaff && user.IsStaff() && !user.MFAEnabled
+}
+
+// StartMFAEnrollment gera um novo segredo TOTP, que só passa a valer após ConfirmMFAEnrollment
+func (s *Service) StartMFAEnrollment(ctx context.Context, user dao.UserDao) (MFAEnrollment, error) {
+	if user.MFAEnabled {
+		return MFAEnrollment{}, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	if err := s.users.UpdateUserProfile(ctx, user.ID, bson.M{"mfa_pending_secret": secret}); err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	return MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: util.TOTPURI(mfaIssuer(), user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFAEnrollment ativa o 2FA se o código corresponder ao segredo pendente
+// e retorna os códigos de recuperação, que só são exibidos uma vez.
+func (s *Service) ConfirmMFAEnrollment(ctx context.Context, user dao.UserDao, code string) ([]string, error) {
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFAPendingSecret == "" {
+		return nil, ErrMFANoPendingEnrollment
+	}
+
+	step, ok := util.ValidateTOTP(user.MFAPendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.users.UpdateUserProfile(ctx, user.ID, bson.M{
+		"mfa_enabled":        true,
+		"mfa_secret":         user.MFAPendingSecret,
+		"mfa_pending_secret": "",
+		"mfa_recovery_codes": hashes,
+		"mfa_last_step":      step,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Verificação em duas etapas ativada - UserID: %s", user.ID.Hex())
+	return codes, nil
+}
+
+// DisableMFA desativa o 2FA mediante um código TOTP ou de recuperação válido
+func (s *Service) DisableMFA(ctx context.Context, user dao.UserDao, code string) error {
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	if err := s.verifyMFACode(ctx, user, code); err != nil {
+		return err
+	}
+
+	err := s.users.UpdateUserProfile(ctx, user.ID, bson.M{
+		"mfa_enabled":        false,
+		"mfa_secret":         "",
+		"mfa_pending_secret": "",
+		"mfa_recovery_codes": []string{},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("⚠️  Verificação em duas etapas desativada - UserID: %s", user.ID.Hex())
+	return nil
+}
+
+// CompleteMFALogin troca o token "mfa pendente" e um código TOTP (ou de
+// recuperação) por um par de tokens normal. Falhas contam para o bloqueio de login.
+func (s *Service) CompleteMFALogin(ctx context.Context, mfaToken, code string, info SessionInfo) (TokenPair, error) {
+	claims, err := ParseMFAPendingToken(mfaToken)
+	if err != nil {
+		return TokenPair{}, ErrMFAPendingTokenInvalid
+	}
+
+	revoked, err := s.auth.RevokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if revoked {
+		return TokenPair{}, ErrMFAPendingTokenInvalid
+	}
+
+	user, err := s.users.FindUserByID(ctx, claims.UserID)
+	if err != nil || !user.MFAEnabled {
+		return TokenPair{}, ErrMFAPendingTokenInvalid
+	}
+
+	if err := s.CheckLoginAllowed(ctx, user.Email, info.IP); err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := s.verifyMFACode(ctx, user, code); err != nil {
+		if errors.Is(err, ErrMFAInvalidCode) {
+			s.RegisterFailedLogin(ctx, user.Email, info.IP)
+		}
+		return TokenPair{}, err
+	}
+
+	s.RegisterSuccessfulLogin(ctx, user.Email)
+
+	// O token pendente vale para uma única troca
+	if err := s.auth.RevokedTokens.RevokeToken(ctx, claims.ID, user.ID, claims.ExpiresAt.Time); err != nil {
+		log.Printf("⚠️  Erro ao revogar token de verificação em duas etapas: %v", err)
+	}
+
+	return s.IssueTokenPair(ctx, user, info)
+}
+
+// verifyMFACode aceita um código TOTP de 6 dígitos ou um código de recuperação
+func (s *Service) verifyMFACode(ctx context.Context, user dao.UserDao, code string) error {
+	code = strings.TrimSpace(code)
+
+	if step, ok := util.ValidateTOTP(user.MFASecret, code, time.Now()); ok {
+		recorded, err := s.users.RecordMFAStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			// Código já utilizado
+			return ErrMFAInvalidCode
+		}
+		return nil
+	}
+
+	consumed, err := s.users.ConsumeRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrMFAInvalidCode
+	}
+
+	log.Printf("⚠️  Código de recuperação utilizado - UserID: %s", user.ID.Hex())
+	return nil
+}
+
+// generateRecoveryCodes gera códigos no formato xxxxx-xxxxx e seus hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashOpaqueToken(normalized)
+}
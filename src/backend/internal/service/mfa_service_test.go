@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totpAt calcula o código que o aplicativo autenticador exibiria no instante informado
+func totpAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func TestMFACodesAreSingleUse(t *testing.T) {
+	useTestConfig(t, "")
+
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	services := service.New(storage)
+	users := storage.Repositories().Users
+	info := service.SessionInfo{IP: "10.0.0.1"}
+
+	user, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enrollment, err := services.StartMFAEnrollment(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err = users.FindUserByID(ctx, user.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	enrollmentCode := totpAt(t, enrollment.Secret, time.Now())
+	recoveryCodes, err := services.ConfirmMFAEnrollment(ctx, user, enrollmentCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(code string) error {
+		t.Helper()
+		user, err := users.FindUserByID(ctx, user.ID.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mfaToken, err := service.GenerateMFAPendingToken(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = services.CompleteMFALogin(ctx, mfaToken, code, info)
+		return err
+	}
+
+	t.Run("código TOTP não pode ser reutilizado", func(t *testing.T) {
+		if err := login(enrollmentCode); !errors.Is(err, service.ErrMFAInvalidCode) {
+			t.Fatalf("o código usado no cadastro deve ser recusado, retornou %v", err)
+		}
+
+		// O passo seguinte ainda está dentro da tolerância e vale uma única vez
+		next := totpAt(t, enrollment.Secret, time.Now().Add(30*time.Second))
+		if err := login(next); err != nil {
+			t.Fatalf("código do passo seguinte recusado: %v", err)
+		}
+		if err := login(next); !errors.Is(err, service.ErrMFAInvalidCode) {
+			t.Fatalf("código reutilizado deve ser recusado, retornou %v", err)
+		}
+	})
+
+	t.Run("código de recuperação vale uma vez", func(t *testing.T) {
+		if err := login(recoveryCodes[0]); err != nil {
+			t.Fatalf("código de recuperação recusado: %v", err)
+		}
+		if err := login(recoveryCodes[0]); !errors.Is(err, service.ErrMFAInvalidCode) {
+			t.Fatalf("código de recuperação reutilizado deve ser recusado, retornou %v", err)
+		}
+
+		// O hífen e as maiúsculas não fazem parte do código
+		if err := login(strings.ToUpper(strings.ReplaceAll(recoveryCodes[1], "-", ""))); err != nil {
+			t.Fatalf("código de recuperação normalizado recusado: %v", err)
+		}
+	})
+
+	t.Run("códigos de recuperação são guardados só como hash", func(t *testing.T) {
+		saved, err := users.FindUserByID(ctx, user.ID.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(saved.MFARecoveryCodes) != len(recoveryCodes)-2 {
+			t.Fatalf("esperava %d códigos restantes, há %d", len(recoveryCodes)-2, len(saved.MFARecoveryCodes))
+		}
+		for _, code := range recoveryCodes {
+			if slices.Contains(saved.MFARecoveryCodes, code) {
+				t.Fatalf("código de recuperação %s gravado em texto puro", code)
+			}
+		}
+	})
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"metabee/internal/config"
+	"metabee/internal/model/dao"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OIDCProviderGoogle é o único provedor configurado por enquanto
+const OIDCProviderGoogle = "google"
+
+const (
+	oidcStateTTL        = 10 * time.Minute
+	oidcJWKSMinRefresh  = time.Minute
+	defaultGoogleIssuer = "https://accounts.google.com"
+)
+
+var (
+	ErrOIDCNotConfigured       = errors.New("login com Google não está configurado")
+	ErrOIDCRedirectURIInvalid  = errors.New("redirect_uri não permitida")
+	ErrOIDCStateInvalid        = errors.New("login com Google expirado ou inválido, tente novamente")
+	ErrOIDCEmailNotVerified    = errors.New("o email da conta Google não está verificado")
+	ErrOIDCAccountNotVerified  = errors.New("já existe uma conta com este email; confirme o email dela antes de entrar com Google")
+	ErrOIDCSignupDisabled      = errors.New("nenhuma conta cadastrada com este email")
+	ErrOIDCAccountLinkConflict = errors.New("esta conta já está vinculada a outra conta Google")
+)
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// OIDCAuthorization é devolvida ao cliente para abrir o navegador do sistema
+type OIDCAuthorization struct {
+	AuthorizationURL string
+	State            string
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcIDTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+var (
+	oidcCacheMu       sync.Mutex
+	oidcDiscoveryDoc  *oidcDiscovery
+	oidcProviderKeys  map[string]*rsa.PublicKey
+	oidcKeysFetchedAt time.Time
+)
+
+func googleIssuer() string {
+	if config.Current().OIDC.GoogleIssuer != "" {
+		return strings.TrimSuffix(config.Current().OIDC.GoogleIssuer, "/")
+	}
+	return defaultGoogleIssuer
+}
+
+// StartOIDCLogin inicia o fluxo authorization code + PKCE. O cliente desktop abre
+// a URL no navegador e recebe o code no redirect de loopback (RFC 8252).
+func (s *Service) StartOIDCLogin(ctx context.Context, redirectURI string) (OIDCAuthorization, error) {
+	if config.Current().OIDC.GoogleClientID == "" {
+		return OIDCAuthorization{}, ErrOIDCNotConfigured
+	}
+	if !isAllowedRedirectURI(redirectURI) {
+		return OIDCAuthorization{}, ErrOIDCRedirectURIInvalid
+	}
+
+	discovery, err := fetchOIDCDiscovery()
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+
+	state, err := generateOpaqueToken()
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+	nonce, err := generateOpaqueToken()
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+	codeVerifier, err := generateOpaqueToken()
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+
+	err = s.auth.OIDCStates.CreateOIDCState(ctx, dao.OIDCStateDao{
+		StateHash:    hashOpaqueToken(state),
+		Provider:     OIDCProviderGoogle,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		RedirectURI:  redirectURI,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return OIDCAuthorization{}, err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", config.Current().OIDC.GoogleClientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", "openid email profile")
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+	params.Set("prompt", "select_account")
+
+	return OIDCAuthorization{
+		AuthorizationURL: discovery.AuthorizationEndpoint + "?" + params.Encode(),
+		State:            state,
+	}, nil
+}
+
+// CompleteOIDCLogin troca o code pelo ID token, valida-o e retorna o usuário
+// correspondente, vinculando ou criando a conta quando necessário.
+func (s *Service) CompleteOIDCLogin(ctx context.Context, state, code string) (dao.UserDao, error) {
+	if config.Current().OIDC.GoogleClientID == "" {
+		return dao.UserDao{}, ErrOIDCNotConfigured
+	}
+
+	stored, err := s.auth.OIDCStates.ConsumeByHash(ctx, hashOpaqueToken(state))
+	if err != nil || stored.ExpiresAt.Before(time.Now()) {
+		return dao.UserDao{}, ErrOIDCStateInvalid
+	}
+
+	rawIDToken, err := exchangeOIDCCode(code, stored)
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+
+	claims, err := verifyOIDCIDToken(rawIDToken, stored.Nonce)
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+
+	return s.linkOIDCAccount(ctx, claims)
+}
+
+func (s *Service) linkOIDCAccount(ctx context.Context, claims *oidcIDTokenClaims) (dao.UserDao, error) {
+
+	if user, err := s.users.FindUserByGoogleSubject(ctx, claims.Subject); err == nil {
+		return user, nil
+	}
+
+	if !claims.EmailVerified || claims.Email == "" {
+		return dao.UserDao{}, ErrOIDCEmailNotVerified
+	}
+	email := strings.TrimSpace(strings.ToLower(claims.Email))
+
+	user, err := s.users.FindUserByEmail(ctx, email)
+	if err == nil {
+		// Vincular uma conta local não verificada entregaria a conta a quem a cadastrou com o email alheio
+		if !user.IsEmailVerified() {
+			return dao.UserDao{}, ErrOIDCAccountNotVerified
+		}
+		if user.GoogleSubject != "" && user.GoogleSubject != claims.Subject {
+			return dao.UserDao{}, ErrOIDCAccountLinkConflict
+		}
+		if err := s.users.UpdateUserProfile(ctx, user.ID, bson.M{"google_sub": claims.Subject}); err != nil {
+			return dao.UserDao{}, err
+		}
+		user.GoogleSubject = claims.Subject
+		s.RecordAudit(ctx, user.ID, dao.AuditGoogleLinked, "", "", map[string]string{"email": email})
+		log.Printf("✅ Conta vinculada ao Google - UserID: %s", user.ID.Hex())
+		return user, nil
+	}
+
+	if !config.Current().OIDC.AllowSignup {
+		return dao.UserDao{}, ErrOIDCSignupDisabled
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	// Contas criadas pelo Google não têm senha; o usuário pode definir uma pela redefinição de senha
+	user, err = s.users.CreateUser(ctx, dao.UserDao{Name: name, Email: email})
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+
+	now := time.Now()
+	err = s.users.UpdateUserProfile(ctx, user.ID, bson.M{
+		"google_sub":        claims.Subject,
+		"email_unverified":  false,
+		"email_verified_at": now,
+	})
+	if err != nil {
+		return dao.UserDao{}, err
+	}
+	user.GoogleSubject = claims.Subject
+	user.EmailUnverified = false
+	user.EmailVerifiedAt = now
+
+	log.Printf("✅ Usuário criado via Google: %s (ID: %s)", user.Email, user.ID.Hex())
+	return user, nil
+}
+
+// isAllowedRedirectURI aceita as URIs configuradas. Para endereços de loopback
+// qualquer porta é aceita, já que o cliente desktop escolhe uma porta livre.
+func isAllowedRedirectURI(redirectURI string) bool {
+	candidate, err := url.Parse(redirectURI)
+	if err != nil || candidate.Scheme == "" || candidate.Host == "" {
+		return false
+	}
+
+	for _, allowed := range config.Current().OIDC.RedirectURIs {
+		if redirectURI == allowed {
+			return true
+		}
+
+		allowedURL, err := url.Parse(allowed)
+		if err != nil || !isLoopbackHost(allowedURL.Hostname()) {
+			continue
+		}
+		if candidate.Scheme == allowedURL.Scheme &&
+			candidate.Hostname() == allowedURL.Hostname() &&
+			candidate.Path == allowedURL.Path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func fetchOIDCDiscovery() (*oidcDiscovery, error) {
+	oidcCacheMu.Lock()
+	defer oidcCacheMu.Unlock()
+
+	if oidcDiscoveryDoc != nil {
+		return oidcDiscoveryDoc, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getOIDCJSON(googleIssuer()+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("erro ao obter configuração OIDC: %w", err)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("configuração OIDC incompleta")
+	}
+
+	oidcDiscoveryDoc = &discovery
+	return oidcDiscoveryDoc, nil
+}
+
+func exchangeOIDCCode(code string, stored dao.OIDCStateDao) (string, error) {
+	discovery, err := fetchOIDCDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", stored.RedirectURI)
+	form.Set("client_id", config.Current().OIDC.GoogleClientID)
+	form.Set("code_verifier", stored.CodeVerifier)
+	if config.Current().OIDC.GoogleClientSecret != "" {
+		form.Set("client_secret", config.Current().OIDC.GoogleClientSecret)
+	}
+
+	resp, err := oidcHTTPClient.PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		return "", fmt.Errorf("erro ao trocar code OIDC: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("❌ Provedor OIDC recusou o code (status %d): %s", resp.StatusCode, string(body))
+		return "", ErrOIDCStateInvalid
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil || tokenResponse.IDToken == "" {
+		return "", errors.New("resposta do provedor OIDC sem id_token")
+	}
+
+	return tokenResponse.IDToken, nil
+}
+
+func verifyOIDCIDToken(rawIDToken, expectedNonce string) (*oidcIDTokenClaims, error) {
+	discovery, err := fetchOIDCDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oidcIDTokenClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name}),
+		jwt.WithAudience(config.Current().OIDC.GoogleClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	_, err = parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return oidcProviderKey(discovery.JWKSURI, kid)
+	})
+	if err != nil {
+		log.Printf("❌ ID token OIDC inválido: %v", err)
+		return nil, ErrOIDCStateInvalid
+	}
+
+	// O Google emite tokens com e sem o esquema no issuer
+	issuer := strings.TrimPrefix(discovery.Issuer, "https://")
+	if strings.TrimPrefix(claims.Issuer, "https://") != issuer {
+		log.Printf("❌ Issuer inesperado no ID token: %s", claims.Issuer)
+		return nil, ErrOIDCStateInvalid
+	}
+	if claims.Nonce != expectedNonce || claims.Subject == "" {
+		log.Printf("❌ Nonce ou sub inválido no ID token")
+		return nil, ErrOIDCStateInvalid
+	}
+
+	return claims, nil
+}
+
+// oidcProviderKey retorna a chave pública do provedor, recarregando o JWKS quando
+// aparece um kid desconhecido (o Google rotaciona as chaves com frequência).
+func oidcProviderKey(jwksURI, kid string) (*rsa.PublicKey, error) {
+	oidcCacheMu.Lock()
+	defer oidcCacheMu.Unlock()
+
+	if key, found := oidcProviderKeys[kid]; found {
+		return key, nil
+	}
+	if time.Since(oidcKeysFetchedAt) < oidcJWKSMinRefresh {
+		return nil, fmt.Errorf("chave OIDC desconhecida: %s", kid)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getOIDCJSON(jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("erro ao obter JWKS OIDC: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	oidcProviderKeys = keys
+	oidcKeysFetchedAt = time.Now()
+
+	if key, found := oidcProviderKeys[kid]; found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("chave OIDC desconhecida: %s", kid)
+}
+
+func getOIDCJSON(endpoint string, target interface{}) error {
+	resp, err := oidcHTTPClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d em %s", resp.StatusCode, endpoint)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
@@ -0,0 +1,270 @@
+package service_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	oidcTestClientID    = "metabee-desktop"
+	oidcTestRedirectURI = "http://127.0.0.1:53682/callback"
+)
+
+// fakeOIDCProvider é um provedor OIDC local com discovery, JWKS e endpoint de
+// token. O teste registra, para cada code, as claims do ID token que ele devolve.
+type fakeOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]oidcGrant
+}
+
+type oidcGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func startFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &fakeOIDCProvider{key: key, grants: map[string]oidcGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 provider.server.URL,
+			"authorization_endpoint": provider.server.URL + "/authorize",
+			"token_endpoint":         provider.server.URL + "/token",
+			"jwks_uri":               provider.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "chave-teste",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", provider.token)
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+// token troca o code pelo ID token, conferindo o PKCE como o Google faz
+func (p *fakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "formulário inválido", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	grant, found := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !found || r.PostForm.Get("client_id") != oidcTestClientID ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, grant.claims)
+	idToken.Header["kid"] = "chave-teste"
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+}
+
+// authorize faz o papel do navegador: lê o pedido de autorização gerado pelo
+// serviço e registra um code cujo ID token recebe as claims de edit
+func (p *fakeOIDCProvider) authorize(t *testing.T, authorizationURL, subject, email string, emailVerified bool, edit func(jwt.MapClaims)) string {
+	t.Helper()
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := parsed.Query()
+
+	claims := jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            oidcTestClientID,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": emailVerified,
+		"name":           "Ana Google",
+		"nonce":          params.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	if edit != nil {
+		edit(claims)
+	}
+
+	code := "code-" + subject + "-" + params.Get("state")[:8]
+	p.mu.Lock()
+	p.grants[code] = oidcGrant{challenge: params.Get("code_challenge"), claims: claims}
+	p.mu.Unlock()
+	return code
+}
+
+// useOIDCConfig carrega uma configuração com o login com Google apontando para o provedor falso
+func useOIDCConfig(t *testing.T, provider *fakeOIDCProvider) {
+	t.Helper()
+	useTestConfig(t, fmt.Sprintf(`
+[oidc]
+googleClientID = %q
+googleIssuer = %q
+redirectURIs = ["http://127.0.0.1/callback"]
+allowSignup = true
+`, oidcTestClientID, provider.server.URL))
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	// Discovery e JWKS ficam em cache no pacote: um único provedor serve todos os casos
+	provider := startFakeOIDCProvider(t)
+	useOIDCConfig(t, provider)
+
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	services := service.New(storage)
+	users := storage.Repositories().Users
+
+	login := func(t *testing.T, subject, email string, emailVerified bool, edit func(jwt.MapClaims)) (dao.UserDao, error) {
+		t.Helper()
+		authorization, err := services.StartOIDCLogin(ctx, oidcTestRedirectURI)
+		if err != nil {
+			t.Fatalf("StartOIDCLogin: %v", err)
+		}
+		code := provider.authorize(t, authorization.AuthorizationURL, subject, email, emailVerified, edit)
+		return services.CompleteOIDCLogin(ctx, authorization.State, code)
+	}
+
+	t.Run("cria a conta no primeiro login", func(t *testing.T) {
+		user, err := login(t, "sub-novo", "Nova@Metabee.test", true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Email != "nova@metabee.test" || user.GoogleSubject != "sub-novo" || !user.IsEmailVerified() {
+			t.Fatalf("conta criada com dados errados: %+v", user)
+		}
+
+		again, err := login(t, "sub-novo", "nova@metabee.test", true, nil)
+		if err != nil || again.ID != user.ID {
+			t.Fatalf("o segundo login deve encontrar a mesma conta pelo sub: %v %+v", err, again)
+		}
+	})
+
+	t.Run("vincula a conta local com email verificado", func(t *testing.T) {
+		local, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = users.UpdateUserProfile(ctx, local.ID, bson.M{"email_unverified": false, "email_verified_at": time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := login(t, "sub-ana", "ana@metabee.test", true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != local.ID {
+			t.Fatalf("o login deve usar a conta local %s, usou %s", local.ID.Hex(), user.ID.Hex())
+		}
+		saved, err := users.FindUserByGoogleSubject(ctx, "sub-ana")
+		if err != nil || saved.ID != local.ID {
+			t.Fatalf("o sub do Google não foi gravado na conta local: %v", err)
+		}
+	})
+
+	t.Run("recusa vincular conta local não verificada", func(t *testing.T) {
+		// Contas novas começam com o email não verificado
+		if _, err := users.CreateUser(ctx, dao.UserDao{Name: "Bia", Email: "bia@metabee.test", Password: "hash"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := login(t, "sub-bia", "bia@metabee.test", true, nil); !errors.Is(err, service.ErrOIDCAccountNotVerified) {
+			t.Fatalf("esperava ErrOIDCAccountNotVerified, retornou %v", err)
+		}
+		if _, err := users.FindUserByGoogleSubject(ctx, "sub-bia"); err == nil {
+			t.Fatal("a conta não verificada não pode ser vinculada")
+		}
+	})
+
+	t.Run("recusa email do Google não verificado", func(t *testing.T) {
+		if _, err := login(t, "sub-sem-verificacao", "carla@metabee.test", false, nil); !errors.Is(err, service.ErrOIDCEmailNotVerified) {
+			t.Fatalf("esperava ErrOIDCEmailNotVerified, retornou %v", err)
+		}
+	})
+
+	t.Run("recusa nonce diferente", func(t *testing.T) {
+		_, err := login(t, "sub-nonce", "dani@metabee.test", true, func(claims jwt.MapClaims) {
+			claims["nonce"] = "nonce-de-outro-login"
+		})
+		if !errors.Is(err, service.ErrOIDCStateInvalid) {
+			t.Fatalf("esperava ErrOIDCStateInvalid, retornou %v", err)
+		}
+	})
+
+	t.Run("recusa token para outro cliente", func(t *testing.T) {
+		_, err := login(t, "sub-aud", "edu@metabee.test", true, func(claims jwt.MapClaims) {
+			claims["aud"] = "outro-cliente"
+		})
+		if !errors.Is(err, service.ErrOIDCStateInvalid) {
+			t.Fatalf("esperava ErrOIDCStateInvalid, retornou %v", err)
+		}
+	})
+
+	t.Run("recusa issuer diferente", func(t *testing.T) {
+		_, err := login(t, "sub-iss", "fabi@metabee.test", true, func(claims jwt.MapClaims) {
+			claims["iss"] = "https://provedor-falso.test"
+		})
+		if !errors.Is(err, service.ErrOIDCStateInvalid) {
+			t.Fatalf("esperava ErrOIDCStateInvalid, retornou %v", err)
+		}
+	})
+
+	t.Run("state só vale uma vez", func(t *testing.T) {
+		authorization, err := services.StartOIDCLogin(ctx, oidcTestRedirectURI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code := provider.authorize(t, authorization.AuthorizationURL, "sub-state", "gabi@metabee.test", true, nil)
+		if _, err := services.CompleteOIDCLogin(ctx, authorization.State, code); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := services.CompleteOIDCLogin(ctx, authorization.State, code); !errors.Is(err, service.ErrOIDCStateInvalid) {
+			t.Fatalf("o state reutilizado deve ser recusado, retornou %v", err)
+		}
+	})
+}
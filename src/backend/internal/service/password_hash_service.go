@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/model/dao"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algoritmos aceitos em [password].hashAlgorithm
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// argon2Params são os parâmetros codificados no hash no formato PHC:
+// $argon2id$v=19$m=<memória KiB>,t=<iterações>,p=<paralelismo>$<salt>$<hash>
+type argon2Params struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+func passwordHashAlgorithm() string {
+	if strings.ToLower(config.Current().Password.HashAlgorithm) == PasswordHashArgon2id {
+		return PasswordHashArgon2id
+	}
+	return PasswordHashBcrypt
+}
+
+func bcryptCost() int {
+	cost := config.Current().Password.BcryptCost
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return 12
+	}
+	return cost
+}
+
+func configuredArgon2Params() argon2Params {
+	params := argon2Params{Memory: 64 * 1024, Time: 3, Threads: 2}
+	if config.Current().Password.Argon2MemoryKiB > 0 {
+		params.Memory = uint32(config.Current().Password.Argon2MemoryKiB)
+	}
+	if config.Current().Password.Argon2Iterations > 0 {
+		params.Time = uint32(config.Current().Password.Argon2Iterations)
+	}
+	if config.Current().Password.Argon2Threads > 0 {
+		params.Threads = uint8(config.Current().Password.Argon2Threads)
+	}
+	return params
+}
+
+// HashPassword gera o hash da senha com o algoritmo e os parâmetros configurados
+func HashPassword(password string) (string, error) {
+	if passwordHashAlgorithm() == PasswordHashArgon2id {
+		return hashArgon2id(password, configuredArgon2Params())
+	}
+
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost())
+	return string(bytes), err
+}
+
+// VerifyPassword compara a senha com o hash armazenado (bcrypt ou argon2id).
+// needsRehash indica que o hash confere mas foi gerado com outro algoritmo ou
+// com parâmetros mais fracos que os atuais.
+func VerifyPassword(password, hash string) (ok bool, needsRehash bool) {
+	if hash == "" {
+		return false, false
+	}
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			log.Printf("❌ Hash argon2id inválido: %v", err)
+			return false, false
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return false, false
+		}
+		return true, passwordHashAlgorithm() != PasswordHashArgon2id || params != configuredArgon2Params()
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			log.Printf("❌ Erro ao comparar senha: %v", err)
+		}
+		return false, false
+	}
+
+	if passwordHashAlgorithm() != PasswordHashBcrypt {
+		return true, true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, err == nil && cost < bcryptCost()
+}
+
+// RehashPasswordIfNeeded atualiza o hash armazenado após um login bem-sucedido.
+// Falhas apenas são registradas: o login não deve depender disso.
+func (s *Service) RehashPasswordIfNeeded(ctx context.Context, user dao.UserDao, password string, needsRehash bool) {
+	if !needsRehash {
+		return
+	}
+
+	newHash, err := HashPassword(password)
+	if err != nil {
+		log.Printf("⚠️  Erro ao gerar novo hash de senha para %s: %v", user.ID.Hex(), err)
+		return
+	}
+
+	updated, err := s.users.ReplacePasswordHash(ctx, user.ID, user.Password, newHash)
+	if err != nil {
+		log.Printf("⚠️  Erro ao atualizar hash de senha para %s: %v", user.ID.Hex(), err)
+		return
+	}
+	if updated {
+		log.Printf("🔐 Hash de senha atualizado para os parâmetros atuais - UserID: %s", user.ID.Hex())
+	}
+}
+
+func hashArgon2id(password string, params argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return argon2Params{}, nil, nil, errors.New("formato inválido")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+	if version != argon2.Version {
+		return argon2Params{}, nil, nil, fmt.Errorf("versão argon2 não suportada: %d", version)
+	}
+
+	var params argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"fmt"
+	"metabee/internal/config"
+	"metabee/internal/service"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestConfig carrega um metabee.conf temporário com as seções informadas e
+// volta à configuração base ao fim do teste
+func useTestConfig(t *testing.T, sections string) {
+	t.Helper()
+	dir := t.TempDir()
+	base := fmt.Sprintf(`
+[service]
+n8nWebhookURL = "http://127.0.0.1:5678/webhook"
+
+[database]
+backend = "embedded"
+embeddedPath = %q
+
+[jwt]
+jwtsecret = "segredo-de-teste-com-mais-de-32-caracteres"
+`, filepath.Join(dir, "metabee.db"))
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	basePath := write("base.conf", base)
+	config.Load(write("metabee.conf", base+sections))
+	t.Cleanup(func() { config.Load(basePath) })
+}
+
+func TestVerifyPasswordRehashDecision(t *testing.T) {
+	hashWith := func(t *testing.T, sections string) string {
+		t.Helper()
+		useTestConfig(t, sections)
+		hash, err := service.HashPassword("senha-correta")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	const (
+		bcryptCost4  = "[password]\nhashAlgorithm = \"bcrypt\"\nbcryptCost = 4\n"
+		bcryptCost5  = "[password]\nhashAlgorithm = \"bcrypt\"\nbcryptCost = 5\n"
+		argon2Small  = "[password]\nhashAlgorithm = \"argon2id\"\nargon2MemoryKiB = 1024\nargon2Iterations = 1\nargon2Threads = 1\n"
+		argon2Bigger = "[password]\nhashAlgorithm = \"argon2id\"\nargon2MemoryKiB = 2048\nargon2Iterations = 1\nargon2Threads = 1\n"
+	)
+
+	bcryptHash := hashWith(t, bcryptCost4)
+	if !strings.HasPrefix(bcryptHash, "$2a$04$") {
+		t.Fatalf("hash bcrypt com o custo errado: %s", bcryptHash)
+	}
+	argon2Hash := hashWith(t, argon2Small)
+	if !strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash argon2id com os parâmetros errados: %s", argon2Hash)
+	}
+
+	cases := []struct {
+		name        string
+		config      string
+		hash        string
+		password    string
+		ok          bool
+		needsRehash bool
+	}{
+		{"bcrypt com o custo atual", bcryptCost4, bcryptHash, "senha-correta", true, false},
+		{"bcrypt com custo menor que o atual", bcryptCost5, bcryptHash, "senha-correta", true, true},
+		{"bcrypt com a senha errada", bcryptCost5, bcryptHash, "senha-errada", false, false},
+		{"bcrypt depois da troca para argon2id", argon2Small, bcryptHash, "senha-correta", true, true},
+		{"argon2id com os parâmetros atuais", argon2Small, argon2Hash, "senha-correta", true, false},
+		{"argon2id com parâmetros diferentes", argon2Bigger, argon2Hash, "senha-correta", true, true},
+		{"argon2id com a senha errada", argon2Small, argon2Hash, "senha-errada", false, false},
+		{"argon2id depois da volta para bcrypt", bcryptCost4, argon2Hash, "senha-correta", true, true},
+		{"argon2id malformado", argon2Small, "$argon2id$v=19$m=1024", "senha-correta", false, false},
+		{"conta sem senha", bcryptCost4, "", "", false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			useTestConfig(t, tc.config)
+			ok, needsRehash := service.VerifyPassword(tc.password, tc.hash)
+			if ok != tc.ok || needsRehash != tc.needsRehash {
+				t.Fatalf("ok=%v needsRehash=%v, esperava ok=%v needsRehash=%v", ok, needsRehash, tc.ok, tc.needsRehash)
+			}
+		})
+	}
+}
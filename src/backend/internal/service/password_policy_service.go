@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"metabee/internal/config"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Códigos das violações da política de senha, usados pelo cliente para exibir a mensagem no campo
+const (
+	PasswordTooShort       = "too_short"
+	PasswordTooLong        = "too_long"
+	PasswordMissingUpper   = "missing_uppercase"
+	PasswordMissingLower   = "missing_lowercase"
+	PasswordMissingDigit   = "missing_digit"
+	PasswordMissingSymbol  = "missing_symbol"
+	PasswordTooCommon      = "too_common"
+	passwordBcryptMaxBytes = 72 // bcrypt recusa senhas maiores que 72 bytes
+)
+
+//go:embed data/common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// PasswordPolicy descreve os requisitos atuais, exibidos pelo cliente no formulário
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	MaxLength        int  `json:"max_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+}
+
+// PasswordViolation é um requisito da política que a senha não atende
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lista todas as violações encontradas, não apenas a primeira
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, " ")
+}
+
+// CurrentPasswordPolicy retorna a política configurada em [password] com os valores padrão aplicados
+func CurrentPasswordPolicy() PasswordPolicy {
+	passwordConf := config.Current().Password
+
+	policy := PasswordPolicy{
+		MinLength:        passwordConf.MinLength,
+		MaxLength:        passwordConf.MaxLength,
+		RequireUppercase: passwordConf.RequireUppercase,
+		RequireLowercase: passwordConf.RequireLowercase,
+		RequireDigit:     passwordConf.RequireDigit,
+		RequireSymbol:    passwordConf.RequireSymbol,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = 8
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = 128
+	}
+	if passwordHashAlgorithm() == PasswordHashBcrypt && policy.MaxLength > passwordBcryptMaxBytes {
+		policy.MaxLength = passwordBcryptMaxBytes
+	}
+	return policy
+}
+
+// ValidatePassword verifica a senha contra a política configurada e a lista de
+// senhas comuns. Retorna *PasswordPolicyError com todas as violações encontradas.
+func ValidatePassword(password string) error {
+	policy := CurrentPasswordPolicy()
+	var violations []PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooShort,
+			Message: fmt.Sprintf("A senha deve ter pelo menos %d caracteres.", policy.MinLength),
+		})
+	}
+	if length > policy.MaxLength || (passwordHashAlgorithm() == PasswordHashBcrypt && len(password) > passwordBcryptMaxBytes) {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooLong,
+			Message: fmt.Sprintf("A senha deve ter no máximo %d caracteres.", policy.MaxLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !hasUpper {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingUpper, Message: "A senha deve conter uma letra maiúscula."})
+	}
+	if policy.RequireLowercase && !hasLower {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingLower, Message: "A senha deve conter uma letra minúscula."})
+	}
+	if policy.RequireDigit && !hasDigit {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingDigit, Message: "A senha deve conter um número."})
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, PasswordViolation{Code: PasswordMissingSymbol, Message: "A senha deve conter um símbolo."})
+	}
+
+	if isCommonPassword(password) {
+		violations = append(violations, PasswordViolation{
+			Code:    PasswordTooCommon,
+			Message: "Esta senha é muito comum ou já apareceu em vazamentos. Escolha outra.",
+		})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+		scanner := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			commonPasswords[strings.ToLower(line)] = struct{}{}
+		}
+	})
+
+	_, found := commonPasswords[strings.ToLower(strings.TrimSpace(password))]
+	return found
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"errors"
+	"metabee/internal/service"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// violationCodes devolve os códigos das violações, ou nil quando a senha é aceita
+func violationCodes(t *testing.T, password string) []string {
+	t.Helper()
+	err := service.ValidatePassword(password)
+	if err == nil {
+		return nil
+	}
+	var policyErr *service.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	codes := make([]string, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func TestValidatePassword(t *testing.T) {
+	useTestConfig(t, `
+[password]
+minLength = 10
+requireUppercase = true
+requireLowercase = true
+requireDigit = true
+requireSymbol = true
+`)
+
+	cases := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"atende a política", "Abelha-Rainha7", nil},
+		{"acentos contam como letras", "Ação-Diária9", nil},
+		{"todas as violações de uma vez", "abc", []string{service.PasswordTooShort, service.PasswordMissingUpper, service.PasswordMissingDigit, service.PasswordMissingSymbol}},
+		{"espaço conta como símbolo", "Abelha Rainha7", nil},
+		{"sem minúscula", "ABELHA-RAINHA7", []string{service.PasswordMissingLower}},
+		{"comum mesmo com outra caixa", "Password123", []string{service.PasswordMissingSymbol, service.PasswordTooCommon}},
+		// O tamanho é contado em caracteres, não em bytes
+		{"curta com caracteres multibyte", "Çãéíõ-ú1a", []string{service.PasswordTooShort}},
+		// Com bcrypt o limite é de 72 bytes
+		{"longa demais para o bcrypt", "Ab1-" + strings.Repeat("x", 69), []string{service.PasswordTooLong}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := violationCodes(t, tc.password); !slices.Equal(got, tc.want) {
+				t.Fatalf("violações %v, esperava %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyDefaults(t *testing.T) {
+	useTestConfig(t, "")
+
+	policy := service.CurrentPasswordPolicy()
+	if policy.MinLength != 8 || policy.MaxLength != 72 || policy.RequireUppercase || policy.RequireSymbol {
+		t.Fatalf("padrões inesperados com bcrypt: %+v", policy)
+	}
+	if got := violationCodes(t, "abelhas melíferas"); got != nil {
+		t.Fatalf("sem requisitos de composição a senha deve ser aceita, violações %v", got)
+	}
+	if got := violationCodes(t, "senha123"); !slices.Equal(got, []string{service.PasswordTooCommon}) {
+		t.Fatalf("a lista de senhas comuns vale mesmo sem requisitos, violações %v", got)
+	}
+
+	useTestConfig(t, "[password]\nhashAlgorithm = \"argon2id\"\n")
+	if policy := service.CurrentPasswordPolicy(); policy.MaxLength != 128 {
+		t.Fatalf("com argon2id o limite padrão é 128, veio %d", policy.MaxLength)
+	}
+	if got := violationCodes(t, strings.Repeat("abelha ", 12)); got != nil {
+		t.Fatalf("com argon2id senhas acima de 72 bytes são aceitas, violações %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/mailer"
+	"metabee/internal/model/dao"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrPasswordResetInvalid = errors.New("token de redefinição inválido")
+	ErrPasswordResetExpired = errors.New("token de redefinição expirado")
+)
+
+// PasswordResetTTL retorna a validade configurada para links de redefinição (padrão: 30 minutos)
+func PasswordResetTTL() time.Duration {
+	if config.Current().Mail.PasswordResetTTLMinutes > 0 {
+		return time.Duration(config.Current().Mail.PasswordResetTTLMinutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// RequestPasswordReset gera um token de uso único e o envia para o email do
+// usuário. Se o email não existir nada é enviado, mas nenhum erro é retornado
+// para não revelar quais emails estão cadastrados.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.users.FindUserByEmail(ctx, email)
+	if err != nil {
+		log.Printf("⚠️  Redefinição de senha solicitada para email não cadastrado: %s", email)
+		return nil
+	}
+
+	if err := s.auth.PasswordResets.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	rawToken, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := PasswordResetTTL()
+	_, err = s.auth.PasswordResets.CreatePasswordReset(ctx, dao.PasswordResetDao{
+		UserID:    user.ID,
+		TokenHash: hashOpaqueToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Olá, %s!\n\n"+
+		"Recebemos um pedido para redefinir a senha da sua conta Metabee.\n\n"+
+		"%s\n\n"+
+		"O link expira em %d minutos e só pode ser usado uma vez.\n"+
+		"Se você não fez este pedido, ignore este email.\n",
+		user.Name, passwordResetLink(rawToken), int(ttl.Minutes()))
+
+	return mailer.Default().Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha - Metabee",
+		Body:    body,
+	})
+}
+
+// ResetPassword troca a senha do usuário dono do token e encerra todas as suas sessões
+func (s *Service) ResetPassword(ctx context.Context, rawToken, newPassword string) error {
+	if rawToken == "" {
+		return ErrPasswordResetInvalid
+	}
+
+	// Valida antes de consumir o token, para que o usuário possa tentar outra senha
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	reset, err := s.auth.PasswordResets.FindByHash(ctx, hashOpaqueToken(rawToken))
+	if err != nil || !reset.UsedAt.IsZero() {
+		return ErrPasswordResetInvalid
+	}
+
+	if reset.ExpiresAt.Before(time.Now()) {
+		return ErrPasswordResetExpired
+	}
+
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Consome o token antes de trocar a senha: só um pedido com o mesmo token passa daqui
+	if err := s.auth.PasswordResets.MarkUsed(ctx, reset.ID); err != nil {
+		if errors.Is(err, dao.ErrPasswordResetAlreadyUsed) {
+			return ErrPasswordResetInvalid
+		}
+		return err
+	}
+
+	if err := s.users.UpdateUserProfile(ctx, reset.UserID, bson.M{"password": hashedPassword}); err != nil {
+		// A senha não mudou: devolve o token para que o usuário tente de novo com o mesmo link
+		if releaseErr := s.auth.PasswordResets.Release(context.WithoutCancel(ctx), reset.ID); releaseErr != nil {
+			log.Printf("❌ Erro ao liberar token de redefinição - UserID: %s: %v", reset.UserID.Hex(), releaseErr)
+		}
+		return err
+	}
+
+	s.RecordAudit(ctx, reset.UserID, dao.AuditPasswordReset, "", "", nil)
+	log.Printf("✅ Senha redefinida via email - UserID: %s", reset.UserID.Hex())
+	return s.RevokeAllSessions(ctx, reset.UserID)
+}
+
+func passwordResetLink(rawToken string) string {
+	baseURL := config.Current().Mail.PasswordResetURL
+	if baseURL == "" {
+		return "Código de redefinição: " + rawToken
+	}
+	return "Acesse o link para criar uma nova senha: " + baseURL + "?token=" + url.QueryEscape(rawToken)
+}
@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"metabee/internal/mailer"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+	"metabee/internal/repository/embedded"
+	"metabee/internal/service"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// captureMailer guarda o último email em vez de enviá-lo
+type captureMailer struct {
+	last mailer.Message
+}
+
+func (m *captureMailer) Send(msg mailer.Message) error {
+	m.last = msg
+	return nil
+}
+
+// flakyUsers falha as atualizações de perfil enquanto fail for true
+type flakyUsers struct {
+	repository.UserRepository
+	fail bool
+}
+
+func (u *flakyUsers) UpdateUserProfile(ctx context.Context, userID bson.ObjectID, updates bson.M) error {
+	if u.fail {
+		return errors.New("banco indisponível")
+	}
+	return u.UserRepository.UpdateUserProfile(ctx, userID, updates)
+}
+
+type flakyUsersStorage struct {
+	repository.Storage
+	users *flakyUsers
+}
+
+func (s flakyUsersStorage) Repositories() repository.Repositories {
+	repos := s.Storage.Repositories()
+	repos.Users = s.users
+	return repos
+}
+
+// openTestStorage abre o backend embedded em um arquivo novo de t.TempDir()
+func openTestStorage(t *testing.T) *embedded.Storage {
+	t.Helper()
+	storage, err := embedded.Open(filepath.Join(t.TempDir(), "metabee.db"))
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco local: %v", err)
+	}
+	t.Cleanup(func() { storage.Close(context.Background()) })
+	return storage
+}
+
+var resetCodePattern = regexp.MustCompile(`Código de redefinição: (\S+)`)
+
+// Se a troca da senha falhar, o token não pode ser consumido: o usuário tenta de novo com o mesmo link
+func TestResetPasswordKeepsTokenWhenUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	users := &flakyUsers{UserRepository: storage.Repositories().Users}
+	services := service.New(flakyUsersStorage{Storage: storage, users: users})
+
+	mail := &captureMailer{}
+	mailer.SetDefault(mail)
+	t.Cleanup(func() { mailer.SetDefault(nil) })
+
+	user, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash-antigo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := services.RequestPasswordReset(ctx, user.Email); err != nil {
+		t.Fatal(err)
+	}
+	match := resetCodePattern.FindStringSubmatch(mail.last.Body)
+	if match == nil {
+		t.Fatalf("o email não tem o código de redefinição: %q", mail.last.Body)
+	}
+
+	users.fail = true
+	if err := services.ResetPassword(ctx, match[1], "SenhaNova456"); err == nil {
+		t.Fatal("ResetPassword deve repassar a falha da troca de senha")
+	}
+
+	users.fail = false
+	if err := services.ResetPassword(ctx, match[1], "SenhaNova456"); err != nil {
+		t.Fatalf("o token deve continuar valendo depois da falha: %v", err)
+	}
+	if err := services.ResetPassword(ctx, match[1], "OutraSenha789"); !errors.Is(err, service.ErrPasswordResetInvalid) {
+		t.Fatalf("o token deve ser consumido depois da troca, retornou %v", err)
+	}
+}
+
+// Pedidos simultâneos com o mesmo token: só um troca a senha
+func TestResetPasswordTokenIsSingleUseUnderConcurrency(t *testing.T) {
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	services := service.New(storage)
+	users := storage.Repositories().Users
+
+	mail := &captureMailer{}
+	mailer.SetDefault(mail)
+	t.Cleanup(func() { mailer.SetDefault(nil) })
+
+	user, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash-antigo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := services.RequestPasswordReset(ctx, user.Email); err != nil {
+		t.Fatal(err)
+	}
+	match := resetCodePattern.FindStringSubmatch(mail.last.Body)
+	if match == nil {
+		t.Fatalf("o email não tem o código de redefinição: %q", mail.last.Body)
+	}
+
+	const requests = 5
+	results := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results <- services.ResetPassword(ctx, match[1], fmt.Sprintf("SenhaNova%d56", i))
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, service.ErrPasswordResetInvalid):
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d pedidos trocaram a senha com o mesmo token", succeeded)
+	}
+}
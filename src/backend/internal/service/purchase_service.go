@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"metabee/internal/model/dao"
+	"metabee/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PurchaseCourse matricula o usuário no curso: grava a compra, o progresso
+// zerado e o lançamento no ledger em uma única unidade de trabalho. Se o curso
+// já foi comprado, retorna a compra existente com created false e completa o
+// que faltar (ex.: uma compra anterior a este fluxo sem progresso nem lançamento).
+func (s *Service) PurchaseCourse(ctx context.Context, userID bson.ObjectID, course dao.CourseDao) (dao.PurchaseDao, bool, error) {
+	var purchase dao.PurchaseDao
+	var created bool
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context, tx repository.TxRepositories) error {
+		// A unidade pode ser repetida: nada do que foi lido antes vale
+		purchase, created = dao.PurchaseDao{}, false
+
+		existing, err := tx.Purchases.GetPurchaseByUserAndCourse(ctx, userID, course.ID)
+		switch {
+		case err == nil:
+			purchase = existing
+		case errors.Is(err, mongo.ErrNoDocuments):
+			purchase, err = tx.Purchases.CreatePurchase(ctx, userID, course.ID, course.DriveLink)
+			if err != nil {
+				return err
+			}
+			created = true
+		default:
+			return err
+		}
+
+		if _, err := tx.Progress.EnsureProgress(ctx, userID, course.ID); err != nil {
+			return err
+		}
+
+		// Busca antes de inserir: no MongoDB, um erro de chave duplicada aborta a transação
+		_, err = tx.Ledger.FindByPurchase(ctx, purchase.ID, dao.LedgerEntryPurchase)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		_, err = tx.Ledger.RecordEntry(ctx, dao.LedgerEntryDao{
+			UserID:     userID,
+			CourseID:   course.ID,
+			PurchaseID: purchase.ID,
+			Type:       dao.LedgerEntryPurchase,
+			Amount:     course.Price,
+		})
+		if errors.Is(err, dao.ErrLedgerEntryExists) {
+			return nil
+		}
+		return err
+	})
+
+	// Compra concorrente do mesmo curso: o índice único barrou esta, e a outra já gravou tudo
+	if errors.Is(err, dao.ErrPurchaseExists) {
+		existing, err := s.purchases.GetPurchaseByUserAndCourse(ctx, userID, course.ID)
+		if err != nil {
+			return dao.PurchaseDao{}, false, err
+		}
+		return existing, false, nil
+	}
+	if err != nil {
+		return dao.PurchaseDao{}, false, err
+	}
+
+	if created {
+		log.Printf("✅ Compra registrada - UserID: %s, Curso: %s", userID.Hex(), course.ID.Hex())
+	}
+	return purchase, created, nil
+}
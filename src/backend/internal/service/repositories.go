@@ -0,0 +1,28 @@
+package service
+
+import "metabee/internal/repository"
+
+// Service reúne as regras de negócio que dependem do armazenamento. Cada
+// instância usa apenas o backend recebido em New, então testes com backends
+// diferentes podem rodar em paralelo.
+type Service struct {
+	storage     repository.Storage
+	users       repository.UserRepository
+	purchases   repository.PurchaseRepository
+	auth        repository.AuthRepositories
+	syncRecords repository.SyncRepository
+	unitOfWork  repository.UnitOfWork
+}
+
+// New cria os serviços sobre o backend selecionado em [database].backend
+func New(storage repository.Storage) *Service {
+	repos := storage.Repositories()
+	return &Service{
+		storage:     storage,
+		users:       repos.Users,
+		purchases:   repos.Purchases,
+		auth:        repos.Auth,
+		syncRecords: repos.Sync,
+		unitOfWork:  repos.UnitOfWork,
+	}
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"metabee/internal/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Algoritmos de assinatura suportados em [jwt].algorithm
+const (
+	SigningAlgorithmHS256 = "HS256" // Legado: segredo compartilhado (jwtsecret)
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmEdDSA = "EdDSA"
+)
+
+// O kid das chaves geradas é o instante de criação, então a ordem alfabética é a ordem cronológica
+const signingKeyIDLayout = "20060102T150405Z"
+
+// Intervalo de recarga do diretório de chaves. Uma chave nova só passa a assinar
+// depois de signingKeyPublicationDelay, tempo suficiente para as outras instâncias
+// a carregarem e para o cache do JWKS nos serviços externos expirar.
+const (
+	signingKeyCheckInterval    = 5 * time.Minute
+	signingKeyPublicationDelay = 15 * time.Minute
+)
+
+// signingKey é um par de chaves carregado de [jwt].keysDir
+type signingKey struct {
+	KID       string
+	Algorithm string
+	CreatedAt time.Time
+	Private   crypto.Signer
+}
+
+var (
+	signingKeysMu sync.RWMutex
+	signingKeys   []signingKey // Mais recente primeiro
+)
+
+// SigningAlgorithm retorna o algoritmo configurado para assinar novos tokens (padrão: HS256)
+func SigningAlgorithm() string {
+	switch strings.ToUpper(config.Current().Jwt.Algorithm) {
+	case "RS256":
+		return SigningAlgorithmRS256
+	case "EDDSA":
+		return SigningAlgorithmEdDSA
+	default:
+		return SigningAlgorithmHS256
+	}
+}
+
+// usesAsymmetricSigning indica se os tokens são assinados com as chaves de keysDir
+func usesAsymmetricSigning() bool {
+	return SigningAlgorithm() != SigningAlgorithmHS256
+}
+
+// acceptsSharedSecret indica se tokens HS256 assinados com jwtsecret ainda são aceitos,
+// seja porque HS256 é o algoritmo atual ou durante a migração para chaves assimétricas.
+func acceptsSharedSecret() bool {
+	if config.Current().Jwt.JWTSECRET == "" {
+		return false
+	}
+	return !usesAsymmetricSigning() || config.Current().Jwt.AcceptLegacyHS256
+}
+
+func signingKeyRotationPeriod() time.Duration {
+	if config.Current().Jwt.KeyRotationDays > 0 {
+		return time.Duration(config.Current().Jwt.KeyRotationDays) * 24 * time.Hour
+	}
+	return 30 * 24 * time.Hour
+}
+
+// retiredSigningKeyRetention é por quanto tempo uma chave substituída continua
+// válida para verificação. Deve ser maior que a validade do access token.
+func retiredSigningKeyRetention() time.Duration {
+	retention := 24 * time.Hour
+	if config.Current().Jwt.RetiredKeyRetentionHours > 0 {
+		retention = time.Duration(config.Current().Jwt.RetiredKeyRetentionHours) * time.Hour
+	}
+	// A chave anterior ainda assina durante o atraso de publicação da sucessora
+	if minimum := signingKeyPublicationDelay + AccessTokenTTL() + mfaPendingTokenTTL; retention < minimum {
+		return minimum
+	}
+	return retention
+}
+
+// LoadSigningKeys carrega as chaves de [jwt].keysDir e gera a primeira chave ou
+// uma nova chave quando a atual passou do período de rotação. Não faz nada com HS256.
+func LoadSigningKeys() error {
+	if !usesAsymmetricSigning() {
+		return nil
+	}
+	if config.Current().Jwt.KeysDir == "" {
+		return fmt.Errorf("[jwt].keysDir é obrigatório com o algoritmo %s", SigningAlgorithm())
+	}
+	if err := os.MkdirAll(config.Current().Jwt.KeysDir, 0o700); err != nil {
+		return err
+	}
+
+	return rotateSigningKeysIfDue()
+}
+
+// StartSigningKeyRotation verifica periodicamente se a chave de assinatura deve
+// ser rotacionada e recarrega o diretório para enxergar chaves criadas por outras
+// instâncias. Para quando ctx é cancelado, sem interromper uma rotação em andamento.
+func StartSigningKeyRotation(ctx context.Context) {
+	if !usesAsymmetricSigning() {
+		return
+	}
+
+	runInBackground(func() {
+		ticker := time.NewTicker(signingKeyCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := rotateSigningKeysIfDue(); err != nil {
+					log.Printf("❌ Erro ao rotacionar chaves de assinatura: %v", err)
+				}
+			}
+		}
+	})
+}
+
+func rotateSigningKeysIfDue() error {
+	keys, err := readSigningKeys(config.Current().Jwt.KeysDir)
+	if err != nil {
+		return err
+	}
+
+	algorithm := SigningAlgorithm()
+	current, found := newestSigningKey(keys, algorithm)
+	if !found || time.Since(current.CreatedAt) >= signingKeyRotationPeriod() {
+		key, err := generateSigningKey(config.Current().Jwt.KeysDir, algorithm)
+		if err != nil {
+			return err
+		}
+		log.Printf("🔑 Nova chave de assinatura %s gerada: %s", algorithm, key.KID)
+		keys = append([]signingKey{key}, keys...)
+	}
+
+	keys = activeSigningKeys(keys, time.Now())
+
+	signingKeysMu.Lock()
+	signingKeys = keys
+	signingKeysMu.Unlock()
+	return nil
+}
+
+// activeSigningKeys descarta as chaves substituídas há mais tempo que o período de retenção.
+// Os arquivos permanecem em keysDir e podem ser apagados manualmente.
+func activeSigningKeys(keys []signingKey, now time.Time) []signingKey {
+	retention := retiredSigningKeyRetention()
+	active := make([]signingKey, 0, len(keys))
+	for i, key := range keys {
+		if i > 0 && now.Sub(keys[i-1].CreatedAt) > retention {
+			break
+		}
+		active = append(active, key)
+	}
+	return active
+}
+
+func newestSigningKey(keys []signingKey, algorithm string) (signingKey, bool) {
+	for _, key := range keys {
+		if key.Algorithm == algorithm {
+			return key, true
+		}
+	}
+	return signingKey{}, false
+}
+
+// currentSigningKey retorna a chave usada para assinar novos tokens
+func currentSigningKey() (signingKey, error) {
+	signingKeysMu.RLock()
+	defer signingKeysMu.RUnlock()
+
+	algorithm := SigningAlgorithm()
+	publishedBefore := time.Now().Add(-signingKeyPublicationDelay)
+	for _, key := range signingKeys {
+		if key.Algorithm == algorithm && !key.CreatedAt.After(publishedBefore) {
+			return key, nil
+		}
+	}
+
+	// Primeira chave (ou troca de algoritmo): não há chave anterior para continuar assinando
+	key, found := newestSigningKey(signingKeys, algorithm)
+	if !found {
+		return signingKey{}, errors.New("nenhuma chave de assinatura carregada")
+	}
+	return key, nil
+}
+
+// verificationKey retorna a chave pública correspondente ao kid do token
+func verificationKey(kid, algorithm string) (crypto.PublicKey, error) {
+	signingKeysMu.RLock()
+	defer signingKeysMu.RUnlock()
+
+	for _, key := range signingKeys {
+		if key.KID == kid {
+			if key.Algorithm != algorithm {
+				return nil, errors.New("algoritmo do token não corresponde à chave")
+			}
+			return key.Private.Public(), nil
+		}
+	}
+	return nil, fmt.Errorf("chave de assinatura desconhecida: %s", kid)
+}
+
+func readSigningKeys(dir string) ([]signingKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]signingKey, 0, len(paths))
+	for _, path := range paths {
+		key, err := readSigningKey(path)
+		if err != nil {
+			log.Printf("⚠️  Chave de assinatura ignorada (%s): %v", path, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func readSigningKey(path string) (signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, errors.New("arquivo não contém um bloco PEM")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	key := signingKey{KID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = SigningAlgorithmRS256
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Algorithm = SigningAlgorithmEdDSA
+		key.Private = private
+	default:
+		return signingKey{}, fmt.Errorf("tipo de chave não suportado: %T", parsed)
+	}
+
+	// Chaves adicionadas manualmente com outro nome usam a data do arquivo
+	if createdAt, err := time.Parse(signingKeyIDLayout, key.KID); err == nil {
+		key.CreatedAt = createdAt
+	} else if info, err := os.Stat(path); err == nil {
+		key.CreatedAt = info.ModTime()
+	}
+
+	return key, nil
+}
+
+func generateSigningKey(dir, algorithm string) (signingKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case SigningAlgorithmRS256:
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return signingKey{}, err
+		}
+		private = rsaKey
+	case SigningAlgorithmEdDSA:
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return signingKey{}, err
+		}
+		private = edKey
+	default:
+		return signingKey{}, fmt.Errorf("algoritmo sem suporte a chaves: %s", algorithm)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	kid := createdAt.Format(signingKeyIDLayout)
+	path := filepath.Join(dir, kid+".pem")
+
+	// O_EXCL evita sobrescrever uma chave gerada no mesmo segundo por outra instância
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return signingKey{}, err
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return signingKey{}, err
+	}
+
+	return signingKey{KID: kid, Algorithm: algorithm, CreatedAt: createdAt, Private: private}, nil
+}
+
+// JWK é a representação pública de uma chave no formato RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+	Crv string `json:"crv,omitempty"` // OKP
+	X   string `json:"x,omitempty"`   // OKP
+}
+
+// JWKSet é o documento servido em /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS retorna as chaves públicas aceitas na verificação. Com HS256 a lista
+// é vazia, já que o segredo compartilhado não pode ser publicado.
+func PublicJWKS() JWKSet {
+	signingKeysMu.RLock()
+	defer signingKeysMu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(signingKeys))}
+	for _, key := range signingKeys {
+		jwk := JWK{Kid: key.KID, Use: "sig", Alg: key.Algorithm}
+		switch public := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
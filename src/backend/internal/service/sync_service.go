@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/model/dao"
+	"metabee/internal/model/dto"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Limites dos registros recebidos na sincronização
+const (
+	maxSyncNoteBytes     = 20000
+	maxSyncDeviceIDBytes = 100
+	// Resposta do servidor central: config.MaxSyncBatchSize notas no tamanho máximo cabem com folga
+	maxSyncResponseBytes = 16 << 20
+)
+
+var (
+	ErrSyncRecordInvalid       = errors.New("registro de sincronização inválido")
+	ErrSyncBatchTooLarge       = fmt.Errorf("envie no máximo %d registros por vez", config.MaxSyncBatchSize)
+	ErrSyncNotConfigured       = errors.New("sincronização com o servidor central não está configurada")
+	ErrSyncCentralUnauthorized = errors.New("o servidor central recusou o token")
+	ErrSyncCentralUnavailable  = errors.New("servidor central indisponível")
+)
+
+// syncLocks serializa, por usuário, os envios e as sincronizações com o servidor
+// central: RunCentralSync marca como enviado tudo o que foi gravado durante a
+// execução. O lock é do processo, o que basta no app desktop (uma instância por arquivo).
+var syncLocks sync.Map
+
+func syncUserLock(userID bson.ObjectID) *sync.Mutex {
+	lock, _ := syncLocks.LoadOrStore(userID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// syncTime normaliza as datas recebidas para a precisão do BSON (milissegundos);
+// sem isso a comparação com o valor gravado nunca seria igual
+func syncTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+func invalidSyncRecord(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrSyncRecordInvalid, fmt.Sprintf(format, args...))
+}
+
+func parseSyncID(field, value string, required bool) (bson.ObjectID, error) {
+	if value == "" && !required {
+		return bson.ObjectID{}, nil
+	}
+	id, err := bson.ObjectIDFromHex(value)
+	if err != nil || id.IsZero() {
+		return bson.ObjectID{}, invalidSyncRecord("%s inválido: %q", field, value)
+	}
+	return id, nil
+}
+
+func hexOrEmpty(id bson.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// syncBatch são os registros de um dto.SyncBatch já convertidos e validados
+type syncBatch struct {
+	sessions   []dao.StudySessionDao
+	progresses []dao.UserProgressDao
+	notes      []dao.NoteDao
+}
+
+// parseSyncBatch valida o lote inteiro antes de gravar qualquer registro
+func parseSyncBatch(userID bson.ObjectID, batch dto.SyncBatch) (syncBatch, error) {
+	var parsed syncBatch
+
+	for _, in := range batch.StudySessions {
+		id, err := parseSyncID("id da sessão", in.ID, true)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		courseID, err := parseSyncID("course_id da sessão", in.CourseID, true)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		lessonID, err := parseSyncID("lesson_id da sessão", in.LessonID, false)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		if in.Duration < 0 || in.Date.IsZero() {
+			return syncBatch{}, invalidSyncRecord("sessão %s sem data ou com duração negativa", in.ID)
+		}
+		parsed.sessions = append(parsed.sessions, dao.StudySessionDao{
+			ID: id, UserID: userID, CourseID: courseID, LessonID: lessonID,
+			Duration: in.Duration, Date: syncTime(in.Date),
+		})
+	}
+
+	for _, in := range batch.Progress {
+		courseID, err := parseSyncID("course_id do progresso", in.CourseID, true)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		if in.Progress < 0 || in.Progress > 100 || in.Hours < 0 {
+			return syncBatch{}, invalidSyncRecord("progresso do curso %s fora dos limites", in.CourseID)
+		}
+		parsed.progresses = append(parsed.progresses, dao.UserProgressDao{
+			UserID: userID, CourseID: courseID,
+			Progress: in.Progress, Hours: in.Hours, LastStudy: syncTime(in.LastStudy),
+		})
+	}
+
+	for _, in := range batch.Notes {
+		id, err := parseSyncID("id da nota", in.ID, true)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		courseID, err := parseSyncID("course_id da nota", in.CourseID, true)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		lessonID, err := parseSyncID("lesson_id da nota", in.LessonID, false)
+		if err != nil {
+			return syncBatch{}, err
+		}
+		switch {
+		case in.Version < 1:
+			return syncBatch{}, invalidSyncRecord("nota %s com version menor que 1", in.ID)
+		case in.DeviceID == "" || len(in.DeviceID) > maxSyncDeviceIDBytes:
+			return syncBatch{}, invalidSyncRecord("nota %s com device_id vazio ou longo demais", in.ID)
+		case in.UpdatedAt.IsZero():
+			return syncBatch{}, invalidSyncRecord("nota %s sem updated_at", in.ID)
+		case len(in.Content) > maxSyncNoteBytes || !utf8.ValidString(in.Content):
+			return syncBatch{}, invalidSyncRecord("nota %s com mais de %d bytes", in.ID, maxSyncNoteBytes)
+		}
+		note := dao.NoteDao{
+			ID: id, UserID: userID, CourseID: courseID, LessonID: lessonID,
+			Content: in.Content, Deleted: in.Deleted,
+			Version: in.Version, DeviceID: in.DeviceID, UpdatedAt: syncTime(in.UpdatedAt),
+		}
+		// Nota apagada vira uma lápide: o conteúdo não é mais sincronizado
+		if note.Deleted {
+			note.Content = ""
+		}
+		parsed.notes = append(parsed.notes, note)
+	}
+
+	return parsed, nil
+}
+
+func syncStudySessionDTO(session dao.StudySessionDao) dto.SyncStudySession {
+	return dto.SyncStudySession{
+		ID: session.ID.Hex(), CourseID: session.CourseID.Hex(), LessonID: hexOrEmpty(session.LessonID),
+		Duration: session.Duration, Date: session.Date, Seq: session.Seq,
+	}
+}
+
+func syncProgressDTO(progress dao.UserProgressDao) dto.SyncProgress {
+	return dto.SyncProgress{
+		CourseID: progress.CourseID.Hex(), Progress: progress.Progress, Hours: progress.Hours,
+		LastStudy: progress.LastStudy, Seq: progress.Seq,
+	}
+}
+
+func syncNoteDTO(note dao.NoteDao) dto.SyncNote {
+	return dto.SyncNote{
+		ID: note.ID.Hex(), CourseID: note.CourseID.Hex(), LessonID: hexOrEmpty(note.LessonID),
+		Content: note.Content, Deleted: note.Deleted,
+		Version: note.Version, DeviceID: note.DeviceID, UpdatedAt: note.UpdatedAt, Seq: note.Seq,
+	}
+}
+
+func newSyncBatchDTO() dto.SyncBatch {
+	return dto.SyncBatch{
+		StudySessions: []dto.SyncStudySession{},
+		Progress:      []dto.SyncProgress{},
+		Notes:         []dto.SyncNote{},
+	}
+}
+
+// applySyncBatch grava o lote e retorna, em Current, a versão vigente dos
+// registros em que ficou valendo algo diferente do que foi enviado
+func (s *Service) applySyncBatch(ctx context.Context, batch syncBatch) (dto.SyncPushResult, error) {
+	result := dto.SyncPushResult{Current: newSyncBatchDTO()}
+
+	for _, session := range batch.sessions {
+		inserted, err := s.syncRecords.ApplyStudySession(ctx, session)
+		if err != nil {
+			return dto.SyncPushResult{}, err
+		}
+		if inserted {
+			result.Applied++
+		} else {
+			result.Unchanged++
+		}
+	}
+
+	for _, progress := range batch.progresses {
+		saved, changed, err := s.syncRecords.ApplyProgress(ctx, progress)
+		if err != nil {
+			return dto.SyncPushResult{}, err
+		}
+		if changed {
+			result.Applied++
+		} else {
+			result.Unchanged++
+		}
+		// As horas salvas vêm das sessões de estudo e podem diferir das do dispositivo
+		if _, differs := progress.Merge(saved); differs || saved.Hours != progress.Hours {
+			result.Current.Progress = append(result.Current.Progress, syncProgressDTO(saved))
+		}
+	}
+
+	for _, note := range batch.notes {
+		current, changed, err := s.syncRecords.ApplyNote(ctx, note)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.SyncPushResult{}, invalidSyncRecord("id de nota %s já usado por outra conta", note.ID.Hex())
+		}
+		if err != nil {
+			return dto.SyncPushResult{}, err
+		}
+		if changed {
+			result.Applied++
+			continue
+		}
+		result.Unchanged++
+		if current.Supersedes(note) {
+			result.Current.Notes = append(result.Current.Notes, syncNoteDTO(current))
+		}
+	}
+
+	return result, nil
+}
+
+func syncBatchSize(batch dto.SyncBatch) int {
+	return len(batch.StudySessions) + len(batch.Progress) + len(batch.Notes)
+}
+
+// PushChanges grava os registros enviados por um dispositivo do usuário.
+// Reenviar o mesmo lote não altera nada.
+func (s *Service) PushChanges(ctx context.Context, userID bson.ObjectID, batch dto.SyncBatch) (dto.SyncPushResult, error) {
+	if syncBatchSize(batch) > config.MaxSyncBatchSize {
+		return dto.SyncPushResult{}, ErrSyncBatchTooLarge
+	}
+	parsed, err := parseSyncBatch(userID, batch)
+	if err != nil {
+		return dto.SyncPushResult{}, err
+	}
+
+	lock := syncUserLock(userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return s.applySyncBatch(ctx, parsed)
+}
+
+// PullChanges retorna os registros do usuário alterados depois de since. limit
+// fora de 1..config.MaxSyncBatchSize usa [sync].batchSize.
+func (s *Service) PullChanges(ctx context.Context, userID bson.ObjectID, since int64, limit int) (dto.SyncChanges, error) {
+	if limit <= 0 || limit > config.MaxSyncBatchSize {
+		limit = config.Current().Sync.BatchSize
+	}
+	if since < 0 {
+		since = 0
+	}
+
+	changes, err := s.syncRecords.Changes(ctx, userID, since, limit)
+	if err != nil {
+		return dto.SyncChanges{}, err
+	}
+	return syncChangesDTO(changes), nil
+}
+
+func syncChangesDTO(changes dao.SyncChanges) dto.SyncChanges {
+	result := dto.SyncChanges{SyncBatch: newSyncBatchDTO(), Next: changes.Next, More: changes.More}
+	for _, session := range changes.StudySessions {
+		result.StudySessions = append(result.StudySessions, syncStudySessionDTO(session))
+	}
+	for _, progress := range changes.Progress {
+		result.Progress = append(result.Progress, syncProgressDTO(progress))
+	}
+	for _, note := range changes.Notes {
+		result.Notes = append(result.Notes, syncNoteDTO(note))
+	}
+	return result
+}
+
+// RunCentralSync sincroniza os dados locais do usuário com o servidor central
+// de [sync].centralURL, autenticando-se lá com centralToken: envia o que mudou
+// aqui desde a última execução, aplica as versões que prevaleceram no central e
+// depois recebe o que mudou lá. Conflitos são resolvidos pelas mesmas regras
+// dos dois lados, então a ordem das sincronizações não altera o resultado.
+func (s *Service) RunCentralSync(ctx context.Context, userID bson.ObjectID, centralToken string) (dto.SyncRunResult, error) {
+	syncConf := config.Current().Sync
+	if syncConf.CentralURL == "" {
+		return dto.SyncRunResult{}, ErrSyncNotConfigured
+	}
+	central := &syncCentralClient{
+		baseURL: strings.TrimRight(syncConf.CentralURL, "/"),
+		token:   centralToken,
+		http:    &http.Client{Timeout: time.Duration(syncConf.TimeoutSeconds) * time.Second},
+	}
+	batchSize := syncConf.BatchSize
+
+	lock := syncUserLock(userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	state, err := s.syncRecords.GetSyncState(ctx, userID)
+	if err != nil {
+		return dto.SyncRunResult{}, err
+	}
+	var result dto.SyncRunResult
+
+	// Envio: alterações locais desde a última execução
+	for more := true; more; {
+		local, err := s.syncRecords.Changes(ctx, userID, state.PushedSeq, batchSize)
+		if err != nil {
+			return result, err
+		}
+		more = local.More
+		if local.Next == state.PushedSeq {
+			break
+		}
+
+		var pushed dto.SyncPushResult
+		if err := central.call(ctx, http.MethodPost, "/metabee/sync/push", syncChangesDTO(local).SyncBatch, &pushed); err != nil {
+			return result, err
+		}
+		if err := s.applyCentralBatch(ctx, userID, pushed.Current); err != nil {
+			return result, err
+		}
+
+		result.Pushed += len(local.StudySessions) + len(local.Progress) + len(local.Notes)
+		state.PushedSeq = local.Next
+		if err := s.syncRecords.SaveSyncState(ctx, state); err != nil {
+			return result, err
+		}
+	}
+
+	// Recebimento: alterações no central desde a última execução
+	for more := true; more; {
+		query := url.Values{}
+		query.Set("since", strconv.FormatInt(state.PulledSeq, 10))
+		query.Set("limit", strconv.Itoa(batchSize))
+
+		var changes dto.SyncChanges
+		if err := central.call(ctx, http.MethodGet, "/metabee/sync/changes?"+query.Encode(), nil, &changes); err != nil {
+			return result, err
+		}
+		if err := s.applyCentralBatch(ctx, userID, changes.SyncBatch); err != nil {
+			return result, err
+		}
+
+		result.Pulled += syncBatchSize(changes.SyncBatch)
+		more = changes.More && changes.Next > state.PulledSeq
+		state.PulledSeq = changes.Next
+		if err := s.syncRecords.SaveSyncState(ctx, state); err != nil {
+			return result, err
+		}
+	}
+
+	// O que foi gravado aqui durante a execução veio do central: não precisa voltar
+	latest, err := s.syncRecords.LatestSeq(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+	state.PushedSeq = latest
+	state.LastSyncAt = time.Now()
+	if err := s.syncRecords.SaveSyncState(ctx, state); err != nil {
+		return result, err
+	}
+
+	result.Seq = state.PulledSeq
+	log.Printf("🔄 Sincronização com o servidor central concluída - UserID: %s, Enviados: %d, Recebidos: %d",
+		userID.Hex(), result.Pushed, result.Pulled)
+	return result, nil
+}
+
+// applyCentralBatch grava localmente os registros recebidos do servidor central
+func (s *Service) applyCentralBatch(ctx context.Context, userID bson.ObjectID, batch dto.SyncBatch) error {
+	parsed, err := parseSyncBatch(userID, batch)
+	if err != nil {
+		return fmt.Errorf("%w: resposta inválida: %v", ErrSyncCentralUnavailable, err)
+	}
+	_, err = s.applySyncBatch(ctx, parsed)
+	return err
+}
+
+type syncCentralClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// call envia body (se não for nil) como JSON e decodifica a resposta em result
+func (c *syncCentralClient) call(ctx context.Context, method, path string, body, result any) error {
+	var payload io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSyncCentralUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxSyncResponseBytes))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSyncCentralUnavailable, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrSyncCentralUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		log.Printf("❌ Servidor central respondeu %d em %s %s: %s", resp.StatusCode, method, path, string(raw))
+		return fmt.Errorf("%w: status %d", ErrSyncCentralUnavailable, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("%w: resposta inválida: %v", ErrSyncCentralUnavailable, err)
+	}
+	return nil
+}
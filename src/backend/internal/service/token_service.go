@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"metabee/internal/config"
+	"metabee/internal/model/dao"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TokenPair agrupa o access token e o refresh token entregues ao cliente.
+type TokenPair struct {
+	UserID       string
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+	ExpiresIn    int64 // Validade do access token em segundos
+}
+
+// SessionInfo descreve o dispositivo que iniciou ou renovou a sessão
+type SessionInfo struct {
+	DeviceName string
+	IP         string
+	UserAgent  string
+}
+
+var (
+	ErrSessionNotFound     = errors.New("sessão não encontrada")
+	ErrRefreshTokenInvalid = errors.New("refresh token inválido")
+	ErrRefreshTokenExpired = errors.New("refresh token expirado")
+	ErrRefreshTokenReused  = errors.New("refresh token reutilizado, sessão revogada")
+)
+
+// RefreshTokenTTL retorna a validade configurada para refresh tokens (padrão: 30 dias)
+func RefreshTokenTTL() time.Duration {
+	if config.Current().Jwt.RefreshTokenTTLDays > 0 {
+		return time.Duration(config.Current().Jwt.RefreshTokenTTLDays) * 24 * time.Hour
+	}
+	return 30 * 24 * time.Hour
+}
+
+// IssueTokenPair inicia uma nova sessão (família de refresh tokens) para o usuário.
+func (s *Service) IssueTokenPair(ctx context.Context, user dao.UserDao, info SessionInfo) (TokenPair, error) {
+	sessionID := bson.NewObjectID()
+	_, err := s.auth.Sessions.CreateSession(ctx, dao.SessionDao{
+		ID:         sessionID,
+		UserID:     user.ID,
+		DeviceName: info.DeviceName,
+		IP:         info.IP,
+		UserAgent:  info.UserAgent,
+		ExpiresAt:  time.Now().Add(RefreshTokenTTL()),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return s.issueTokenPair(ctx, user, sessionID, bson.NewObjectID())
+}
+
+// RefreshTokenPair troca um refresh token válido por um novo par de tokens.
+// Cada refresh token só pode ser usado uma vez; se um token já rotacionado for
+// apresentado novamente, toda a família é revogada, pois o token vazou.
+func (s *Service) RefreshTokenPair(ctx context.Context, rawToken string, info SessionInfo) (TokenPair, error) {
+	if rawToken == "" {
+		return TokenPair{}, ErrRefreshTokenInvalid
+	}
+
+	stored, err := s.auth.RefreshTokens.FindByHash(ctx, hashOpaqueToken(rawToken))
+	if err != nil {
+		return TokenPair{}, ErrRefreshTokenInvalid
+	}
+
+	if !stored.UsedAt.IsZero() || !stored.RevokedAt.IsZero() {
+		log.Printf("⚠️  Reuso de refresh token detectado - UserID: %s, Família: %s", stored.UserID.Hex(), stored.FamilyID.Hex())
+		// A revogação não pode depender de quem apresentou o token vazado continuar conectado
+		if err := s.revokeSessionFamily(context.WithoutCancel(ctx), stored.FamilyID); err != nil {
+			log.Printf("❌ Erro ao revogar família de tokens %s: %v", stored.FamilyID.Hex(), err)
+		}
+		return TokenPair{}, ErrRefreshTokenReused
+	}
+
+	if stored.ExpiresAt.Before(time.Now()) {
+		return TokenPair{}, ErrRefreshTokenExpired
+	}
+
+	// Recarrega o usuário para que mudanças de papel valham a partir da renovação
+	user, err := s.users.FindUserByID(ctx, stored.UserID.Hex())
+	if err != nil {
+		return TokenPair{}, ErrRefreshTokenInvalid
+	}
+
+	newTokenID := bson.NewObjectID()
+	if err := s.auth.RefreshTokens.MarkUsed(ctx, stored.ID, newTokenID); err != nil {
+		if errors.Is(err, dao.ErrRefreshTokenAlreadyUsed) {
+			// Outra requisição usou o mesmo token ao mesmo tempo
+			log.Printf("⚠️  Uso concorrente de refresh token - Família: %s", stored.FamilyID.Hex())
+			if err := s.revokeSessionFamily(context.WithoutCancel(ctx), stored.FamilyID); err != nil {
+				log.Printf("❌ Erro ao revogar família de tokens %s: %v", stored.FamilyID.Hex(), err)
+			}
+			return TokenPair{}, ErrRefreshTokenReused
+		}
+		return TokenPair{}, err
+	}
+
+	if err := s.auth.Sessions.Touch(ctx, stored.FamilyID, info.IP, time.Now().Add(RefreshTokenTTL())); err != nil {
+		log.Printf("⚠️  Erro ao atualizar sessão %s: %v", stored.FamilyID.Hex(), err)
+	}
+
+	return s.issueTokenPair(ctx, user, stored.FamilyID, newTokenID)
+}
+
+func (s *Service) issueTokenPair(ctx context.Context, user dao.UserDao, familyID, tokenID bson.ObjectID) (TokenPair, error) {
+	accessToken, err := GenerateJWT(user, familyID.Hex())
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	rawRefreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	_, err = s.auth.RefreshTokens.CreateRefreshToken(ctx, dao.RefreshTokenDao{
+		ID:        tokenID,
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashOpaqueToken(rawRefreshToken),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL()),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		UserID:       user.ID.Hex(),
+		AccessToken:  accessToken,
+		RefreshToken: rawRefreshToken,
+		SessionID:    familyID.Hex(),
+		ExpiresIn:    int64(AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// generateOpaqueToken gera um token opaco de 256 bits (refresh tokens, links enviados por email)
+func generateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashOpaqueToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevokeSession encerra a sessão do token informado: o access token entra na
+// lista de revogação e a família de refresh tokens é revogada.
+func (s *Service) RevokeSession(ctx context.Context, claims *JWTClaims) error {
+	userID, err := bson.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return errors.New("userID inválido")
+	}
+
+	if claims.ID != "" {
+		expiresAt := time.Now().Add(AccessTokenTTL())
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		if err := s.auth.RevokedTokens.RevokeToken(ctx, claims.ID, userID, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != "" {
+		familyID, err := bson.ObjectIDFromHex(claims.SessionID)
+		if err != nil {
+			return errors.New("sessão inválida")
+		}
+		return s.revokeSessionFamily(ctx, familyID)
+	}
+
+	return nil
+}
+
+// ListSessions retorna as sessões ativas do usuário
+func (s *Service) ListSessions(ctx context.Context, userID bson.ObjectID) ([]dao.SessionDao, error) {
+	return s.auth.Sessions.FindActiveByUser(ctx, userID)
+}
+
+// EndSession encerra uma sessão do usuário (por exemplo, um computador
+// compartilhado que ele não reconhece). Access tokens da sessão deixam de valer
+// imediatamente, já que a verificação consulta a família de refresh tokens.
+func (s *Service) EndSession(ctx context.Context, userID bson.ObjectID, sessionID string) error {
+	id, err := bson.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return ErrSessionNotFound
+	}
+
+	session, err := s.auth.Sessions.FindByIDForUser(ctx, id, userID)
+	if err != nil || !session.RevokedAt.IsZero() {
+		return ErrSessionNotFound
+	}
+
+	return s.revokeSessionFamily(ctx, session.ID)
+}
+
+// TouchSession registra o último acesso da sessão do access token
+func (s *Service) TouchSession(ctx context.Context, claims *JWTClaims, ip string) {
+	sessionID, err := bson.ObjectIDFromHex(claims.SessionID)
+	if err != nil {
+		return
+	}
+
+	// Uma escrita por minuto no máximo; basta para exibir "visto por último"
+	if err := s.auth.Sessions.TouchIfIdle(ctx, sessionID, ip, time.Now().Add(-time.Minute)); err != nil {
+		log.Printf("⚠️  Erro ao atualizar último acesso da sessão %s: %v", claims.SessionID, err)
+	}
+}
+
+// revokeSessionFamily revoga os refresh tokens da família e marca a sessão como encerrada
+func (s *Service) revokeSessionFamily(ctx context.Context, familyID bson.ObjectID) error {
+	if err := s.auth.RefreshTokens.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return s.auth.Sessions.Revoke(ctx, familyID)
+}
+
+// RevokeAllSessions encerra todas as sessões do usuário. Access tokens emitidos
+// até agora deixam de ser aceitos e todos os refresh tokens são revogados.
+func (s *Service) RevokeAllSessions(ctx context.Context, userID bson.ObjectID) error {
+	if err := s.users.UpdateUserProfile(ctx, userID, bson.M{"tokens_valid_after": time.Now()}); err != nil {
+		return err
+	}
+
+	if err := s.auth.RefreshTokens.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.auth.APIKeys.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.auth.Sessions.RevokeAllForUserExcept(ctx, userID, bson.NilObjectID)
+}
+
+// RevokeOtherSessions encerra todas as sessões do usuário exceto a atual
+// (usado após a troca de senha, para não deslogar quem fez a troca). As chaves
+// de API também são revogadas: quem descobriu a senha pode ter criado uma.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID bson.ObjectID, currentSessionID string) error {
+	currentFamilyID, err := bson.ObjectIDFromHex(currentSessionID)
+	if err != nil {
+		// Token sem sessão (emitido antes dos refresh tokens): encerra tudo
+		return s.RevokeAllSessions(ctx, userID)
+	}
+
+	if err := s.auth.RefreshTokens.RevokeAllForUserExcept(ctx, userID, currentFamilyID); err != nil {
+		return err
+	}
+	if err := s.auth.APIKeys.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.auth.Sessions.RevokeAllForUserExcept(ctx, userID, currentFamilyID)
+}
+
+// IsTokenRevoked verifica se o token foi revogado individualmente, se a sua
+// sessão foi encerrada ou se houve um "sair de todos os dispositivos" posterior à emissão.
+func (s *Service) IsTokenRevoked(ctx context.Context, claims *JWTClaims, user dao.UserDao) (bool, error) {
+	if !user.TokensValidAfter.IsZero() {
+		// O iat do JWT tem precisão de segundos: sem truncar, um token emitido logo
+		// após o "sair de todos" (no mesmo segundo) seria recusado
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(user.TokensValidAfter.Truncate(time.Second)) {
+			return true, nil
+		}
+	}
+
+	if claims.SessionID != "" {
+		familyID, err := bson.ObjectIDFromHex(claims.SessionID)
+		if err != nil {
+			return true, nil
+		}
+		revoked, err := s.auth.RefreshTokens.IsFamilyRevoked(ctx, familyID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if claims.ID == "" {
+		return false, nil
+	}
+
+	return s.auth.RevokedTokens.IsRevoked(ctx, claims.ID)
+}
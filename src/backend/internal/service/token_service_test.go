@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"context"
+	"metabee/internal/model/dao"
+	"metabee/internal/service"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// O iat tem precisão de segundos: um token emitido no mesmo segundo do
+// "sair de todos", mas depois dele, continua valendo
+func TestIsTokenRevokedComparesWholeSeconds(t *testing.T) {
+	ctx := context.Background()
+	storage := openTestStorage(t)
+	services := service.New(storage)
+	users := storage.Repositories().Users
+
+	user, err := users.CreateUser(ctx, dao.UserDao{Name: "Ana", Email: "ana@metabee.test", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	validAfter := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	if err := users.UpdateUserProfile(ctx, user.ID, bson.M{"tokens_valid_after": validAfter}); err != nil {
+		t.Fatal(err)
+	}
+	user, err = users.FindUserByID(ctx, user.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt := func(at time.Time) *service.JWTClaims {
+		return &service.JWTClaims{
+			UserID:           user.ID.Hex(),
+			RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(at)},
+		}
+	}
+
+	if revoked, err := services.IsTokenRevoked(ctx, issuedAt(validAfter.Add(200*time.Millisecond)), user); err != nil || revoked {
+		t.Fatalf("token emitido no mesmo segundo deve valer, revogado=%v err=%v", revoked, err)
+	}
+	if revoked, err := services.IsTokenRevoked(ctx, issuedAt(validAfter.Add(-time.Second)), user); err != nil || !revoked {
+		t.Fatalf("token emitido antes deve ser recusado, revogado=%v err=%v", revoked, err)
+	}
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+// Segredo ASCII "12345678901234567890" dos vetores SHA1 da RFC 6238
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Os vetores da RFC têm 8 dígitos; com 6 dígitos valem os 6 últimos
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, vector := range vectors {
+		if code := totpCode(key, vector.unix/totpPeriod); code != vector.code {
+			t.Errorf("T=%d: esperava %s, gerou %s", vector.unix, vector.code, code)
+		}
+
+		step, ok := ValidateTOTP(rfc6238Secret, vector.code, time.Unix(vector.unix, 0))
+		if !ok || step != vector.unix/totpPeriod {
+			t.Errorf("T=%d: código da RFC recusado (passo %d, ok=%v)", vector.unix, step, ok)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	const code = "005924" // T=1234567890, passo 41152263
+	issued := time.Unix(1234567890, 0)
+	issuedStep := issued.Unix() / totpPeriod
+
+	cases := []struct {
+		name   string
+		at     time.Time
+		accept bool
+	}{
+		{"mesmo passo", issued, true},
+		{"um passo depois", issued.Add(totpPeriod * time.Second), true},
+		{"um passo antes", issued.Add(-totpPeriod * time.Second), true},
+		{"dois passos depois", issued.Add(2 * totpPeriod * time.Second), false},
+		{"dois passos antes", issued.Add(-2 * totpPeriod * time.Second), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, code, tc.at)
+			if ok != tc.accept {
+				t.Fatalf("aceito=%v, esperava %v", ok, tc.accept)
+			}
+			// O passo devolvido é o do código, não o do relógio, para que o reuso seja detectado
+			if ok && step != issuedStep {
+				t.Fatalf("passo %d, esperava %d", step, issuedStep)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsMalformedCodes(t *testing.T) {
+	at := time.Unix(1234567890, 0)
+	for _, code := range []string{"", "05924", "0005924", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, at); ok {
+			t.Errorf("código %q não deveria ser aceito", code)
+		}
+	}
+	if _, ok := ValidateTOTP("segredo inválido!", "005924", at); ok {
+		t.Error("segredo inválido não deveria validar")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, " 005924 ", at); !ok {
+		t.Error("espaços em volta do código devem ser ignorados")
+	}
+}